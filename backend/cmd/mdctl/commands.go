@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/filesync"
+	"github.com/warriorguo/md-editor/backend/pkg/client"
+)
+
+const listPageSize = 100
+
+func (a *app) printJSON(v interface{}) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// resolveTopic finds the project a topic argument names: its ID, or its
+// name ignoring case.
+func (a *app) resolveTopic(ctx context.Context, topic string) (*client.Project, error) {
+	if id, err := uuid.Parse(topic); err == nil {
+		return a.client.GetProject(ctx, id)
+	}
+
+	lookup, err := a.client.LookupProjects(ctx, topic, 3)
+	if err != nil {
+		return nil, err
+	}
+	var similar []string
+	for _, match := range lookup.Matches {
+		if match.Exact {
+			project := match.Project
+			return &project, nil
+		}
+		similar = append(similar, fmt.Sprintf("%q", match.Project.Name))
+	}
+	if len(similar) > 0 {
+		return nil, fmt.Errorf("no topic named %q; did you mean %s?", topic, strings.Join(similar, ", "))
+	}
+	return nil, fmt.Errorf("no topic named %q", topic)
+}
+
+// listProjects returns every project matching opts, following cursors.
+func (a *app) listProjects(ctx context.Context, opts client.ListProjectsOptions) ([]client.Project, error) {
+	opts.PageSize = listPageSize
+	var projects []client.Project
+	for {
+		page, err := a.client.ListProjects(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, page.Projects...)
+		if page.NextCursor == "" {
+			return projects, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+func runList(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	query := flags.String("q", "", "Only list topics whose name matches")
+	archived := flags.Bool("archived", false, "Include archived topics")
+	pinned := flags.Bool("pinned", false, "Only list pinned topics")
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	opts := client.ListProjectsOptions{Sort: "name", Query: *query, Pinned: *pinned}
+	if *archived {
+		opts.Archived = "all"
+	}
+	projects, err := a.listProjects(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(projects)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tUPDATED\tID")
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.UpdatedAt.Local().Format("2006-01-02 15:04"), p.ID)
+	}
+	return w.Flush()
+}
+
+func runCat(ctx context.Context, a *app, args []string) error {
+	rest, err := parseArgs(flag.NewFlagSet("cat", flag.ContinueOnError), args, oneOrMore)
+	if err != nil {
+		return err
+	}
+	project, err := a.resolveTopic(ctx, topicArg(rest))
+	if err != nil {
+		return err
+	}
+	doc, err := a.client.GetDocument(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(doc)
+	}
+	_, err = io.WriteString(a.stdout, doc.ContentMD)
+	return err
+}
+
+func runNew(ctx context.Context, a *app, args []string) error {
+	rest, err := parseArgs(flag.NewFlagSet("new", flag.ContinueOnError), args, oneOrMore)
+	if err != nil {
+		return err
+	}
+	project, err := a.client.CreateProject(ctx, client.CreateProjectRequest{Name: topicArg(rest)})
+	if err != nil {
+		return err
+	}
+	return a.printProject(project, "Created")
+}
+
+func runRename(ctx context.Context, a *app, args []string) error {
+	rest, err := parseArgs(flag.NewFlagSet("rename", flag.ContinueOnError), args, 2)
+	if err != nil {
+		return err
+	}
+	project, err := a.resolveTopic(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	project, err = a.client.RenameProject(ctx, project.ID, rest[1])
+	if err != nil {
+		return err
+	}
+	return a.printProject(project, "Renamed to")
+}
+
+func runRemove(ctx context.Context, a *app, args []string) error {
+	rest, err := parseArgs(flag.NewFlagSet("rm", flag.ContinueOnError), args, oneOrMore)
+	if err != nil {
+		return err
+	}
+	project, err := a.resolveTopic(ctx, topicArg(rest))
+	if err != nil {
+		return err
+	}
+	if err := a.client.DeleteProject(ctx, project.ID); err != nil {
+		return err
+	}
+	return a.printProject(project, "Moved to the trash:")
+}
+
+func (a *app) printProject(project *client.Project, verb string) error {
+	if a.json {
+		return a.printJSON(project)
+	}
+	_, err := fmt.Fprintf(a.stdout, "%s %q (%s)\n", verb, project.Name, project.ID)
+	return err
+}
+
+// runEdit opens the note in $VISUAL or $EDITOR and saves it if it changed.
+// The save carries the version that was opened, so edits made by others in
+// the meantime are never overwritten; the local copy is kept instead.
+func runEdit(ctx context.Context, a *app, args []string) error {
+	rest, err := parseArgs(flag.NewFlagSet("edit", flag.ContinueOnError), args, oneOrMore)
+	if err != nil {
+		return err
+	}
+	project, err := a.resolveTopic(ctx, topicArg(rest))
+	if err != nil {
+		return err
+	}
+	doc, err := a.client.GetDocument(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "mdctl-*.md")
+	if err != nil {
+		return err
+	}
+	path := file.Name()
+	_, err = file.WriteString(doc.ContentMD)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if err := a.runEditor(ctx, path); err != nil {
+		os.Remove(path)
+		return err
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if string(edited) == doc.ContentMD {
+		os.Remove(path)
+		fmt.Fprintln(a.stderr, "No changes")
+		return nil
+	}
+
+	saved, err := a.client.UpdateDocument(ctx, doc, string(edited), nil)
+	if client.HasCode(err, client.CodeVersionConflict) {
+		return fmt.Errorf("%q was changed while you edited it; your version is in %s", project.Name, path)
+	}
+	if err != nil {
+		return fmt.Errorf("%w; your version is in %s", err, path)
+	}
+	os.Remove(path)
+
+	if a.json {
+		return a.printJSON(saved)
+	}
+	_, err = fmt.Fprintf(a.stdout, "Saved %q version %d\n", project.Name, saved.Version)
+	return err
+}
+
+func (a *app) runEditor(ctx context.Context, path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may come with arguments, e.g. "code --wait".
+	cmd := exec.CommandContext(ctx, "sh", "-c", editor+` "$1"`, "mdctl", path)
+	cmd.Stdin = a.stdin
+	cmd.Stdout = a.stdout
+	cmd.Stderr = a.stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+	return nil
+}
+
+func runAppend(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("append", flag.ContinueOnError)
+	create := flags.Bool("create", false, "Create the topic if it does not exist")
+	rest, err := parseArgs(flags, args, oneOrMore)
+	if err != nil {
+		return err
+	}
+
+	addition, err := io.ReadAll(a.stdin)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(addition)) == "" {
+		return errors.New("nothing to append on standard input")
+	}
+
+	var project *client.Project
+	if *create {
+		project, _, err = a.client.GetOrCreateProject(ctx, topicArg(rest))
+	} else {
+		project, err = a.resolveTopic(ctx, topicArg(rest))
+	}
+	if err != nil {
+		return err
+	}
+
+	saved, err := a.client.EditDocument(ctx, project.ID, func(content string) (string, error) {
+		return appendMarkdown(content, string(addition)), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(saved)
+	}
+	_, err = fmt.Fprintf(a.stdout, "Appended to %q, now version %d\n", project.Name, saved.Version)
+	return err
+}
+
+// appendMarkdown adds addition to content as a block of its own, separated
+// by a blank line.
+func appendMarkdown(content, addition string) string {
+	addition = strings.Trim(addition, "\n") + "\n"
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return addition
+	}
+	return content + "\n\n" + addition
+}
+
+// searchHit is a topic whose name matches a search, or with Line set, a
+// line of its note that does.
+type searchHit struct {
+	Topic     string    `json:"topic"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Line      int       `json:"line,omitempty"`
+	Text      string    `json:"text,omitempty"`
+}
+
+// runSearch matches topic names on the server, which also finds similar
+// names, and note lines by case-insensitive substring.
+func runSearch(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	namesOnly := flags.Bool("names", false, "Only search topic names")
+	rest, err := parseArgs(flags, args, oneOrMore)
+	if err != nil {
+		return err
+	}
+	query := strings.Join(rest, " ")
+
+	named, err := a.listProjects(ctx, client.ListProjectsOptions{Query: query})
+	if err != nil {
+		return err
+	}
+	hits := []searchHit{}
+	for _, p := range named {
+		hits = append(hits, searchHit{Topic: p.Name, ProjectID: p.ID})
+	}
+
+	if !*namesOnly {
+		projects, err := a.listProjects(ctx, client.ListProjectsOptions{Sort: "name"})
+		if err != nil {
+			return err
+		}
+		for _, p := range projects {
+			doc, err := a.client.GetDocument(ctx, p.ID)
+			if err != nil {
+				return err
+			}
+			for _, line := range matchingLines(doc.ContentMD, query) {
+				line.Topic, line.ProjectID = p.Name, p.ID
+				hits = append(hits, line)
+			}
+		}
+	}
+
+	if a.json {
+		return a.printJSON(hits)
+	}
+	for _, hit := range hits {
+		if hit.Line == 0 {
+			fmt.Fprintln(a.stdout, hit.Topic)
+		} else {
+			fmt.Fprintf(a.stdout, "%s:%d: %s\n", hit.Topic, hit.Line, hit.Text)
+		}
+	}
+	return nil
+}
+
+// matchingLines returns the lines of content that contain query, ignoring
+// case, numbered from 1.
+func matchingLines(content, query string) []searchHit {
+	query = strings.ToLower(query)
+	var hits []searchHit
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for n := 1; scanner.Scan(); n++ {
+		if strings.Contains(strings.ToLower(scanner.Text()), query) {
+			hits = append(hits, searchHit{Line: n, Text: scanner.Text()})
+		}
+	}
+	return hits
+}
+
+// exportedFile is a note written by export.
+type exportedFile struct {
+	Topic     string    `json:"topic"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Path      string    `json:"path"`
+}
+
+// runExport writes notes to a directory, one Markdown file per topic, named
+// as the server's -sync-dir names them.
+func runExport(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	dir := flags.String("o", ".", "Directory to write the files to")
+	topics, err := parseArgs(flags, args, anyNumber)
+	if err != nil {
+		return err
+	}
+
+	var projects []client.Project
+	if len(topics) == 0 {
+		projects, err = a.listProjects(ctx, client.ListProjectsOptions{Sort: "name"})
+		if err != nil {
+			return err
+		}
+	}
+	for _, topic := range topics {
+		project, err := a.resolveTopic(ctx, topic)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, *project)
+	}
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+	names := filesync.FileNames(projects)
+	files := []exportedFile{}
+	for _, p := range projects {
+		doc, err := a.client.GetDocument(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(*dir, names[p.ID])
+		if err := os.WriteFile(path, []byte(doc.ContentMD), 0o644); err != nil {
+			return err
+		}
+		files = append(files, exportedFile{Topic: p.Name, ProjectID: p.ID, Path: path})
+	}
+
+	if a.json {
+		return a.printJSON(files)
+	}
+	for _, f := range files {
+		fmt.Fprintln(a.stdout, f.Path)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		addition string
+		want     string
+	}{
+		{"empty note", "", "- first\n", "- first\n"},
+		{"blank line between", "# Notes\n", "- item", "# Notes\n\n- item\n"},
+		{"trailing newlines collapsed", "# Notes\n\n\n", "\n- item\n\n", "# Notes\n\n- item\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendMarkdown(tt.content, tt.addition); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchingLines(t *testing.T) {
+	content := "# Postgres\n\nAutovacuum settings\n- tune VACUUM cost\n"
+
+	got := matchingLines(content, "vacuum")
+	want := []searchHit{{Line: 3, Text: "Autovacuum settings"}, {Line: 4, Text: "- tune VACUUM cost"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	if got := matchingLines(content, "mysql"); len(got) != 0 {
+		t.Errorf("Expected no matches, got %+v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// config says which server mdctl talks to and as whom. It is read from a
+// YAML file, then overridden by the environment and finally by flags:
+//
+//	server: http://md-editor.local
+//	token: 3f2a...
+//	workspace: 00000000-0000-0000-0000-000000000001
+type config struct {
+	Server    string `yaml:"server"`
+	Token     string `yaml:"token"`
+	Workspace string `yaml:"workspace"`
+}
+
+// defaultConfigPath is $MDCTL_CONFIG, or config.yaml in the user's mdctl
+// config directory.
+func defaultConfigPath() string {
+	if path := os.Getenv("MDCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mdctl", "config.yaml")
+}
+
+// loadConfig reads the config file at path. A missing file is only an error
+// if the path was given explicitly.
+func loadConfig(path string, explicit bool) (config, error) {
+	cfg := config{Server: defaultServer}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides cfg with MD_EDITOR_URL, MD_EDITOR_TOKEN and
+// MD_EDITOR_WORKSPACE, the variables the md-editor skill uses.
+func (cfg *config) applyEnv(getenv func(string) string) {
+	for key, field := range map[string]*string{
+		"MD_EDITOR_URL":       &cfg.Server,
+		"MD_EDITOR_TOKEN":     &cfg.Token,
+		"MD_EDITOR_WORKSPACE": &cfg.Workspace,
+	} {
+		if value := getenv(key); value != "" {
+			*field = value
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("server: http://notes.local\ntoken: file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path, true)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server != "http://notes.local" || cfg.Token != "file-token" || cfg.Workspace != "" {
+		t.Errorf("Expected the file's settings, got %+v", cfg)
+	}
+
+	env := map[string]string{"MD_EDITOR_TOKEN": "env-token", "MD_EDITOR_WORKSPACE": "ws"}
+	cfg.applyEnv(func(key string) string { return env[key] })
+	if cfg.Server != "http://notes.local" || cfg.Token != "env-token" || cfg.Workspace != "ws" {
+		t.Errorf("Expected the environment to override the file, got %+v", cfg)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	cfg, err := loadConfig(path, false)
+	if err != nil || cfg.Server != defaultServer {
+		t.Errorf("Expected the defaults for a missing default file, got %+v, %v", cfg, err)
+	}
+	if _, err := loadConfig(path, true); err == nil {
+		t.Error("Expected an error for a missing file given with -config")
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(path, true); err == nil {
+		t.Error("Expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,188 @@
+// Command mdctl reads and writes md-editor notes from the terminal.
+//
+//	mdctl ls
+//	mdctl cat "Sprint 12 Retrospective"
+//	echo "- ship it" | mdctl append -create "Release Checklist"
+//
+// Topics are named by project name or ID. The server and token come from
+// the config file, MD_EDITOR_URL and MD_EDITOR_TOKEN, or flags; see
+// mdctl -h.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/pkg/client"
+)
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = []command{
+	{"ls", "[-q query] [-archived] [-pinned]", "List topics", runList},
+	{"cat", "<topic>", "Print a topic's note", runCat},
+	{"new", "<name>", "Create a topic with an empty note", runNew},
+	{"rename", "<topic> <new name>", "Rename a topic", runRename},
+	{"rm", "<topic>", "Move a topic to the trash", runRemove},
+	{"edit", "<topic>", "Edit a topic's note in $EDITOR", runEdit},
+	{"append", "[-create] <topic>", "Append standard input to a topic's note", runAppend},
+	{"search", "[-names] <text>", "Find topics and note lines containing text", runSearch},
+	{"export", "[-o dir] [topic...]", "Write notes to Markdown files", runExport},
+}
+
+// app is what commands run with: the API client and where to read and
+// write.
+type app struct {
+	client *client.Client
+	json   bool
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mdctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "Config file (default $MDCTL_CONFIG or "+defaultConfigPath()+")")
+	server := flags.String("server", "", "Server URL (overrides MD_EDITOR_URL)")
+	token := flags.String("token", "", "API token (overrides MD_EDITOR_TOKEN)")
+	workspace := flags.String("workspace", "", "Workspace ID (overrides MD_EDITOR_WORKSPACE)")
+	jsonOutput := flags.Bool("json", false, "Print JSON instead of text")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	name := flags.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "mdctl: unknown command %q\n", name)
+		flags.Usage()
+		return 2
+	}
+
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	cfg, err := loadConfig(path, explicit)
+	if err != nil {
+		fmt.Fprintf(stderr, "mdctl: %v\n", err)
+		return 1
+	}
+	cfg.applyEnv(os.Getenv)
+	if *server != "" {
+		cfg.Server = *server
+	}
+	if *token != "" {
+		cfg.Token = *token
+	}
+	if *workspace != "" {
+		cfg.Workspace = *workspace
+	}
+
+	var opts []client.Option
+	if cfg.Token != "" {
+		opts = append(opts, client.WithAuth(client.BearerToken(cfg.Token)))
+	}
+	if cfg.Workspace != "" {
+		id, err := uuid.Parse(cfg.Workspace)
+		if err != nil {
+			fmt.Fprintf(stderr, "mdctl: invalid workspace ID %q\n", cfg.Workspace)
+			return 1
+		}
+		opts = append(opts, client.WithWorkspace(id))
+	}
+
+	a := &app{
+		client: client.New(cfg.Server, opts...),
+		json:   *jsonOutput,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	if err := cmd.run(ctx, a, flags.Args()[1:]); err != nil {
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(stderr, "usage: mdctl %s %s\n", cmd.name, cmd.args)
+			return 2
+		}
+		fmt.Fprintf(stderr, "mdctl %s: %v\n", cmd.name, err)
+		return 1
+	}
+	return 0
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprint(out, "usage: mdctl [flags] <command> [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-7s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(out, "\nFlags:\n")
+	flags.PrintDefaults()
+}
+
+// usageError reports that a command was called with the wrong arguments.
+type usageError struct{}
+
+func (usageError) Error() string { return "invalid arguments" }
+
+// Argument counts for parseArgs besides exact ones.
+const (
+	oneOrMore = -1
+	anyNumber = -2
+)
+
+// parseArgs parses a command's flags and checks the number of positional
+// arguments that remain.
+func parseArgs(flags *flag.FlagSet, args []string, want int) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return nil, usageError{}
+	}
+	rest := flags.Args()
+	switch {
+	case want == oneOrMore && len(rest) == 0,
+		want >= 0 && len(rest) != want:
+		return nil, usageError{}
+	}
+	return rest, nil
+}
+
+// topicArg joins the arguments naming a topic, so that quoting a name with
+// spaces is optional where it is the last argument.
+func topicArg(args []string) string {
+	return strings.Join(args, " ")
+}
@@ -3,139 +3,295 @@ package main
 import (
 	"context"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/warriorguo/md-editor/backend/internal/config"
 	"github.com/warriorguo/md-editor/backend/internal/database"
-	"github.com/warriorguo/md-editor/backend/internal/handlers"
+	"github.com/warriorguo/md-editor/backend/internal/filesync"
+	"github.com/warriorguo/md-editor/backend/internal/gitstore"
+	"github.com/warriorguo/md-editor/backend/internal/logging"
+	"github.com/warriorguo/md-editor/backend/internal/mcp"
+	"github.com/warriorguo/md-editor/backend/internal/metrics"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
 	"github.com/warriorguo/md-editor/backend/internal/repository"
+	"github.com/warriorguo/md-editor/backend/internal/repository/sqlite"
+	"github.com/warriorguo/md-editor/backend/internal/server"
 	"github.com/warriorguo/md-editor/backend/internal/services"
 )
 
+// mcpAuthor is the name changes made through -mcp are saved under.
+const mcpAuthor = "mcp"
+
 func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML config file; environment variables override its settings (default $CONFIG_FILE)")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration as YAML and exit")
 	migrateUp := flag.Bool("migrate-up", false, "Run database migrations up")
 	migrateDown := flag.Bool("migrate-down", false, "Run database migrations down")
+	createUser := flag.String("create-user", "", "Create a user with the given name, print its API token and exit")
+	syncDir := flag.String("sync-dir", "", "Sync the projects with a directory of Markdown files (overrides SYNC_DIR)")
+	mcpStdio := flag.Bool("mcp", false, "Serve the Model Context Protocol on stdin and stdout instead of HTTP")
+	mcpWorkspace := flag.String("mcp-workspace", models.DefaultWorkspaceID.String(), "Workspace whose notes -mcp serves")
 	flag.Parse()
 
-	cfg := config.Load()
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Logs go to stderr, which keeps stdout free for -print-config,
+	// -create-user and -mcp.
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.Format, cfg.Log.Level))
+	gin.DebugPrintFunc = func(format string, values ...interface{}) {
+		slog.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+	}
+	if *syncDir != "" {
+		cfg.Sync.Dir = *syncDir
+	}
+
+	if *printConfig {
+		if err := cfg.Write(os.Stdout); err != nil {
+			fatal("Failed to print configuration", err)
+		}
+		return
+	}
 
-	db, err := database.NewPostgres(cfg.DatabaseURL)
+	stores, closeDB, err := openStores(cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
-	defer db.Close()
+	defer closeDB()
 
 	if *migrateUp {
-		if err := database.MigrateUp(cfg.DatabaseURL); err != nil {
-			log.Fatalf("Failed to run migrations up: %v", err)
+		if err := database.MigrateUp(cfg.Database.URL); err != nil {
+			fatal("Failed to run migrations up", err)
 		}
-		log.Println("Migrations completed successfully")
+		slog.Info("Migrations completed successfully")
 		return
 	}
 
 	if *migrateDown {
-		if err := database.MigrateDown(cfg.DatabaseURL); err != nil {
-			log.Fatalf("Failed to run migrations down: %v", err)
+		if err := database.MigrateDown(cfg.Database.URL); err != nil {
+			fatal("Failed to run migrations down", err)
 		}
-		log.Println("Migrations rolled back successfully")
+		slog.Info("Migrations rolled back successfully")
 		return
 	}
 
-	// Auto-migrate on startup in development
-	if cfg.Environment == "development" {
-		if err := database.MigrateUp(cfg.DatabaseURL); err != nil {
-			log.Printf("Warning: Failed to auto-migrate: %v", err)
+	// Auto-migrate on startup if enabled, and always for SQLite, whose
+	// database file is created on first use.
+	if cfg.Features.AutoMigrate || database.IsSQLiteURL(cfg.Database.URL) {
+		if err := database.MigrateUp(cfg.Database.URL); err != nil {
+			slog.Warn("Failed to auto-migrate", "error", err)
 		}
 	}
 
-	// Initialize repositories
-	projectRepo := repository.NewProjectRepository(db)
-	documentRepo := repository.NewDocumentRepository(db)
+	// Git storage keeps every document save as a commit, made in the
+	// background once the save is
+	var history services.DocumentHistory
+	if cfg.Git.Dir != "" {
+		repo, err := gitstore.Open(cfg.Git.Dir, cfg.Git.Remote)
+		if err != nil {
+			fatal("Failed to open git storage", err)
+		}
+		history = repo
+	}
 
 	// Initialize services
-	projectService := services.NewProjectService(projectRepo, documentRepo)
-	documentService := services.NewDocumentService(documentRepo)
-
-	// Initialize handlers
-	projectHandler := handlers.NewProjectHandler(projectService)
-	documentHandler := handlers.NewDocumentHandler(documentService)
+	webhookService := services.NewWebhookService(stores.Webhooks)
+	events := services.Publishers{webhookService}
 
-	// Setup router
-	if cfg.Environment == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	// The syncer learns about changes from the same events as webhooks
+	var syncer *filesync.Syncer
+	if cfg.Sync.Dir != "" {
+		syncer = filesync.New(cfg.Sync.Dir, cfg.Sync.WorkspaceID)
+		events = append(events, syncer)
 	}
 
-	router := gin.Default()
-
-	// CORS configuration
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "X-Document-Version"},
-		ExposeHeaders:    []string{"Content-Length", "X-Document-Version"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	// API routes
-	api := router.Group("/api")
-	{
-		projects := api.Group("/projects")
-		{
-			projects.POST("", projectHandler.Create)
-			projects.GET("", projectHandler.List)
-			projects.GET("/:id", projectHandler.Get)
-			projects.PATCH("/:id", projectHandler.Update)
-			projects.DELETE("/:id", projectHandler.Delete)
-			projects.GET("/:id/document", projectHandler.GetDocument)
+	pageSizes := services.PageSizes{Default: cfg.Limits.DefaultPageSize, Max: cfg.Limits.MaxPageSize}
+	projectService := services.NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, events, history, pageSizes)
+	documentService := services.NewDocumentService(stores.Tx, stores.Documents, stores.Projects, events, history, cfg.Limits.MaxDocumentBytes)
+	shareLinkService := services.NewShareLinkService(stores.ShareLinks, stores.Projects, stores.Documents)
+	userService := services.NewUserService(stores.Tx, stores.Users, stores.Workspaces)
+	workspaceService := services.NewWorkspaceService(stores.Tx, stores.Workspaces, stores.Users, cfg.Features.AllowAnonymous)
+	auditService := services.NewAuditService(stores.Audit)
+	templateService := services.NewTemplateService(stores.Templates, stores.Projects, stores.Documents, cfg.Limits.MaxDocumentBytes)
+	idempotencyService := services.NewIdempotencyService(stores.Idempotency, time.Duration(cfg.Retention.IdempotencyKeyHours)*time.Hour)
+	trashService := services.NewTrashService(stores.Tx, stores.Projects, time.Duration(cfg.Retention.TrashDays)*24*time.Hour, pageSizes)
+
+	if *createUser != "" {
+		user, token, err := userService.Create(context.Background(), *createUser)
+		if err != nil {
+			fatal("Failed to create user", err)
 		}
+		slog.Info("Created user", "user", user.Name, "user_id", user.ID)
+		fmt.Println(token)
+		return
+	}
 
-		documents := api.Group("/documents")
-		{
-			documents.PUT("/:id", documentHandler.Update)
+	if *mcpStdio {
+		if err := serveMCP(stores, projectService, documentService, auditService, history, *mcpWorkspace); err != nil {
+			fatal("MCP server failed", err)
 		}
+		return
 	}
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	// Setup router
+	if cfg.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router, err := server.NewRouter(server.Services{
+		Projects:    projectService,
+		Documents:   documentService,
+		ShareLinks:  shareLinkService,
+		Users:       userService,
+		Workspaces:  workspaceService,
+		Audit:       auditService,
+		Templates:   templateService,
+		Idempotency: idempotencyService,
+		Trash:       trashService,
+		Webhooks:    webhookService,
+	}, server.Options{
+		AllowedOrigins:      cfg.CORS.AllowedOrigins,
+		MaxRequestBodyBytes: cfg.Limits.MaxRequestBodyBytes,
+		RateLimit: middleware.RateLimitConfig{
+			ReadPerMinute:  cfg.RateLimit.ReadPerMinute,
+			ReadBurst:      cfg.RateLimit.ReadBurst,
+			WritePerMinute: cfg.RateLimit.WritePerMinute,
+			WriteBurst:     cfg.RateLimit.WriteBurst,
+		},
+		ValidateResponses: cfg.Features.ValidateResponses,
 	})
+	if err != nil {
+		fatal("Failed to set up router", err)
+	}
 
 	// Create server
 	srv := &http.Server{
-		Addr:    ":" + cfg.ServerPort,
+		Addr:    ":" + cfg.Server.Port,
 		Handler: router,
 	}
 
 	// Graceful shutdown
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("Failed to start server", err)
 		}
 	}()
 
-	log.Printf("Server started on port %s", cfg.ServerPort)
+	slog.Info("Server started", "port", cfg.Server.Port)
+
+	// Background workers stop when the server shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	webhookDispatcher := services.NewWebhookDispatcher(stores.Webhooks, services.DefaultWebhookDispatcherConfig())
+	go webhookDispatcher.Run(workerCtx)
+	go trashService.RunPurge(workerCtx, time.Hour)
+	go idempotencyService.RunPurge(workerCtx, time.Hour)
+	if history != nil {
+		go services.NewHistoryCommitter(stores.Documents, history).Run(workerCtx, time.Second)
+	}
+	if syncer != nil {
+		go func() {
+			if err := syncer.Run(workerCtx, projectService, documentService, time.Minute); err != nil {
+				slog.Error("Directory sync stopped", "error", err)
+			}
+		}()
+		slog.Info("Syncing projects", "dir", cfg.Sync.Dir)
+	}
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
+	stopWorkers()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("Server forced to shutdown", err)
+	}
+
+	slog.Info("Server exited")
+}
+
+// serveMCP serves the notes of a workspace over the Model Context Protocol
+// on stdin and stdout until stdin is closed. Changes are made and audited
+// under the name "mcp".
+func serveMCP(stores *repository.Stores, projects *services.ProjectService, documents *services.DocumentService, audit *services.AuditService, history services.DocumentHistory, workspace string) error {
+	workspaceID, err := uuid.Parse(workspace)
+	if err != nil {
+		return fmt.Errorf("invalid -mcp-workspace: %w", err)
+	}
+	ws, err := stores.Workspaces.GetByID(context.Background(), workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to load workspace %s: %w", workspaceID, err)
+	}
+	if ws == nil {
+		return fmt.Errorf("workspace %s does not exist", workspaceID)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if history != nil {
+		go services.NewHistoryCommitter(stores.Documents, history).Run(ctx, time.Second)
+	}
+
+	caller := mcp.Caller{
+		WorkspaceID: workspaceID,
+		Author:      mcpAuthor,
+		Record: func(event models.AuditEvent) {
+			event.WorkspaceID = workspaceID
+			event.ActorName = mcpAuthor
+			if err := audit.Record(ctx, &event); err != nil {
+				slog.ErrorContext(ctx, "Failed to record audit event", "action", event.Action, "error", err)
+			}
+		},
 	}
+	slog.Info("Serving MCP on stdio", "workspace_id", workspaceID)
+	return mcp.New(projects, documents).ServeStdio(ctx, os.Stdin, os.Stdout, caller)
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-	log.Println("Server exited")
+// openStores connects to the configured database and returns its stores
+// together with a function that closes the connection.
+func openStores(cfg config.DatabaseConfig) (*repository.Stores, func(), error) {
+	if database.IsSQLiteURL(cfg.URL) {
+		db, err := database.NewSQLite(cfg.URL)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqlite.NewStores(db), db.Close, nil
+	}
+
+	db, err := database.NewPostgres(cfg.URL, cfg.Pool())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := metrics.RegisterPool(db.Pool); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return repository.NewPostgresStores(db), db.Close, nil
 }
@@ -0,0 +1,118 @@
+// Package apperror defines the errors that services return to clients. Each
+// carries a stable, machine-readable code and the HTTP status it is reported
+// with, and is rendered as RFC 9457 problem details.
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ContentType is the media type of problem detail responses.
+const ContentType = "application/problem+json"
+
+// Codes that are not tied to a single service.
+const (
+	CodeInternal         = "internal_error"
+	CodeValidationFailed = "validation_failed"
+	CodeMalformedBody    = "malformed_body"
+	CodeBodyTooLarge     = "body_too_large"
+	CodeRateLimited      = "rate_limited"
+)
+
+// Error is an error with a code clients can rely on instead of the message.
+// Errors are compared by identity, so services declare them as sentinels and
+// wrap them with fmt.Errorf to add detail.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// Fields lists the invalid fields of a validation_failed error.
+	Fields []FieldError
+}
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Validation returns a validation_failed error for the given fields.
+func Validation(fields ...FieldError) *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: "request validation failed",
+		Fields:  fields,
+	}
+}
+
+// InvalidParam returns a validation_failed error for a single path, query or
+// header parameter.
+func InvalidParam(field, message string) *Error {
+	return Validation(FieldError{Field: field, Code: "invalid", Message: message})
+}
+
+// Problem is an RFC 9457 problem details object. Its type is always
+// about:blank, so the title is the status text and clients branch on Code.
+type Problem struct {
+	Title  string
+	Status int
+	Detail string
+	Code   string
+	Errors []FieldError
+	// Extensions are additional members, such as the target of a redirect.
+	Extensions map[string]interface{}
+}
+
+// ProblemFor describes err. Errors without a code are reported as internal
+// errors with the fallback detail, so that database errors and the like never
+// reach clients.
+func ProblemFor(err error, fallback string) Problem {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		return Problem{
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+			Detail: fallback,
+			Code:   CodeInternal,
+		}
+	}
+
+	// Wrapping adds context to the message, e.g. the heading that was not
+	// found, so the detail is the full error text.
+	return Problem{
+		Title:  http.StatusText(appErr.Status),
+		Status: appErr.Status,
+		Detail: err.Error(),
+		Code:   appErr.Code,
+		Errors: appErr.Fields,
+	}
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	members["title"] = p.Title
+	members["status"] = p.Status
+	members["code"] = p.Code
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if len(p.Errors) > 0 {
+		members["errors"] = p.Errors
+	}
+	return json.Marshal(members)
+}
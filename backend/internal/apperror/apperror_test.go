@@ -0,0 +1,59 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestProblemFor(t *testing.T) {
+	notFound := New(http.StatusNotFound, "project_not_found", "project not found")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{name: "coded", err: notFound, wantStatus: 404, wantCode: "project_not_found", wantDetail: "project not found"},
+		{name: "wrapped", err: fmt.Errorf("%w: Intro", notFound), wantStatus: 404, wantCode: "project_not_found", wantDetail: "project not found: Intro"},
+		{name: "uncoded", err: errors.New("connection refused"), wantStatus: 500, wantCode: CodeInternal, wantDetail: "Failed to load"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ProblemFor(tt.err, "Failed to load")
+			if p.Status != tt.wantStatus || p.Code != tt.wantCode || p.Detail != tt.wantDetail {
+				t.Errorf("Got %d %q %q, want %d %q %q", p.Status, p.Code, p.Detail, tt.wantStatus, tt.wantCode, tt.wantDetail)
+			}
+			if p.Title != http.StatusText(tt.wantStatus) {
+				t.Errorf("Expected title %q, got %q", http.StatusText(tt.wantStatus), p.Title)
+			}
+		})
+	}
+}
+
+func TestProblemJSON(t *testing.T) {
+	p := ProblemFor(InvalidParam("sort", "expected name"), "")
+	p.Extensions = map[string]interface{}{"redirectTo": "elsewhere"}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if got["code"] != CodeValidationFailed || got["status"] != float64(400) || got["redirectTo"] != "elsewhere" {
+		t.Errorf("Unexpected problem %s", data)
+	}
+	fields, _ := got["errors"].([]interface{})
+	if len(fields) != 1 || fields[0].(map[string]interface{})["field"] != "sort" {
+		t.Errorf("Expected the sort field error, got %s", data)
+	}
+}
@@ -6,12 +6,19 @@ import (
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// SQLite has its own migrations since the Postgres ones rely on extensions,
+// arrays and PL/pgSQL.
+//
+//go:embed migrations_sqlite/*.sql
+var sqliteMigrationsFS embed.FS
+
 func MigrateUp(databaseURL string) error {
 	m, err := getMigrator(databaseURL)
 	if err != nil {
@@ -39,7 +46,13 @@ func MigrateDown(databaseURL string) error {
 }
 
 func getMigrator(databaseURL string) (*migrate.Migrate, error) {
-	source, err := iofs.New(migrationsFS, "migrations")
+	fsys, dir := migrationsFS, "migrations"
+	if IsSQLiteURL(databaseURL) {
+		fsys, dir = sqliteMigrationsFS, "migrations_sqlite"
+		databaseURL = SQLiteScheme + SQLitePath(databaseURL)
+	}
+
+	source, err := iofs.New(fsys, dir)
 	if err != nil {
 		return nil, err
 	}
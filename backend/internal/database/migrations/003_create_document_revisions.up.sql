@@ -0,0 +1,11 @@
+CREATE TABLE document_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    content_md TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (document_id, version)
+);
+
+INSERT INTO document_revisions (document_id, version, content_md, created_at)
+SELECT id, version, content_md, updated_at FROM documents;
@@ -0,0 +1,33 @@
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    workspace_id UUID NOT NULL,
+    actor_id UUID,
+    actor_name VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id UUID,
+    project_id UUID,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    before_version INTEGER,
+    after_version INTEGER,
+    details JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_audit_events_workspace_id ON audit_events(workspace_id, id DESC);
+CREATE INDEX idx_audit_events_project_id ON audit_events(project_id, id DESC);
+CREATE INDEX idx_audit_events_actor_name ON audit_events(actor_name, id DESC);
+
+-- Audit rows reference projects and users by value only, so they outlive
+-- the entities they describe, and they can never be changed afterwards.
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_projects_workspace_name;
+DROP INDEX IF EXISTS idx_projects_workspace_updated_at;
+DROP INDEX IF EXISTS idx_projects_name_trgm;
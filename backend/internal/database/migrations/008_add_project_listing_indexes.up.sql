@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_projects_name_trgm ON projects USING gin (name gin_trgm_ops);
+CREATE INDEX idx_projects_workspace_updated_at ON projects(workspace_id, updated_at DESC, id DESC);
+CREATE INDEX idx_projects_workspace_name ON projects(workspace_id, name, id);
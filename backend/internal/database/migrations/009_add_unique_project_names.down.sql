@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS projects_set_name_key ON projects;
+DROP FUNCTION IF EXISTS projects_set_name_key();
+DROP INDEX IF EXISTS idx_projects_workspace_lower_name;
+DROP INDEX IF EXISTS idx_projects_unique_name;
+ALTER TABLE projects DROP COLUMN IF EXISTS name_key;
+ALTER TABLE workspaces DROP COLUMN IF EXISTS unique_project_names;
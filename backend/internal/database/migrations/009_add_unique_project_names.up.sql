@@ -0,0 +1,22 @@
+ALTER TABLE workspaces ADD COLUMN unique_project_names BOOLEAN NOT NULL DEFAULT false;
+
+-- name_key is the lower-cased name in workspaces that require unique project
+-- names and NULL elsewhere, so the unique index only binds those workspaces.
+ALTER TABLE projects ADD COLUMN name_key TEXT;
+
+CREATE UNIQUE INDEX idx_projects_unique_name ON projects(workspace_id, name_key) WHERE deleted_at IS NULL;
+CREATE INDEX idx_projects_workspace_lower_name ON projects(workspace_id, lower(name)) WHERE deleted_at IS NULL;
+
+CREATE FUNCTION projects_set_name_key() RETURNS trigger AS $$
+BEGIN
+    SELECT CASE WHEN w.unique_project_names THEN lower(NEW.name) END
+    INTO NEW.name_key
+    FROM workspaces w
+    WHERE w.id = NEW.workspace_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER projects_set_name_key
+    BEFORE INSERT OR UPDATE OF name, workspace_id ON projects
+    FOR EACH ROW EXECUTE FUNCTION projects_set_name_key();
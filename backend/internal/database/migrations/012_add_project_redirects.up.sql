@@ -0,0 +1,3 @@
+-- A project merged into another is soft-deleted and points at the project
+-- that now holds its content.
+ALTER TABLE projects ADD COLUMN redirect_to UUID REFERENCES projects(id) ON DELETE SET NULL;
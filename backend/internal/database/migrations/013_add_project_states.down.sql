@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS project_favourites;
+ALTER TABLE projects DROP COLUMN IF EXISTS pinned_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS archived_at;
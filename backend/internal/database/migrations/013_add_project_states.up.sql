@@ -0,0 +1,13 @@
+-- Archived projects are hidden from listings and search and cannot be
+-- edited; pinned projects are listed first.
+ALTER TABLE projects ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE projects ADD COLUMN pinned_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE project_favourites (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, project_id)
+);
+
+CREATE INDEX idx_project_favourites_project_id ON project_favourites(project_id);
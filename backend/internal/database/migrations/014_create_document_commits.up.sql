@@ -0,0 +1,10 @@
+-- Outbox of saved document versions still to be committed to git storage.
+-- Rows are written in the transaction of the save and drained in id order,
+-- which is version order for each document.
+CREATE TABLE document_commits (
+    id BIGSERIAL PRIMARY KEY,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS templates;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS share_links;
+DROP TABLE IF EXISTS document_revisions;
+DROP TABLE IF EXISTS documents;
+DROP TABLE IF EXISTS project_favourites;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS workspaces;
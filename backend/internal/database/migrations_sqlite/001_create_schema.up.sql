@@ -0,0 +1,199 @@
+-- The SQLite schema matches the Postgres one as of its migration 013. UUIDs
+-- are stored as text, times as UTC text in a fixed format, arrays and JSON
+-- as JSON text.
+
+CREATE TABLE workspaces (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    unique_project_names BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO workspaces (id, name, created_at, updated_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', strftime('%Y-%m-%d %H:%M:%f000000+00:00'), strftime('%Y-%m-%d %H:%M:%f000000+00:00'));
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE workspace_members (
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(32) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+CREATE TABLE projects (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id),
+    name VARCHAR(255) NOT NULL,
+    name_key TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    archived_at TIMESTAMP,
+    pinned_at TIMESTAMP,
+    redirect_to TEXT REFERENCES projects(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_projects_workspace_id ON projects(workspace_id, created_at DESC);
+CREATE INDEX idx_projects_workspace_updated_at ON projects(workspace_id, updated_at DESC, id DESC);
+CREATE INDEX idx_projects_workspace_name ON projects(workspace_id, name, id);
+CREATE INDEX idx_projects_deleted_at ON projects(deleted_at);
+CREATE UNIQUE INDEX idx_projects_unique_name ON projects(workspace_id, name_key) WHERE deleted_at IS NULL;
+
+-- name_key is the lower-cased name in workspaces that require unique project
+-- names and NULL elsewhere, so the unique index only binds those workspaces.
+CREATE TRIGGER projects_set_name_key_insert
+    AFTER INSERT ON projects
+BEGIN
+    UPDATE projects
+    SET name_key = (SELECT CASE WHEN w.unique_project_names THEN lower(NEW.name) END FROM workspaces w WHERE w.id = NEW.workspace_id)
+    WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER projects_set_name_key_update
+    AFTER UPDATE OF name, workspace_id ON projects
+BEGIN
+    UPDATE projects
+    SET name_key = (SELECT CASE WHEN w.unique_project_names THEN lower(NEW.name) END FROM workspaces w WHERE w.id = NEW.workspace_id)
+    WHERE id = NEW.id;
+END;
+
+CREATE TABLE project_favourites (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, project_id)
+);
+
+CREATE INDEX idx_project_favourites_project_id ON project_favourites(project_id);
+
+CREATE TABLE documents (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL UNIQUE REFERENCES projects(id) ON DELETE CASCADE,
+    content_md TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE document_revisions (
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    content_md TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (document_id, version)
+);
+
+CREATE TABLE share_links (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    password_hash VARCHAR(255),
+    pinned_version INTEGER,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_share_links_project_id ON share_links(project_id);
+
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at TIMESTAMP NOT NULL,
+    workspace_id TEXT NOT NULL,
+    actor_id TEXT,
+    actor_name VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id TEXT,
+    project_id TEXT,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    before_version INTEGER,
+    after_version INTEGER,
+    details TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_audit_events_workspace_id ON audit_events(workspace_id, id DESC);
+CREATE INDEX idx_audit_events_project_id ON audit_events(project_id, id DESC);
+CREATE INDEX idx_audit_events_actor_name ON audit_events(actor_name, id DESC);
+
+-- Audit rows reference projects and users by value only, so they outlive
+-- the entities they describe, and they can never be changed afterwards.
+CREATE TRIGGER audit_events_no_update
+    BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER audit_events_no_delete
+    BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TABLE webhooks (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '[]',
+    secret VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_webhooks_workspace_id ON webhooks(workspace_id);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_status_code INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, id DESC);
+
+CREATE TABLE idempotency_keys (
+    scope VARCHAR(128) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_headers TEXT,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+CREATE TABLE templates (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    content_md TEXT NOT NULL DEFAULT '',
+    source_project_id TEXT REFERENCES projects(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_templates_workspace_id ON templates(workspace_id, name);
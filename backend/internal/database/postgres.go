@@ -7,34 +7,42 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// PoolConfig sizes the connection pool.
+type PoolConfig struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+}
+
 type Postgres struct {
 	Pool *pgxpool.Pool
 }
 
-func NewPostgres(databaseURL string) (*Postgres, error) {
+func NewPostgres(databaseURL string, pool PoolConfig) (*Postgres, error) {
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	config.MaxConns = 25
-	config.MinConns = 5
-	config.MaxConnLifetime = time.Hour
-	config.MaxConnIdleTime = 30 * time.Minute
+	config.MaxConns = pool.MaxConns
+	config.MinConns = pool.MinConns
+	config.MaxConnLifetime = pool.MaxConnLifetime
+	config.MaxConnIdleTime = pool.MaxConnIdleTime
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	p, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := pool.Ping(ctx); err != nil {
+	if err := p.Ping(ctx); err != nil {
 		return nil, err
 	}
 
-	return &Postgres{Pool: pool}, nil
+	return &Postgres{Pool: p}, nil
 }
 
 func (p *Postgres) Close() {
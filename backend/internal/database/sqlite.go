@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteScheme prefixes database URLs that select the embedded SQLite
+// backend, as in sqlite:///var/lib/md-editor/data.db.
+const SQLiteScheme = "sqlite://"
+
+// sqliteTimeFormat stores times in UTC with a fixed number of digits so that
+// they compare correctly as text.
+const sqliteTimeFormat = "2006-01-02 15:04:05.000000000+00:00"
+
+// IsSQLiteURL reports whether databaseURL selects the SQLite backend.
+func IsSQLiteURL(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, SQLiteScheme)
+}
+
+// SQLitePath returns the file path of a sqlite:// URL.
+func SQLitePath(databaseURL string) string {
+	path := strings.TrimPrefix(databaseURL, SQLiteScheme)
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
+
+type SQLite struct {
+	DB *sql.DB
+}
+
+func NewSQLite(databaseURL string) (*SQLite, error) {
+	dsn := "file:" + SQLitePath(databaseURL) +
+		"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer. One connection serializes all access so
+	// that transactions never fail with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLite{DB: db}, nil
+}
+
+func (s *SQLite) Close() {
+	s.DB.Close()
+}
+
+// SQLQuerier is the part of *sql.DB and *sql.Tx that repositories use.
+type SQLQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type sqliteTxKey struct{}
+
+// Querier returns the transaction started by InTx for ctx, or the database
+// when there is none. Time arguments are converted to sqliteTimeFormat.
+func (s *SQLite) Querier(ctx context.Context) SQLQuerier {
+	if tx, ok := ctx.Value(sqliteTxKey{}).(*sql.Tx); ok {
+		return sqliteQuerier{tx}
+	}
+	return sqliteQuerier{s.DB}
+}
+
+// InTx runs fn in a transaction that repositories called with the context
+// passed to fn take part in. The transaction is committed if fn returns nil
+// and rolled back otherwise. Nested calls join the outer transaction.
+func (s *SQLite) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(sqliteTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, sqliteTxKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type sqliteQuerier struct {
+	q SQLQuerier
+}
+
+func (q sqliteQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return q.q.ExecContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (q sqliteQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return q.q.QueryContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (q sqliteQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.q.QueryRowContext(ctx, query, sqliteArgs(args)...)
+}
+
+func sqliteArgs(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = FormatSQLiteTime(v)
+		case *time.Time:
+			if v != nil {
+				converted[i] = FormatSQLiteTime(*v)
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+// FormatSQLiteTime formats t the way the SQLite backend stores times.
+func FormatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"database/sql/driver"
+	"strings"
+	"unicode"
+
+	"modernc.org/sqlite"
+)
+
+// SQLiteSimilarityThreshold is the similarity above which the SQLite backend
+// considers two names alike, the default of pg_trgm's % operator.
+const SQLiteSimilarityThreshold = 0.3
+
+// The SQLite backend provides the Postgres functions its queries rely on.
+// lower replaces the built-in, which only folds ASCII letters.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("similarity", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		a, _ := args[0].(string)
+		b, _ := args[1].(string)
+		return Similarity(a, b), nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("lower", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if s, ok := args[0].(string); ok {
+			return strings.ToLower(s), nil
+		}
+		return args[0], nil
+	})
+}
+
+// Similarity returns the trigram similarity of a and b from 0 to 1, computed
+// like pg_trgm: the share of trigrams the two have in common, where each
+// lower-cased word is padded with two spaces in front and one behind.
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+func trigrams(s string) map[string]bool {
+	set := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
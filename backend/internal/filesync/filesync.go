@@ -0,0 +1,483 @@
+// Package filesync mirrors the documents of a workspace to a directory of
+// Markdown files, one per project, and feeds edits made to those files back
+// through the document service.
+package filesync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+const (
+	// stateFile records what was last synced, so that a restart can tell
+	// which side changed in the meantime.
+	stateFile = ".md-editor-sync.json"
+
+	// conflictMarker is part of the name of conflict copies, which are never
+	// synced back.
+	conflictMarker = ".conflict-"
+
+	// Author is the name edits from the directory are saved under.
+	Author = "sync"
+
+	// debounce lets editors finish writing a file before it is read.
+	debounce = 500 * time.Millisecond
+)
+
+// Projects is the part of the project service the syncer uses.
+type Projects interface {
+	List(ctx context.Context, q models.ProjectListQuery) (*models.ProjectListResponse, error)
+	Create(ctx context.Context, workspaceID uuid.UUID, name, author string) (*models.Project, error)
+	Update(ctx context.Context, workspaceID, id uuid.UUID, name string) (*models.Project, error)
+	GetDocument(ctx context.Context, workspaceID, projectID uuid.UUID) (*models.Document, error)
+}
+
+// Documents is the part of the document service the syncer uses.
+type Documents interface {
+	Update(ctx context.Context, workspaceID, id uuid.UUID, contentMD string, expectedVersion int, author string) (*models.Document, error)
+}
+
+// syncedFile is the state of a project's file after the last sync.
+type syncedFile struct {
+	File    string `json:"file"`
+	Version int    `json:"version"`
+	Hash    string `json:"hash"`
+}
+
+// Syncer keeps dir and the projects of a workspace in step. Every live,
+// unarchived project is mirrored to <dir>/<project name>.md. When both the
+// file and the document changed since the last sync, the document wins and
+// the file's content is kept in a conflict copy next to it.
+type Syncer struct {
+	dir         string
+	workspaceID uuid.UUID
+	projects    Projects
+	documents   Documents
+	state       map[uuid.UUID]*syncedFile
+	changes     chan struct{}
+}
+
+// New creates a syncer for dir. It publishes nothing until Run is called, but
+// should be registered as an event publisher before the services are
+// created so that it learns about every change.
+func New(dir string, workspaceID uuid.UUID) *Syncer {
+	return &Syncer{
+		dir:         dir,
+		workspaceID: workspaceID,
+		changes:     make(chan struct{}, 1),
+	}
+}
+
+// Publish schedules a sync after a change to the synced workspace.
+func (s *Syncer) Publish(ctx context.Context, workspaceID uuid.UUID, eventType string, data interface{}) error {
+	if workspaceID != s.workspaceID {
+		return nil
+	}
+	select {
+	case s.changes <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run syncs once, then again whenever a file in the directory or a project
+// of the workspace changes, and every interval in case a change went
+// unnoticed. It returns when ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context, projects Projects, documents Documents, interval time.Duration) error {
+	s.projects = projects
+	s.documents = documents
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(s.dir); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pending := time.NewTimer(0)
+	defer pending.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-watcher.Events:
+			if isSyncedFile(filepath.Base(event.Name)) {
+				pending.Reset(debounce)
+			}
+		case err := <-watcher.Errors:
+			slog.ErrorContext(ctx, "Sync directory watch error", "error", err)
+		case <-s.changes:
+			pending.Reset(debounce)
+		case <-ticker.C:
+			pending.Reset(0)
+		case <-pending.C:
+			if err := s.sync(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to sync directory", "dir", s.dir, "error", err)
+			}
+		}
+	}
+}
+
+// sync brings the directory and the workspace in step and saves the state.
+func (s *Syncer) sync(ctx context.Context) error {
+	projects, err := s.listProjects(ctx)
+	if err != nil {
+		return err
+	}
+	files, err := s.readFiles()
+	if err != nil {
+		return err
+	}
+
+	if s.applyFileRenames(ctx, projects, files) {
+		if projects, err = s.listProjects(ctx); err != nil {
+			return err
+		}
+	}
+
+	names := FileNames(projects)
+	claimed := map[string]bool{}
+
+	for _, p := range projects {
+		name := names[p.ID]
+		claimed[name] = true
+		if err := s.syncProject(ctx, p, name, files); err != nil {
+			slog.ErrorContext(ctx, "Failed to sync project", "project_id", p.ID, "error", err)
+		}
+	}
+
+	live := map[uuid.UUID]bool{}
+	for _, p := range projects {
+		live[p.ID] = true
+	}
+	for id, st := range s.state {
+		if live[id] {
+			continue
+		}
+		claimed[st.File] = true
+		if err := s.removeFile(st, files); err != nil {
+			slog.ErrorContext(ctx, "Failed to remove synced file", "file", st.File, "error", err)
+		}
+		delete(s.state, id)
+	}
+
+	for name, content := range files {
+		if claimed[name] {
+			continue
+		}
+		if err := s.importFile(ctx, name, content); err != nil {
+			slog.ErrorContext(ctx, "Failed to import file", "file", name, "error", err)
+		}
+	}
+
+	return s.saveState()
+}
+
+// syncProject syncs one project with the file called name.
+func (s *Syncer) syncProject(ctx context.Context, p models.Project, name string, files map[string]string) error {
+	doc, err := s.projects.GetDocument(ctx, s.workspaceID, p.ID)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return nil
+	}
+
+	st, known := s.state[p.ID]
+	if known && st.File != name {
+		// The project was renamed, or its name now collides with another.
+		if content, ok := files[st.File]; ok {
+			if _, taken := files[name]; !taken {
+				if err := os.Rename(s.path(st.File), s.path(name)); err != nil {
+					return err
+				}
+				files[name] = content
+				delete(files, st.File)
+			}
+		}
+	}
+
+	content, exists := files[name]
+	switch {
+	case !exists:
+		if err := s.writeFile(name, doc.ContentMD); err != nil {
+			return err
+		}
+	case known && hash(content) == st.Hash:
+		// Only the document may have changed.
+		if doc.Version != st.Version {
+			if err := s.writeFile(name, doc.ContentMD); err != nil {
+				return err
+			}
+		}
+	case known && doc.Version == st.Version:
+		// Only the file changed.
+		updated, err := s.documents.Update(ctx, s.workspaceID, doc.ID, content, doc.Version, Author)
+		if errors.Is(err, services.ErrVersionConflict) {
+			// The document changed meanwhile; the next sync sees both changes.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		doc = updated
+	case content != doc.ContentMD:
+		// Both changed, or the file predates the sync.
+		if err := s.writeFile(conflictName(name, time.Now()), content); err != nil {
+			return err
+		}
+		if err := s.writeFile(name, doc.ContentMD); err != nil {
+			return err
+		}
+	}
+
+	s.state[p.ID] = &syncedFile{File: name, Version: doc.Version, Hash: hash(doc.ContentMD)}
+	return nil
+}
+
+// applyFileRenames renames the projects whose file was renamed in the
+// directory, recognised by an unknown file with the content last synced to a
+// file that has disappeared. It reports whether it renamed any project.
+func (s *Syncer) applyFileRenames(ctx context.Context, projects []models.Project, files map[string]string) bool {
+	tracked := map[string]bool{}
+	for _, st := range s.state {
+		tracked[st.File] = true
+	}
+	live := map[uuid.UUID]bool{}
+	for _, p := range projects {
+		live[p.ID] = true
+	}
+
+	renamed := false
+	for id, st := range s.state {
+		if _, ok := files[st.File]; ok || !live[id] {
+			continue
+		}
+		for name, content := range files {
+			if tracked[name] || hash(content) != st.Hash {
+				continue
+			}
+			if _, err := s.projects.Update(ctx, s.workspaceID, id, projectName(name)); err != nil {
+				slog.ErrorContext(ctx, "Failed to rename project after its file", "project_id", id, "file", name, "error", err)
+				break
+			}
+			st.File = name
+			tracked[name] = true
+			renamed = true
+			break
+		}
+	}
+
+	return renamed
+}
+
+// removeFile removes the file of a project that no longer exists. A file
+// edited since the last sync is kept as a conflict copy instead.
+func (s *Syncer) removeFile(st *syncedFile, files map[string]string) error {
+	content, ok := files[st.File]
+	if !ok {
+		return nil
+	}
+	if hash(content) == st.Hash {
+		return os.Remove(s.path(st.File))
+	}
+	return os.Rename(s.path(st.File), s.path(conflictName(st.File, time.Now())))
+}
+
+// importFile creates a project for a new file in the directory.
+func (s *Syncer) importFile(ctx context.Context, name, content string) error {
+	project, err := s.projects.Create(ctx, s.workspaceID, projectName(name), Author)
+	if err != nil {
+		return err
+	}
+
+	doc, err := s.projects.GetDocument(ctx, s.workspaceID, project.ID)
+	if err != nil {
+		return err
+	}
+	if content != doc.ContentMD {
+		if doc, err = s.documents.Update(ctx, s.workspaceID, doc.ID, content, doc.Version, Author); err != nil {
+			return err
+		}
+	}
+
+	s.state[project.ID] = &syncedFile{File: name, Version: doc.Version, Hash: hash(doc.ContentMD)}
+	return nil
+}
+
+// listProjects returns the live, unarchived projects of the workspace,
+// oldest first.
+func (s *Syncer) listProjects(ctx context.Context) ([]models.Project, error) {
+	var projects []models.Project
+	for page := 1; ; page++ {
+		resp, err := s.projects.List(ctx, models.ProjectListQuery{
+			WorkspaceID: s.workspaceID,
+			Sort:        models.ProjectSortCreatedAt,
+			Page:        page,
+			PageSize:    100,
+		})
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, resp.Projects...)
+		if resp.NextCursor == "" {
+			return projects, nil
+		}
+	}
+}
+
+// readFiles returns the content of every synced file in the directory.
+func (s *Syncer) readFiles() (map[string]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{}
+	for _, e := range entries {
+		if e.IsDir() || !isSyncedFile(e.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(s.path(e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[e.Name()] = string(content)
+	}
+
+	return files, nil
+}
+
+// writeFile replaces a file in one step so that watchers never see it half
+// written.
+func (s *Syncer) writeFile(name, content string) error {
+	tmp, err := os.CreateTemp(s.dir, ".sync-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+func (s *Syncer) loadState() error {
+	s.state = map[uuid.UUID]*syncedFile{}
+
+	data, err := os.ReadFile(s.path(stateFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &s.state)
+}
+
+func (s *Syncer) saveState() error {
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(stateFile, string(data))
+}
+
+func (s *Syncer) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// FileNames assigns each project its file name. Projects whose names map to
+// the same file, ignoring case, get their short ID appended, except for the
+// oldest of them.
+func FileNames(projects []models.Project) map[uuid.UUID]string {
+	sorted := append([]models.Project(nil), projects...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	names := make(map[uuid.UUID]string, len(projects))
+	taken := map[string]bool{}
+	for _, p := range sorted {
+		base := sanitizeName(p.Name)
+		name := base + ".md"
+		if taken[strings.ToLower(name)] {
+			name = fmt.Sprintf("%s (%s).md", base, p.ID.String()[:8])
+		}
+		taken[strings.ToLower(name)] = true
+		names[p.ID] = name
+	}
+
+	return names
+}
+
+// sanitizeName turns a project name into a file name without extension.
+func sanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "untitled"
+	}
+	return name
+}
+
+// projectName is the name of the project a new file is imported as.
+func projectName(file string) string {
+	return strings.TrimSuffix(file, ".md")
+}
+
+// conflictName names the conflict copy of file made at t.
+func conflictName(file string, t time.Time) string {
+	return strings.TrimSuffix(file, ".md") + conflictMarker + t.Format("20060102-150405") + ".md"
+}
+
+// isSyncedFile reports whether a file in the directory is mirrored to a
+// project, which excludes hidden files and conflict copies.
+func isSyncedFile(name string) bool {
+	return strings.HasSuffix(name, ".md") && !strings.HasPrefix(name, ".") && !strings.Contains(name, conflictMarker)
+}
+
+func hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
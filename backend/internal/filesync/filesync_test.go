@@ -0,0 +1,282 @@
+package filesync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+// fakeWorkspace implements Projects and Documents in memory.
+type fakeWorkspace struct {
+	projects []*models.Project
+	docs     map[uuid.UUID]*models.Document
+}
+
+func (f *fakeWorkspace) List(ctx context.Context, q models.ProjectListQuery) (*models.ProjectListResponse, error) {
+	resp := &models.ProjectListResponse{Page: q.Page, PageSize: q.PageSize}
+	for _, p := range f.projects {
+		resp.Projects = append(resp.Projects, *p)
+	}
+	return resp, nil
+}
+
+func (f *fakeWorkspace) Create(ctx context.Context, workspaceID uuid.UUID, name, author string) (*models.Project, error) {
+	p := &models.Project{ID: uuid.New(), WorkspaceID: workspaceID, Name: name, CreatedAt: time.Now()}
+	f.projects = append(f.projects, p)
+	f.docs[p.ID] = &models.Document{ID: uuid.New(), ProjectID: p.ID, Version: 1}
+	return p, nil
+}
+
+func (f *fakeWorkspace) Update(ctx context.Context, workspaceID, id uuid.UUID, name string) (*models.Project, error) {
+	for _, p := range f.projects {
+		if p.ID == id {
+			p.Name = name
+			return p, nil
+		}
+	}
+	return nil, services.ErrProjectNotFound
+}
+
+func (f *fakeWorkspace) GetDocument(ctx context.Context, workspaceID, projectID uuid.UUID) (*models.Document, error) {
+	doc := *f.docs[projectID]
+	return &doc, nil
+}
+
+func (f *fakeWorkspace) UpdateDocument(ctx context.Context, workspaceID, id uuid.UUID, contentMD string, expectedVersion int, author string) (*models.Document, error) {
+	for _, doc := range f.docs {
+		if doc.ID != id {
+			continue
+		}
+		if doc.Version != expectedVersion {
+			return nil, services.ErrVersionConflict
+		}
+		doc.ContentMD = contentMD
+		doc.Version++
+		updated := *doc
+		return &updated, nil
+	}
+	return nil, services.ErrDocumentNotFound
+}
+
+// editDocument changes a document as an API client would.
+func (f *fakeWorkspace) editDocument(p *models.Project, content string) {
+	f.docs[p.ID].ContentMD = content
+	f.docs[p.ID].Version++
+}
+
+type documentsFunc func(ctx context.Context, workspaceID, id uuid.UUID, contentMD string, expectedVersion int, author string) (*models.Document, error)
+
+func (f documentsFunc) Update(ctx context.Context, workspaceID, id uuid.UUID, contentMD string, expectedVersion int, author string) (*models.Document, error) {
+	return f(ctx, workspaceID, id, contentMD, expectedVersion, author)
+}
+
+func newTestSyncer(t *testing.T) (*Syncer, *fakeWorkspace) {
+	t.Helper()
+
+	ws := &fakeWorkspace{docs: map[uuid.UUID]*models.Document{}}
+	s := New(t.TempDir(), models.DefaultWorkspaceID)
+	s.projects = ws
+	s.documents = documentsFunc(ws.UpdateDocument)
+	if err := s.loadState(); err != nil {
+		t.Fatalf("loadState failed: %v", err)
+	}
+	return s, ws
+}
+
+func mustSync(t *testing.T, s *Syncer) {
+	t.Helper()
+	if err := s.sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+}
+
+func readFile(t *testing.T, s *Syncer, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	return string(content)
+}
+
+func writeFile(t *testing.T, s *Syncer, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func conflictCopies(t *testing.T, s *Syncer) []string {
+	t.Helper()
+	matches, _ := filepath.Glob(filepath.Join(s.dir, "*"+conflictMarker+"*"))
+	return matches
+}
+
+func TestSyncBothDirections(t *testing.T) {
+	s, ws := newTestSyncer(t)
+	p, _ := ws.Create(context.Background(), models.DefaultWorkspaceID, "Notes", "alice")
+	ws.editDocument(p, "# Notes\n")
+
+	mustSync(t, s)
+	if got := readFile(t, s, "Notes.md"); got != "# Notes\n" {
+		t.Fatalf("Expected document mirrored, got %q", got)
+	}
+
+	writeFile(t, s, "Notes.md", "# Notes\nfrom the editor\n")
+	mustSync(t, s)
+	if doc := ws.docs[p.ID]; doc.ContentMD != "# Notes\nfrom the editor\n" || doc.Version != 3 {
+		t.Errorf("Expected file edit saved as version 3, got %+v", doc)
+	}
+
+	ws.editDocument(p, "# Notes\nfrom the app\n")
+	mustSync(t, s)
+	if got := readFile(t, s, "Notes.md"); got != "# Notes\nfrom the app\n" {
+		t.Errorf("Expected document change mirrored, got %q", got)
+	}
+	if copies := conflictCopies(t, s); len(copies) != 0 {
+		t.Errorf("Expected no conflict copies, got %v", copies)
+	}
+}
+
+func TestSyncConflict(t *testing.T) {
+	s, ws := newTestSyncer(t)
+	p, _ := ws.Create(context.Background(), models.DefaultWorkspaceID, "Notes", "alice")
+	mustSync(t, s)
+
+	writeFile(t, s, "Notes.md", "local")
+	ws.editDocument(p, "remote")
+	mustSync(t, s)
+
+	if got := readFile(t, s, "Notes.md"); got != "remote" {
+		t.Errorf("Expected the document to win, got %q", got)
+	}
+	copies := conflictCopies(t, s)
+	if len(copies) != 1 {
+		t.Fatalf("Expected one conflict copy, got %v", copies)
+	}
+	if content, _ := os.ReadFile(copies[0]); string(content) != "local" {
+		t.Errorf("Expected conflict copy with the local edit, got %q", content)
+	}
+
+	// Conflict copies are never imported as projects.
+	mustSync(t, s)
+	if len(ws.projects) != 1 {
+		t.Errorf("Expected 1 project, got %d", len(ws.projects))
+	}
+}
+
+func TestSyncRenames(t *testing.T) {
+	s, ws := newTestSyncer(t)
+	p, _ := ws.Create(context.Background(), models.DefaultWorkspaceID, "Notes", "alice")
+	ws.editDocument(p, "content")
+	mustSync(t, s)
+
+	ws.Update(context.Background(), models.DefaultWorkspaceID, p.ID, "Journal")
+	mustSync(t, s)
+	if _, err := os.Stat(filepath.Join(s.dir, "Notes.md")); !os.IsNotExist(err) {
+		t.Error("Expected old file to be gone")
+	}
+	if got := readFile(t, s, "Journal.md"); got != "content" {
+		t.Errorf("Expected renamed file, got %q", got)
+	}
+
+	if err := os.Rename(filepath.Join(s.dir, "Journal.md"), filepath.Join(s.dir, "Diary.md")); err != nil {
+		t.Fatal(err)
+	}
+	mustSync(t, s)
+	if p.Name != "Diary" {
+		t.Errorf("Expected project renamed after its file, got %q", p.Name)
+	}
+	if len(ws.projects) != 1 {
+		t.Errorf("Expected no new project, got %d projects", len(ws.projects))
+	}
+}
+
+func TestSyncImportAndRemove(t *testing.T) {
+	s, ws := newTestSyncer(t)
+	writeFile(t, s, "Ideas.md", "# Ideas\n")
+	mustSync(t, s)
+
+	if len(ws.projects) != 1 || ws.projects[0].Name != "Ideas" {
+		t.Fatalf("Expected imported project, got %+v", ws.projects)
+	}
+	if doc := ws.docs[ws.projects[0].ID]; doc.ContentMD != "# Ideas\n" {
+		t.Errorf("Expected imported content, got %q", doc.ContentMD)
+	}
+
+	ws.projects = nil
+	mustSync(t, s)
+	if _, err := os.Stat(filepath.Join(s.dir, "Ideas.md")); !os.IsNotExist(err) {
+		t.Error("Expected the file of a deleted project to be removed")
+	}
+}
+
+func TestSyncStateSurvivesRestart(t *testing.T) {
+	s, ws := newTestSyncer(t)
+	p, _ := ws.Create(context.Background(), models.DefaultWorkspaceID, "Notes", "alice")
+	mustSync(t, s)
+
+	writeFile(t, s, "Notes.md", "edited while stopped")
+
+	restarted := New(s.dir, models.DefaultWorkspaceID)
+	restarted.projects = ws
+	restarted.documents = documentsFunc(ws.UpdateDocument)
+	if err := restarted.loadState(); err != nil {
+		t.Fatal(err)
+	}
+	mustSync(t, restarted)
+
+	if doc := ws.docs[p.ID]; doc.ContentMD != "edited while stopped" {
+		t.Errorf("Expected the edit to be saved, got %q", doc.ContentMD)
+	}
+	if copies := conflictCopies(t, s); len(copies) != 0 {
+		t.Errorf("Expected no conflict copies, got %v", copies)
+	}
+}
+
+func TestFileNames(t *testing.T) {
+	now := time.Now()
+	first := models.Project{ID: uuid.New(), Name: "Notes", CreatedAt: now}
+	second := models.Project{ID: uuid.New(), Name: "notes", CreatedAt: now.Add(time.Second)}
+	odd := models.Project{ID: uuid.New(), Name: "a/b: c?", CreatedAt: now}
+	empty := models.Project{ID: uuid.New(), Name: " .. ", CreatedAt: now}
+
+	names := FileNames([]models.Project{second, first, odd, empty})
+
+	if names[first.ID] != "Notes.md" {
+		t.Errorf("Expected Notes.md, got %q", names[first.ID])
+	}
+	if want := "notes (" + second.ID.String()[:8] + ").md"; names[second.ID] != want {
+		t.Errorf("Expected %q, got %q", want, names[second.ID])
+	}
+	if names[odd.ID] != "a-b- c-.md" {
+		t.Errorf("Expected a-b- c-.md, got %q", names[odd.ID])
+	}
+	if names[empty.ID] != "untitled.md" {
+		t.Errorf("Expected untitled.md, got %q", names[empty.ID])
+	}
+}
+
+func TestIsSyncedFile(t *testing.T) {
+	tests := map[string]bool{
+		"Notes.md":                           true,
+		"Notes.txt":                          false,
+		".hidden.md":                         false,
+		stateFile:                            false,
+		conflictName("Notes.md", time.Now()): false,
+		strings.Repeat("x", 10) + ".md":      true,
+	}
+
+	for name, want := range tests {
+		if got := isSyncedFile(name); got != want {
+			t.Errorf("isSyncedFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
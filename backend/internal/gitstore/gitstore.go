@@ -0,0 +1,267 @@
+// Package gitstore keeps every saved document version as a commit in a local
+// git repository, with one directory per project.
+package gitstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+const (
+	// documentFile is the name of the markdown file in a project directory.
+	documentFile = "document.md"
+
+	// branch is the branch commits are made on and pushed to.
+	branch = "main"
+
+	projectTrailer = "Project-Id"
+	versionTrailer = "Document-Version"
+
+	// emptyTree is the hash of git's empty tree, the base for diffs of a
+	// document's first commit.
+	emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+	// gitTimeout bounds a commit or a push.
+	gitTimeout = time.Minute
+)
+
+// Repo is a git working repository holding the documents. All git commands
+// run one at a time since they share the index, and no other process may
+// write to the repository.
+type Repo struct {
+	dir    string
+	remote string
+	mu     sync.Mutex
+}
+
+// Open opens the repository in dir, creating it if needed. If remote is not
+// empty, Push pushes to the bare repository at that path, which is also
+// created if needed.
+func Open(dir, remote string) (*Repo, error) {
+	r := &Repo{dir: dir, remote: remote}
+	ctx := context.Background()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := r.git(ctx, "init", "-q", "-b", branch); err != nil {
+			return nil, err
+		}
+	}
+	// A server that died during a commit may have left the index locked.
+	if err := r.removeIndexLock(); err != nil {
+		return nil, err
+	}
+
+	if remote != "" {
+		if _, err := os.Stat(remote); os.IsNotExist(err) {
+			if _, err := r.git(ctx, "init", "-q", "--bare", "-b", branch, remote); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// Commit writes the document of project to its file and commits it as
+// author, recording the project and document version as trailers. A save
+// that does not change the content still gets a commit, but a version that
+// is already committed is skipped, so that a commit can safely be retried.
+//
+// The commit is not cancelled with ctx, since git killed halfway leaves the
+// index locked, but it is given up after gitTimeout.
+func (r *Repo) Commit(ctx context.Context, project *models.Project, doc *models.Document, author string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gitTimeout)
+	defer cancel()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.commit(ctx, project, doc, author); err != nil {
+		if lockErr := r.removeIndexLock(); lockErr != nil {
+			return fmt.Errorf("%w (and %v)", err, lockErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repo) commit(ctx context.Context, project *models.Project, doc *models.Document, author string) error {
+	committed, err := r.lastVersion(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	if committed >= doc.Version {
+		return nil
+	}
+
+	path := documentPath(project.ID)
+	full := filepath.Join(r.dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(full, []byte(doc.ContentMD), 0o644); err != nil {
+		return err
+	}
+
+	if _, err := r.git(ctx, "add", "--", path); err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Update %s to version %d", project.Name, doc.Version)
+	trailers := fmt.Sprintf("%s: %s\n%s: %d", projectTrailer, project.ID, versionTrailer, doc.Version)
+	_, err = r.git(ctx, "commit", "-q", "--allow-empty",
+		"--author", author+" <>", "--date", doc.UpdatedAt.Format(time.RFC3339),
+		"-m", subject, "-m", trailers,
+	)
+	return err
+}
+
+// lastVersion returns the document version of the project's latest commit,
+// or zero if it has none.
+func (r *Repo) lastVersion(ctx context.Context, projectID uuid.UUID) (int, error) {
+	if _, err := r.git(ctx, "rev-parse", "-q", "--verify", "HEAD"); err != nil {
+		// Nothing has been committed yet.
+		return 0, nil
+	}
+
+	out, err := r.git(ctx, "log", "-n", "1", "--fixed-strings", "--grep", projectTrailer+": "+projectID.String(),
+		"--format=%(trailers:key="+versionTrailer+",valueonly,separator=)")
+	if err != nil {
+		return 0, err
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(out)
+}
+
+// Push pushes the commits to the remote given to Open, if any. Like Commit,
+// it is not cancelled with ctx but given up after gitTimeout.
+func (r *Repo) Push(ctx context.Context) error {
+	if r.remote == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gitTimeout)
+	defer cancel()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.git(ctx, "push", "-q", r.remote, "HEAD:refs/heads/"+branch); err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	return nil
+}
+
+// Log returns the commits of the project's document, newest first. A limit
+// of zero returns all of them.
+func (r *Repo) Log(ctx context.Context, projectID uuid.UUID, limit int) ([]models.DocumentCommit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	commits := []models.DocumentCommit{}
+	if _, err := r.git(ctx, "rev-parse", "-q", "--verify", "HEAD"); err != nil {
+		// Nothing has been committed yet.
+		return commits, nil
+	}
+
+	args := []string{
+		"log", "--fixed-strings", "--grep", projectTrailer + ": " + projectID.String(),
+		"--format=%H%x00%an%x00%aI%x00%s%x00%(trailers:key=" + versionTrailer + ",valueonly,separator=)%x1e",
+	}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+
+	out, err := r.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+		committedAt, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, err
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(fields[4]))
+		if err != nil {
+			return nil, fmt.Errorf("commit %s has no document version", fields[0])
+		}
+		commits = append(commits, models.DocumentCommit{
+			Hash:        fields[0],
+			Version:     version,
+			Author:      fields[1],
+			Message:     fields[3],
+			CommittedAt: committedAt,
+		})
+	}
+
+	return commits, nil
+}
+
+// Diff returns the unified diff of the project's document between two
+// commits. An empty from diffs against the empty document.
+func (r *Repo) Diff(ctx context.Context, projectID uuid.UUID, from, to string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if from == "" {
+		from = emptyTree
+	}
+
+	return r.git(ctx, "diff", "--no-color", from, to, "--", documentPath(projectID))
+}
+
+// removeIndexLock removes the index lock git leaves behind when it is killed.
+// It must be called with mu held, when no git command of ours is running.
+func (r *Repo) removeIndexLock() error {
+	err := os.Remove(filepath.Join(r.dir, ".git", "index.lock"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func documentPath(projectID uuid.UUID) string {
+	return projectID.String() + "/" + documentFile
+}
+
+func (r *Repo) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_COMMITTER_NAME=md-editor",
+		"GIT_COMMITTER_EMAIL=md-editor@localhost",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,138 @@
+package gitstore
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestCommitLogDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+
+	repo, err := Open(filepath.Join(dir, "work"), remote)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	project := &models.Project{ID: uuid.New(), Name: "Notes"}
+	other := &models.Project{ID: uuid.New(), Name: "Other"}
+
+	commits, err := repo.Log(ctx, project.ID, 0)
+	if err != nil || len(commits) != 0 {
+		t.Fatalf("Expected empty history, got %v, %v", commits, err)
+	}
+
+	saves := []struct {
+		project *models.Project
+		content string
+		version int
+	}{
+		{project, "# Notes\n", 2},
+		{other, "unrelated\n", 2},
+		{project, "# Notes\nmore\n", 3},
+		{project, "# Notes\nmore\n", 4},
+		// A retried commit of a version that is already committed.
+		{project, "# Notes\nmore\n", 3},
+	}
+	for _, s := range saves {
+		doc := &models.Document{ContentMD: s.content, Version: s.version, UpdatedAt: time.Now()}
+		if err := repo.Commit(ctx, s.project, doc, "alice"); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+	}
+
+	commits, err = repo.Log(ctx, project.ID, 0)
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(commits))
+	}
+	if commits[0].Version != 4 || commits[2].Version != 2 || commits[0].Author != "alice" {
+		t.Errorf("Unexpected commits %+v", commits)
+	}
+	if commits[2].Message != "Update Notes to version 2" {
+		t.Errorf("Unexpected message %q", commits[2].Message)
+	}
+
+	limited, err := repo.Log(ctx, project.ID, 1)
+	if err != nil || len(limited) != 1 {
+		t.Errorf("Expected 1 commit, got %v, %v", limited, err)
+	}
+
+	diff, err := repo.Diff(ctx, project.ID, commits[2].Hash, commits[1].Hash)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(diff, "+more") {
+		t.Errorf("Expected diff to add a line, got %q", diff)
+	}
+
+	diff, err = repo.Diff(ctx, project.ID, "", commits[2].Hash)
+	if err != nil || !strings.Contains(diff, "+# Notes") {
+		t.Errorf("Expected diff from the empty document, got %q, %v", diff, err)
+	}
+
+	if err := repo.Push(ctx); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	out, err := exec.Command("git", "-C", remote, "rev-parse", branch).Output()
+	if err != nil {
+		t.Fatalf("Expected pushed branch: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != commits[0].Hash {
+		t.Errorf("Expected remote at %s, got %s", commits[0].Hash, got)
+	}
+}
+
+func TestCommitOutlivesRequest(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	repo, err := Open(dir, "")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	// A lock left behind by a server that died during a commit.
+	lock := filepath.Join(dir, ".git", "index.lock")
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err = Open(dir, "")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Errorf("Expected Open to remove the stale index lock, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	project := &models.Project{ID: uuid.New(), Name: "Notes"}
+	doc := &models.Document{ContentMD: "# Notes\n", Version: 2, UpdatedAt: time.Now()}
+	if err := repo.Commit(ctx, project, doc, "alice"); err != nil {
+		t.Fatalf("Expected commit to ignore the cancelled request, got %v", err)
+	}
+
+	commits, err := repo.Log(context.Background(), project.ID, 0)
+	if err != nil || len(commits) != 1 {
+		t.Errorf("Expected 1 commit, got %v, %v", commits, err)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := models.AuditFilter{
+		WorkspaceID: middleware.WorkspaceID(c),
+		ActorName:   c.Query("actor"),
+		Action:      c.Query("action"),
+	}
+
+	if projectIDStr := c.Query("projectId"); projectIDStr != "" {
+		projectID, err := uuid.Parse(projectIDStr)
+		if err != nil {
+			respondInvalidParam(c, "projectId", "invalid project ID")
+			return
+		}
+		filter.ProjectID = &projectID
+	}
+
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			respondInvalidParam(c, "since", "expected an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = &since
+	}
+
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		before, err := strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil || before < 1 {
+			respondInvalidParam(c, "before", "expected a positive event ID")
+			return
+		}
+		filter.BeforeID = before
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	response, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, err, "Failed to list audit events")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func auditVersion(version int) *int {
+	return &version
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditHandlerListValidation(t *testing.T) {
+	handler := NewAuditHandler(nil)
+
+	router := gin.New()
+	router.GET("/audit", handler.List)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid project ID", query: "?projectId=invalid"},
+		{name: "invalid since", query: "?since=yesterday"},
+		{name: "invalid before", query: "?before=abc"},
+		{name: "negative before", query: "?before=-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/audit"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
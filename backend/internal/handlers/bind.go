@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+)
+
+func init() {
+	// Report fields by their JSON names, as clients know them.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// respondBindError answers a request whose JSON body could not be bound,
+// distinguishing bodies cut off by the size limit from malformed ones and
+// listing the fields that failed validation.
+func respondBindError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondError(c, middleware.ErrBodyTooLarge, "")
+		return
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apperror.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = fieldError(fe)
+		}
+		respondError(c, apperror.Validation(fields...), "")
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		respondError(c, apperror.Validation(apperror.FieldError{
+			Field:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: "must be a " + typeErr.Type.Kind().String(),
+		}), "")
+		return
+	}
+
+	respondError(c, apperror.New(http.StatusBadRequest, apperror.CodeMalformedBody, err.Error()), "")
+}
+
+// fieldError describes a failed validation rule. The field is the path below
+// the request struct, e.g. "headings[0]".
+func fieldError(fe validator.FieldError) apperror.FieldError {
+	field := fe.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	var message string
+	switch fe.Tag() {
+	case "required":
+		message = "is required"
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			message = fmt.Sprintf("must have %s %s characters", bound, fe.Param())
+		case reflect.Slice, reflect.Map, reflect.Array:
+			message = fmt.Sprintf("must have %s %s items", bound, fe.Param())
+		default:
+			message = fmt.Sprintf("must be %s %s", bound, fe.Param())
+		}
+	case "oneof":
+		message = "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "url":
+		message = "must be a URL"
+	default:
+		message = "failed the " + fe.Tag() + " rule"
+	}
+
+	return apperror.FieldError{Field: field, Code: fe.Tag(), Message: message}
+}
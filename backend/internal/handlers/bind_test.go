@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestRespondBindError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "malformed body", err: errors.New("invalid character"), wantStatus: http.StatusBadRequest, wantCode: apperror.CodeMalformedBody},
+		{name: "body too large", err: &http.MaxBytesError{Limit: 8}, wantStatus: http.StatusRequestEntityTooLarge, wantCode: apperror.CodeBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			respondBindError(c, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != apperror.ContentType {
+				t.Errorf("Expected Content-Type %s, got %s", apperror.ContentType, ct)
+			}
+			if problem := decodeProblem(t, w); problem.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, problem.Code)
+			}
+		})
+	}
+}
+
+func TestRespondBindErrorFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+		wantCode  string
+	}{
+		{name: "missing", body: `{}`, wantField: "projectIds", wantCode: "required"},
+		{name: "too few", body: `{"projectIds":["` + models.DefaultWorkspaceID.String() + `"]}`, wantField: "projectIds", wantCode: "min"},
+		{name: "bad enum", body: `{"projectIds":[],"mode":"zip"}`, wantField: "mode", wantCode: "oneof"},
+		{name: "wrong type", body: `{"projectIds":"all"}`, wantField: "projectIds", wantCode: "invalid_type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+
+			var req models.MergeProjectsRequest
+			respondBindError(c, c.ShouldBindJSON(&req))
+
+			problem := decodeProblem(t, w)
+			if problem.Code != apperror.CodeValidationFailed {
+				t.Fatalf("Expected validation_failed, got %s", problem.Code)
+			}
+			for _, f := range problem.Errors {
+				if f.Field == tt.wantField && f.Code == tt.wantCode {
+					return
+				}
+			}
+			t.Errorf("Expected %s error on %s, got %+v", tt.wantCode, tt.wantField, problem.Errors)
+		})
+	}
+}
+
+type problemBody struct {
+	Status int                   `json:"status"`
+	Code   string                `json:"code"`
+	Detail string                `json:"detail"`
+	Errors []apperror.FieldError `json:"errors"`
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) problemBody {
+	t.Helper()
+
+	var problem problemBody
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Expected problem details, got %q", w.Body.String())
+	}
+	return problem
+}
@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
 	"github.com/warriorguo/md-editor/backend/internal/models"
 	"github.com/warriorguo/md-editor/backend/internal/services"
 )
@@ -23,46 +23,45 @@ func (h *DocumentHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		respondInvalidParam(c, "id", "invalid document ID")
 		return
 	}
 
 	// Get version from header
 	versionStr := c.GetHeader("X-Document-Version")
 	if versionStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "X-Document-Version header is required"})
+		respondInvalidParam(c, "X-Document-Version", "is required")
 		return
 	}
 
 	version, err := strconv.Atoi(versionStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version number"})
+		respondInvalidParam(c, "X-Document-Version", "must be a version number")
 		return
 	}
 
 	var req models.UpdateDocumentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	doc, err := h.service.Update(c.Request.Context(), id, req.ContentMD, version)
+	doc, err := h.service.Update(c.Request.Context(), middleware.WorkspaceID(c), id, req.ContentMD, version, middleware.ActorName(c))
 	if err != nil {
-		if errors.Is(err, services.ErrDocumentNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
-			return
-		}
-		if errors.Is(err, services.ErrVersionConflict) {
-			c.JSON(http.StatusConflict, gin.H{
-				"error":   "Version conflict",
-				"message": "The document has been modified by another session",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document"})
+		respondError(c, err, "Failed to update document")
 		return
 	}
 
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:        models.AuditActionDocumentUpdate,
+		TargetType:    models.AuditTargetDocument,
+		TargetID:      &doc.ID,
+		ProjectID:     &doc.ProjectID,
+		BeforeVersion: auditVersion(version),
+		AfterVersion:  auditVersion(doc.Version),
+		Details:       map[string]interface{}{"bytes": len(doc.ContentMD)},
+	})
+
 	c.Header("X-Document-Version", strconv.Itoa(doc.Version))
 	c.JSON(http.StatusOK, models.DocumentResponse{
 		ID:        doc.ID,
@@ -72,3 +71,63 @@ func (h *DocumentHandler) Update(c *gin.Context) {
 		UpdatedAt: doc.UpdatedAt,
 	})
 }
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// History lists the commits of a project's document in git storage.
+func (h *DocumentHandler) History(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			respondInvalidParam(c, "limit", "expected 1 to 500")
+			return
+		}
+	}
+
+	commits, err := h.service.History(c.Request.Context(), middleware.WorkspaceID(c), id, limit)
+	if err != nil {
+		respondError(c, err, "Failed to load history")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DocumentHistoryResponse{Commits: commits})
+}
+
+// Diff returns the unified diff of a project's document between two
+// committed versions.
+func (h *DocumentHandler) Diff(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	var versions [2]int
+	for i, param := range []string{"from", "to"} {
+		if value := c.Query(param); value != "" {
+			versions[i], err = strconv.Atoi(value)
+			if err != nil || versions[i] < 1 {
+				respondInvalidParam(c, param, "must be a positive version number")
+				return
+			}
+		}
+	}
+
+	diff, err := h.service.Diff(c.Request.Context(), middleware.WorkspaceID(c), id, versions[0], versions[1])
+	if err != nil {
+		respondError(c, err, "Failed to compute diff")
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
@@ -114,3 +114,34 @@ func TestDocumentHandlerVersionHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestDocumentHandlerHistoryValidation(t *testing.T) {
+	handler := NewDocumentHandler(nil)
+
+	router := gin.New()
+	router.GET("/projects/:id/history", handler.History)
+	router.GET("/projects/:id/diff", handler.Diff)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"history invalid UUID", "/projects/invalid-uuid/history"},
+		{"history zero limit", "/projects/00000000-0000-0000-0000-000000000001/history?limit=0"},
+		{"history limit too large", "/projects/00000000-0000-0000-0000-000000000001/history?limit=501"},
+		{"diff invalid UUID", "/projects/invalid-uuid/diff"},
+		{"diff invalid from", "/projects/00000000-0000-0000-0000-000000000001/diff?from=x"},
+		{"diff zero to", "/projects/00000000-0000-0000-0000-000000000001/diff?to=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
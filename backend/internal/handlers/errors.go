@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+)
+
+// respondError answers with the problem details of err. Errors without a
+// code are reported as internal errors described by fallback.
+func respondError(c *gin.Context, err error, fallback string) {
+	middleware.AbortWithError(c, err, fallback)
+}
+
+// respondInvalidParam answers a request whose path, query or header parameter
+// field is invalid.
+func respondInvalidParam(c *gin.Context, field, message string) {
+	respondError(c, apperror.InvalidParam(field, message), "")
+}
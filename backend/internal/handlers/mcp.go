@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/mcp"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type MCPHandler struct {
+	server *mcp.Server
+}
+
+func NewMCPHandler(server *mcp.Server) *MCPHandler {
+	return &MCPHandler{server: server}
+}
+
+// Serve answers a Model Context Protocol message in the request's
+// workspace. This is the protocol's Streamable HTTP transport without
+// sessions or event streams: every request carries one message and gets
+// its response as JSON, or 202 for a notification.
+func (h *MCPHandler) Serve(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = middleware.ErrBodyTooLarge
+		}
+		respondError(c, err, "Failed to read request")
+		return
+	}
+
+	changed := false
+	caller := mcp.Caller{
+		WorkspaceID: middleware.WorkspaceID(c),
+		Author:      middleware.ActorName(c),
+		Record: func(event models.AuditEvent) {
+			changed = true
+			middleware.RecordAudit(c, event)
+		},
+	}
+	resp := h.server.Handle(c.Request.Context(), caller, body)
+	if !changed {
+		middleware.SkipAudit(c)
+	}
+
+	if resp == nil {
+		c.Status(http.StatusAccepted)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", resp)
+}
@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
 	"github.com/warriorguo/md-editor/backend/internal/models"
 	"github.com/warriorguo/md-editor/backend/internal/services"
 )
@@ -22,16 +28,36 @@ func NewProjectHandler(service *services.ProjectService) *ProjectHandler {
 func (h *ProjectHandler) Create(c *gin.Context) {
 	var req models.CreateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	project, err := h.service.Create(c.Request.Context(), req.Name)
+	ctx, workspaceID := c.Request.Context(), middleware.WorkspaceID(c)
+
+	var project *models.Project
+	var err error
+	if req.TemplateID != nil {
+		project, err = h.service.CreateFromTemplate(ctx, workspaceID, req.Name, middleware.ActorName(c), *req.TemplateID, req.Variables)
+	} else {
+		project, err = h.service.Create(ctx, workspaceID, req.Name, middleware.ActorName(c))
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
+		respondError(c, err, "Failed to create project")
 		return
 	}
 
+	details := map[string]interface{}{"name": project.Name}
+	if req.TemplateID != nil {
+		details["templateId"] = *req.TemplateID
+	}
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:       models.AuditActionProjectCreate,
+		TargetType:   models.AuditTargetProject,
+		TargetID:     &project.ID,
+		ProjectID:    &project.ID,
+		AfterVersion: auditVersion(1),
+		Details:      details,
+	})
 	c.JSON(http.StatusCreated, project)
 }
 
@@ -39,30 +65,171 @@ func (h *ProjectHandler) List(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
 
-	response, err := h.service.List(c.Request.Context(), page, pageSize)
+	query := models.ProjectListQuery{
+		WorkspaceID: middleware.WorkspaceID(c),
+		Search:      strings.TrimSpace(c.Query("q")),
+		Page:        page,
+		PageSize:    pageSize,
+	}
+
+	sort, order := c.Query("sort"), c.Query("order")
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err := services.DecodeProjectCursor(cursorStr)
+		if err != nil {
+			respondError(c, err, "")
+			return
+		}
+		// A cursor carries its own ordering; an explicit one must agree.
+		if (sort != "" && sort != cursor.Sort) || (order != "" && (order == "desc") != cursor.Descending) {
+			respondInvalidParam(c, "cursor", "does not match the requested sort order")
+			return
+		}
+		query.After = cursor
+		query.Sort = cursor.Sort
+		query.Descending = cursor.Descending
+	} else {
+		switch sort {
+		case "":
+			query.Sort = models.ProjectSortCreatedAt
+		case models.ProjectSortCreatedAt, models.ProjectSortUpdatedAt, models.ProjectSortName:
+			query.Sort = sort
+		default:
+			respondInvalidParam(c, "sort", "expected createdAt, updatedAt or name")
+			return
+		}
+
+		// Dates sort newest first and names alphabetically unless asked otherwise.
+		switch order {
+		case "":
+			query.Descending = query.Sort != models.ProjectSortName
+		case "asc", "desc":
+			query.Descending = order == "desc"
+		default:
+			respondInvalidParam(c, "order", "expected asc or desc")
+			return
+		}
+	}
+
+	if sinceStr := c.Query("updatedSince"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			respondInvalidParam(c, "updatedSince", "expected an RFC 3339 timestamp")
+			return
+		}
+		query.UpdatedSince = &since
+	}
+
+	switch c.DefaultQuery("archived", "false") {
+	case "false":
+		query.Archived = models.ProjectArchivedExclude
+	case "true":
+		query.Archived = models.ProjectArchivedOnly
+	case "all":
+		query.Archived = models.ProjectArchivedInclude
+	default:
+		respondInvalidParam(c, "archived", "expected true, false or all")
+		return
+	}
+	query.PinnedOnly = c.Query("pinned") == "true"
+
+	if user := middleware.CurrentUser(c); user != nil {
+		query.UserID = &user.ID
+	}
+	if c.Query("favourites") == "true" {
+		if query.UserID == nil {
+			respondError(c, services.ErrUnauthenticated, "")
+			return
+		}
+		query.FavouritesOnly = true
+	}
+
+	if include := c.Query("include"); include != "" {
+		for _, field := range strings.Split(include, ",") {
+			switch strings.TrimSpace(field) {
+			case "preview":
+				query.IncludePreview = true
+			case "stats":
+				query.IncludeStats = true
+			default:
+				respondInvalidParam(c, "include", "expected preview or stats")
+				return
+			}
+		}
+	}
+
+	response, err := h.service.List(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list projects"})
+		respondError(c, err, "Failed to list projects")
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *ProjectHandler) Lookup(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" || utf8.RuneCountInString(name) > models.MaxProjectNameLength {
+		respondInvalidParam(c, "name", "must be between 1 and 255 characters")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	response, err := h.service.Lookup(c.Request.Context(), middleware.WorkspaceID(c), name, limit)
+	if err != nil {
+		respondError(c, err, "Failed to look up projects")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOrCreate returns the project with the name in the path, creating it if
+// it does not exist yet. It replies 201 when the project was created.
+func (h *ProjectHandler) GetOrCreate(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" || utf8.RuneCountInString(name) > models.MaxProjectNameLength {
+		respondInvalidParam(c, "name", "must be between 1 and 255 characters")
+		return
+	}
+
+	project, created, err := h.service.GetOrCreate(c.Request.Context(), middleware.WorkspaceID(c), name, middleware.ActorName(c))
+	if err != nil {
+		respondError(c, err, "Failed to get or create project")
+		return
+	}
+
+	if !created {
+		middleware.SkipAudit(c)
+		c.JSON(http.StatusOK, project)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:       models.AuditActionProjectCreate,
+		TargetType:   models.AuditTargetProject,
+		TargetID:     &project.ID,
+		ProjectID:    &project.ID,
+		AfterVersion: auditVersion(1),
+		Details:      map[string]interface{}{"name": project.Name},
+	})
+	c.JSON(http.StatusCreated, project)
+}
+
 func (h *ProjectHandler) Get(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		respondInvalidParam(c, "id", "invalid project ID")
 		return
 	}
 
-	project, err := h.service.GetByID(c.Request.Context(), id)
+	project, err := h.service.GetByID(c.Request.Context(), middleware.WorkspaceID(c), id)
 	if err != nil {
-		if errors.Is(err, services.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		if respondMoved(c, err, "") {
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get project"})
+		respondError(c, err, "Failed to get project")
 		return
 	}
 
@@ -73,26 +240,29 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		respondInvalidParam(c, "id", "invalid project ID")
 		return
 	}
 
 	var req models.UpdateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	project, err := h.service.Update(c.Request.Context(), id, req.Name)
+	project, err := h.service.Update(c.Request.Context(), middleware.WorkspaceID(c), id, req.Name)
 	if err != nil {
-		if errors.Is(err, services.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
+		respondError(c, err, "Failed to update project")
 		return
 	}
 
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionProjectRename,
+		TargetType: models.AuditTargetProject,
+		TargetID:   &project.ID,
+		ProjectID:  &project.ID,
+		Details:    map[string]interface{}{"name": project.Name},
+	})
 	c.JSON(http.StatusOK, project)
 }
 
@@ -100,20 +270,22 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		respondInvalidParam(c, "id", "invalid project ID")
 		return
 	}
 
-	err = h.service.Delete(c.Request.Context(), id)
+	err = h.service.Delete(c.Request.Context(), middleware.WorkspaceID(c), id)
 	if err != nil {
-		if errors.Is(err, services.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+		respondError(c, err, "Failed to delete project")
 		return
 	}
 
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionProjectDelete,
+		TargetType: models.AuditTargetProject,
+		TargetID:   &id,
+		ProjectID:  &id,
+	})
 	c.JSON(http.StatusNoContent, nil)
 }
 
@@ -121,17 +293,16 @@ func (h *ProjectHandler) GetDocument(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		respondInvalidParam(c, "id", "invalid project ID")
 		return
 	}
 
-	doc, err := h.service.GetDocument(c.Request.Context(), id)
+	doc, err := h.service.GetDocument(c.Request.Context(), middleware.WorkspaceID(c), id)
 	if err != nil {
-		if errors.Is(err, services.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		if respondMoved(c, err, "/document") {
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get document"})
+		respondError(c, err, "Failed to get document")
 		return
 	}
 
@@ -144,3 +315,190 @@ func (h *ProjectHandler) GetDocument(c *gin.Context) {
 		UpdatedAt: doc.UpdatedAt,
 	})
 }
+
+func (h *ProjectHandler) Duplicate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	var req models.DuplicateProjectRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+
+	project, err := h.service.Duplicate(c.Request.Context(), middleware.WorkspaceID(c), id, strings.TrimSpace(req.Name), middleware.ActorName(c))
+	if err != nil {
+		respondError(c, err, "Failed to duplicate project")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:       models.AuditActionProjectDuplicate,
+		TargetType:   models.AuditTargetProject,
+		TargetID:     &project.ID,
+		ProjectID:    &project.ID,
+		AfterVersion: auditVersion(1),
+		Details:      map[string]interface{}{"name": project.Name, "sourceId": id},
+	})
+	c.JSON(http.StatusCreated, project)
+}
+
+func (h *ProjectHandler) Merge(c *gin.Context) {
+	var req models.MergeProjectsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	result, err := h.service.Merge(c.Request.Context(), middleware.WorkspaceID(c), req, middleware.ActorName(c))
+	if err != nil {
+		respondError(c, err, "Failed to merge projects")
+		return
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = models.MergeModeConcatenate
+	}
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:        models.AuditActionProjectMerge,
+		TargetType:    models.AuditTargetProject,
+		TargetID:      &result.Project.ID,
+		ProjectID:     &result.Project.ID,
+		BeforeVersion: auditVersion(result.Document.Version - 1),
+		AfterVersion:  auditVersion(result.Document.Version),
+		Details:       map[string]interface{}{"mergedIds": req.ProjectIDs[1:], "mode": mode},
+	})
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ProjectHandler) Split(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	var req models.SplitProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	result, err := h.service.Split(c.Request.Context(), middleware.WorkspaceID(c), id, req, middleware.ActorName(c))
+	if err != nil {
+		respondError(c, err, "Failed to split project")
+		return
+	}
+
+	createdIDs := make([]uuid.UUID, len(result.Created))
+	for i, p := range result.Created {
+		createdIDs[i] = p.ID
+	}
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:        models.AuditActionProjectSplit,
+		TargetType:    models.AuditTargetProject,
+		TargetID:      &id,
+		ProjectID:     &id,
+		BeforeVersion: auditVersion(result.Document.Version - 1),
+		AfterVersion:  auditVersion(result.Document.Version),
+		Details:       map[string]interface{}{"createdIds": createdIDs},
+	})
+	c.JSON(http.StatusCreated, result)
+}
+
+// respondMoved answers requests for a merged project with a permanent
+// redirect to the project it was merged into.
+func respondMoved(c *gin.Context, err error, suffix string) bool {
+	var moved *services.ProjectMovedError
+	if !errors.As(err, &moved) {
+		return false
+	}
+
+	problem := apperror.ProblemFor(apperror.New(http.StatusMovedPermanently, "project_moved", "project was merged into another project"), "")
+	problem.Extensions = map[string]interface{}{"redirectTo": moved.To}
+
+	c.Header("Location", "/api/projects/"+moved.To.String()+suffix)
+	middleware.AbortWithProblem(c, problem)
+	return true
+}
+
+func (h *ProjectHandler) Archive(c *gin.Context) {
+	h.setState(c, models.AuditActionProjectArchive, h.service.Archive, true)
+}
+
+func (h *ProjectHandler) Unarchive(c *gin.Context) {
+	h.setState(c, models.AuditActionProjectUnarchive, h.service.Archive, false)
+}
+
+func (h *ProjectHandler) Pin(c *gin.Context) {
+	h.setState(c, models.AuditActionProjectPin, h.service.Pin, true)
+}
+
+func (h *ProjectHandler) Unpin(c *gin.Context) {
+	h.setState(c, models.AuditActionProjectUnpin, h.service.Pin, false)
+}
+
+// setState switches a project state on or off with set and records action.
+func (h *ProjectHandler) setState(c *gin.Context, action string, set func(ctx context.Context, workspaceID, id uuid.UUID, on bool) (*models.Project, error), on bool) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	project, err := set(c.Request.Context(), middleware.WorkspaceID(c), id, on)
+	if err != nil {
+		respondError(c, err, "Failed to update project")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetProject,
+		TargetID:   &project.ID,
+		ProjectID:  &project.ID,
+	})
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *ProjectHandler) Favourite(c *gin.Context) {
+	h.setFavourite(c, true)
+}
+
+func (h *ProjectHandler) Unfavourite(c *gin.Context) {
+	h.setFavourite(c, false)
+}
+
+// setFavourite adds the project to or removes it from the favourites of the
+// authenticated user.
+func (h *ProjectHandler) setFavourite(c *gin.Context, favourite bool) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		respondError(c, services.ErrUnauthenticated, "")
+		return
+	}
+
+	err = h.service.SetFavourite(c.Request.Context(), middleware.WorkspaceID(c), user.ID, id, favourite)
+	if err != nil {
+		respondError(c, err, "Failed to update favourites")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -2,12 +2,21 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository/sqlite"
+	"github.com/warriorguo/md-editor/backend/internal/services"
 )
 
 func init() {
@@ -40,6 +49,11 @@ func TestProjectHandlerCreateValidation(t *testing.T) {
 			body:       "invalid",
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "invalid template ID",
+			body:       map[string]string{"name": "Notes", "templateId": "invalid"},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +136,113 @@ func TestProjectHandlerListPagination(t *testing.T) {
 	}
 }
 
+func TestProjectHandlerListValidation(t *testing.T) {
+	handler := NewProjectHandler(nil)
+
+	router := gin.New()
+	router.GET("/projects", handler.List)
+
+	nameCursor := services.EncodeProjectCursor(models.ProjectSortName, false, models.Project{ID: uuid.New(), Name: "a"})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "unknown sort", query: "?sort=size"},
+		{name: "unknown order", query: "?order=up"},
+		{name: "invalid updatedSince", query: "?updatedSince=yesterday"},
+		{name: "invalid cursor", query: "?cursor=garbage"},
+		{name: "unknown include", query: "?include=preview,content"},
+		{name: "cursor with other sort", query: "?sort=createdAt&cursor=" + nameCursor},
+		{name: "cursor with other order", query: "?order=desc&cursor=" + nameCursor},
+		{name: "unknown archived", query: "?archived=maybe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/projects"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestProjectHandlerLookupAndGetOrCreateValidation(t *testing.T) {
+	handler := NewProjectHandler(nil)
+
+	router := gin.New()
+	router.GET("/projects/lookup", handler.Lookup)
+	router.GET("/projects/:id", handler.Get)
+	router.PUT("/projects/by-name/:name", handler.GetOrCreate)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "lookup without name", method: http.MethodGet, path: "/projects/lookup"},
+		{name: "lookup blank name", method: http.MethodGet, path: "/projects/lookup?name=%20%20"},
+		{name: "lookup long name", method: http.MethodGet, path: "/projects/lookup?name=" + strings.Repeat("a", 256)},
+		{name: "get or create blank name", method: http.MethodPut, path: "/projects/by-name/%20"},
+		{name: "get or create long name", method: http.MethodPut, path: "/projects/by-name/" + strings.Repeat("a", 256)},
+		{name: "get or create long multibyte name", method: http.MethodPut, path: "/projects/by-name/" + strings.Repeat("é", 256)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestProjectHandlerGetOrCreateAudit(t *testing.T) {
+	dbURL := database.SQLiteScheme + filepath.Join(t.TempDir(), "test.db")
+	if err := database.MigrateUp(dbURL); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db, err := database.NewSQLite(dbURL)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	stores := sqlite.NewStores(db)
+	projects := services.NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, services.PageSizes{})
+	audit := services.NewAuditService(stores.Audit)
+
+	router := gin.New()
+	router.PUT("/projects/by-name/:name", middleware.Audit(audit), NewProjectHandler(projects).GetOrCreate)
+
+	for _, wantStatus := range []int{http.StatusCreated, http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPut, "/projects/by-name/Notes", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != wantStatus {
+			t.Fatalf("Expected status %d, got %d", wantStatus, w.Code)
+		}
+	}
+
+	list, err := audit.List(context.Background(), models.AuditFilter{WorkspaceID: models.DefaultWorkspaceID})
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	if len(list.Events) != 1 || list.Events[0].Action != models.AuditActionProjectCreate {
+		t.Errorf("Expected only the project creation to be audited, got %+v", list.Events)
+	}
+}
+
 func TestProjectHandlerUpdateInvalidID(t *testing.T) {
 	handler := NewProjectHandler(nil)
 
@@ -154,3 +275,80 @@ func TestProjectHandlerDeleteInvalidID(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
 	}
 }
+
+func TestProjectHandlerDuplicateMergeSplitValidation(t *testing.T) {
+	handler := NewProjectHandler(nil)
+
+	router := gin.New()
+	router.POST("/projects/merge", handler.Merge)
+	router.POST("/projects/:id/duplicate", handler.Duplicate)
+	router.POST("/projects/:id/split", handler.Split)
+
+	validID := "00000000-0000-0000-0000-000000000001"
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "duplicate invalid project", path: "/projects/invalid/duplicate", body: `{}`},
+		{name: "duplicate long name", path: "/projects/" + validID + "/duplicate", body: `{"name":"` + strings.Repeat("a", 256) + `"}`},
+		{name: "merge single project", path: "/projects/merge", body: `{"projectIds":["` + validID + `"]}`},
+		{name: "merge unknown mode", path: "/projects/merge", body: `{"projectIds":["` + validID + `","00000000-0000-0000-0000-000000000002"],"mode":"zip"}`},
+		{name: "split invalid project", path: "/projects/invalid/split", body: `{"headings":["A"]}`},
+		{name: "split without headings", path: "/projects/" + validID + "/split", body: `{"headings":[]}`},
+		{name: "split empty heading", path: "/projects/" + validID + "/split", body: `{"headings":[""]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestProjectHandlerStateValidation(t *testing.T) {
+	handler := NewProjectHandler(nil)
+
+	router := gin.New()
+	router.GET("/projects", handler.List)
+	router.PUT("/projects/:id/archive", handler.Archive)
+	router.DELETE("/projects/:id/pin", handler.Unpin)
+	router.PUT("/projects/:id/favourite", handler.Favourite)
+	router.DELETE("/projects/:id/favourite", handler.Unfavourite)
+
+	validID := "00000000-0000-0000-0000-000000000001"
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "archive invalid project", method: http.MethodPut, path: "/projects/invalid/archive", wantStatus: http.StatusBadRequest},
+		{name: "unpin invalid project", method: http.MethodDelete, path: "/projects/invalid/pin", wantStatus: http.StatusBadRequest},
+		{name: "favourite invalid project", method: http.MethodPut, path: "/projects/invalid/favourite", wantStatus: http.StatusBadRequest},
+		{name: "favourite anonymously", method: http.MethodPut, path: "/projects/" + validID + "/favourite", wantStatus: http.StatusUnauthorized},
+		{name: "unfavourite anonymously", method: http.MethodDelete, path: "/projects/" + validID + "/favourite", wantStatus: http.StatusUnauthorized},
+		{name: "list favourites anonymously", method: http.MethodGet, path: "/projects?favourites=true", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
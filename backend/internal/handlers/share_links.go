@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+	"github.com/yuin/goldmark"
+)
+
+type ShareLinkHandler struct {
+	service *services.ShareLinkService
+}
+
+func NewShareLinkHandler(service *services.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{service: service}
+}
+
+func (h *ShareLinkHandler) Create(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	var req models.CreateShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	link, err := h.service.Create(c.Request.Context(), middleware.WorkspaceID(c), id, req)
+	if err != nil {
+		if errors.Is(err, services.ErrRevisionNotFound) {
+			err = apperror.Validation(apperror.FieldError{Field: "pinnedVersion", Code: "not_found", Message: "version does not exist"})
+		}
+		respondError(c, err, "Failed to create share link")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionShareLinkCreate,
+		TargetType: models.AuditTargetShareLink,
+		TargetID:   &link.ID,
+		ProjectID:  &id,
+		Details: map[string]interface{}{
+			"hasPassword":   link.HasPassword,
+			"pinnedVersion": link.PinnedVersion,
+			"expiresAt":     link.ExpiresAt,
+		},
+	})
+	c.JSON(http.StatusCreated, link)
+}
+
+func (h *ShareLinkHandler) List(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	response, err := h.service.List(c.Request.Context(), middleware.WorkspaceID(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to list share links")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ShareLinkHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	linkID, err := uuid.Parse(c.Param("linkId"))
+	if err != nil {
+		respondInvalidParam(c, "linkId", "invalid share link ID")
+		return
+	}
+
+	err = h.service.Revoke(c.Request.Context(), middleware.WorkspaceID(c), id, linkID)
+	if err != nil {
+		respondError(c, err, "Failed to revoke share link")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionShareLinkRevoke,
+		TargetType: models.AuditTargetShareLink,
+		TargetID:   &linkID,
+		ProjectID:  &id,
+	})
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// View serves a shared document without authentication. The document is
+// rendered as HTML unless ?raw=1 is given, in which case the markdown source
+// is returned. Password protected links accept the password through the
+// X-Share-Password header, or as the password field of a form posted by the
+// password page. It is never read from the query string, which would leave
+// it in access logs and browser history.
+func (h *ShareLinkHandler) View(c *gin.Context) {
+	token := c.Param("token")
+	raw := c.Query("raw") == "1"
+
+	password := c.GetHeader("X-Share-Password")
+	if password == "" && c.Request.Method == http.MethodPost {
+		password = c.PostForm("password")
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+
+	shared, err := h.service.Resolve(c.Request.Context(), token, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShareLinkNotFound):
+			c.String(http.StatusNotFound, "Share link not found or expired")
+		case errors.Is(err, services.ErrSharePasswordRequired), errors.Is(err, services.ErrSharePasswordIncorrect):
+			if raw {
+				c.String(http.StatusUnauthorized, "Password required")
+				return
+			}
+			renderSharePage(c, http.StatusUnauthorized, sharePageData{
+				Title:         "Password required",
+				PasswordForm:  true,
+				WrongPassword: errors.Is(err, services.ErrSharePasswordIncorrect),
+			})
+		default:
+			slog.ErrorContext(c.Request.Context(), "Request failed", "error", err)
+			c.String(http.StatusInternalServerError, "Failed to load shared document")
+		}
+		return
+	}
+
+	c.Header("X-Document-Version", strconv.Itoa(shared.Version))
+
+	if raw {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(shared.ContentMD))
+		return
+	}
+
+	var body bytes.Buffer
+	if err := goldmark.Convert([]byte(shared.ContentMD), &body); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to render shared document", "error", err)
+		c.String(http.StatusInternalServerError, "Failed to render shared document")
+		return
+	}
+
+	renderSharePage(c, http.StatusOK, sharePageData{
+		Title: shared.ProjectName,
+		Body:  template.HTML(body.String()),
+	})
+}
+
+type sharePageData struct {
+	Title         string
+	Body          template.HTML
+	PasswordForm  bool
+	WrongPassword bool
+}
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 48rem; margin: 2rem auto; padding: 0 1rem; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; line-height: 1.6; color: #1f2937; }
+pre { background: #f3f4f6; padding: 1rem; overflow-x: auto; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; }
+blockquote { border-left: 4px solid #d1d5db; margin: 0; padding-left: 1rem; color: #4b5563; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d1d5db; padding: 0.25rem 0.5rem; }
+</style>
+</head>
+<body>
+{{if .PasswordForm}}
+<h1>{{.Title}}</h1>
+{{if .WrongPassword}}<p>The password is incorrect.</p>{{end}}
+<form method="post">
+<input type="password" name="password" autofocus>
+<button type="submit">Open</button>
+</form>
+{{else}}
+<article>{{.Body}}</article>
+{{end}}
+</body>
+</html>
+`))
+
+func renderSharePage(c *gin.Context, status int, data sharePageData) {
+	var page bytes.Buffer
+	if err := sharePageTemplate.Execute(&page, data); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to render shared document", "error", err)
+		c.String(http.StatusInternalServerError, "Failed to render shared document")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository/sqlite"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+func TestShareLinkHandlerCreateValidation(t *testing.T) {
+	handler := NewShareLinkHandler(nil)
+
+	router := gin.New()
+	router.POST("/projects/:id/share-links", handler.Create)
+
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "invalid UUID",
+			id:         "invalid-uuid",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid pinned version",
+			id:         "00000000-0000-0000-0000-000000000001",
+			body:       `{"pinnedVersion": 0}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid expiry",
+			id:         "00000000-0000-0000-0000-000000000001",
+			body:       `{"expiresAt": "tomorrow"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+tt.id+"/share-links", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestShareLinkHandlerRevokeInvalidID(t *testing.T) {
+	handler := NewShareLinkHandler(nil)
+
+	router := gin.New()
+	router.DELETE("/projects/:id/share-links/:linkId", handler.Revoke)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "invalid project ID", path: "/projects/invalid-uuid/share-links/00000000-0000-0000-0000-000000000001"},
+		{name: "invalid link ID", path: "/projects/00000000-0000-0000-0000-000000000001/share-links/invalid-uuid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestShareLinkHandlerViewPassword(t *testing.T) {
+	dbURL := database.SQLiteScheme + filepath.Join(t.TempDir(), "test.db")
+	if err := database.MigrateUp(dbURL); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db, err := database.NewSQLite(dbURL)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	ctx := context.Background()
+	stores := sqlite.NewStores(db)
+	projects := services.NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, services.PageSizes{})
+	shareLinks := services.NewShareLinkService(stores.ShareLinks, stores.Projects, stores.Documents)
+
+	project, err := projects.Create(ctx, models.DefaultWorkspaceID, "Shared", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	link, err := shareLinks.Create(ctx, models.DefaultWorkspaceID, project.ID, models.CreateShareLinkRequest{Password: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to create share link: %v", err)
+	}
+
+	handler := NewShareLinkHandler(shareLinks)
+	router := gin.New()
+	router.GET("/s/:token", handler.View)
+	router.POST("/s/:token", handler.View)
+
+	form := url.Values{"password": {"secret"}}.Encode()
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		header     string
+		form       string
+		wantStatus int
+	}{
+		{name: "no password", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "password in query", method: http.MethodGet, query: "?password=secret", wantStatus: http.StatusUnauthorized},
+		{name: "password in header", method: http.MethodGet, header: "secret", wantStatus: http.StatusOK},
+		{name: "wrong password in header", method: http.MethodGet, header: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "password posted from form", method: http.MethodPost, form: form, wantStatus: http.StatusOK},
+		{name: "password in form of GET", method: http.MethodGet, query: "?" + form, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/s/"+link.Token+tt.query, strings.NewReader(tt.form))
+			if tt.header != "" {
+				req.Header.Set("X-Share-Password", tt.header)
+			}
+			if tt.form != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/s/"+link.Token, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `<form method="post">`) {
+		t.Errorf("Expected the password page to post its form, got %s", w.Body.String())
+	}
+}
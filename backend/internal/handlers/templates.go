@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+type TemplateHandler struct {
+	service *services.TemplateService
+}
+
+func NewTemplateHandler(service *services.TemplateService) *TemplateHandler {
+	return &TemplateHandler{service: service}
+}
+
+func (h *TemplateHandler) Create(c *gin.Context) {
+	var req models.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	template, err := h.service.Create(c.Request.Context(), middleware.WorkspaceID(c), req)
+	if err != nil {
+		respondError(c, err, "Failed to create template")
+		return
+	}
+
+	h.recordCreate(c, template)
+	c.JSON(http.StatusCreated, template)
+}
+
+// SaveProject creates a template from a project's current document.
+func (h *TemplateHandler) SaveProject(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	var req models.SaveAsTemplateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+
+	template, err := h.service.SaveProject(c.Request.Context(), middleware.WorkspaceID(c), projectID, req)
+	if err != nil {
+		respondError(c, err, "Failed to save template")
+		return
+	}
+
+	h.recordCreate(c, template)
+	c.JSON(http.StatusCreated, template)
+}
+
+func (h *TemplateHandler) recordCreate(c *gin.Context, template *models.Template) {
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionTemplateCreate,
+		TargetType: models.AuditTargetTemplate,
+		TargetID:   &template.ID,
+		ProjectID:  template.SourceProjectID,
+		Details:    map[string]interface{}{"name": template.Name},
+	})
+}
+
+func (h *TemplateHandler) List(c *gin.Context) {
+	response, err := h.service.List(c.Request.Context(), middleware.WorkspaceID(c))
+	if err != nil {
+		respondError(c, err, "Failed to list templates")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TemplateHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid template ID")
+		return
+	}
+
+	template, err := h.service.GetByID(c.Request.Context(), middleware.WorkspaceID(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to get template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *TemplateHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid template ID")
+		return
+	}
+
+	var req models.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	template, err := h.service.Update(c.Request.Context(), middleware.WorkspaceID(c), id, req)
+	if err != nil {
+		respondError(c, err, "Failed to update template")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionTemplateUpdate,
+		TargetType: models.AuditTargetTemplate,
+		TargetID:   &template.ID,
+		Details:    map[string]interface{}{"name": template.Name},
+	})
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *TemplateHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid template ID")
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), middleware.WorkspaceID(c), id); err != nil {
+		respondError(c, err, "Failed to delete template")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionTemplateDelete,
+		TargetType: models.AuditTargetTemplate,
+		TargetID:   &id,
+	})
+	c.JSON(http.StatusNoContent, nil)
+}
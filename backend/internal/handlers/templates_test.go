@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTemplateHandlerValidation(t *testing.T) {
+	handler := NewTemplateHandler(nil)
+
+	router := gin.New()
+	router.POST("/templates", handler.Create)
+	router.GET("/templates/:id", handler.Get)
+	router.PATCH("/templates/:id", handler.Update)
+	router.DELETE("/templates/:id", handler.Delete)
+	router.POST("/projects/:id/template", handler.SaveProject)
+
+	validID := "00000000-0000-0000-0000-000000000001"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "create without name", method: http.MethodPost, path: "/templates", body: `{"contentMd":"# {{title}}"}`},
+		{name: "create long description", method: http.MethodPost, path: "/templates", body: `{"name":"ADR","description":"` + strings.Repeat("d", 1001) + `"}`},
+		{name: "get invalid ID", method: http.MethodGet, path: "/templates/invalid"},
+		{name: "update invalid ID", method: http.MethodPatch, path: "/templates/invalid", body: `{"name":"ADR"}`},
+		{name: "update empty name", method: http.MethodPatch, path: "/templates/" + validID, body: `{"name":""}`},
+		{name: "delete invalid ID", method: http.MethodDelete, path: "/templates/invalid"},
+		{name: "save invalid project", method: http.MethodPost, path: "/projects/invalid/template"},
+		{name: "save long name", method: http.MethodPost, path: "/projects/" + validID + "/template", body: `{"name":"` + strings.Repeat("n", 256) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
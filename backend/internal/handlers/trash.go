@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+type TrashHandler struct {
+	service *services.TrashService
+}
+
+func NewTrashHandler(service *services.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+func (h *TrashHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	response, err := h.service.List(c.Request.Context(), middleware.WorkspaceID(c), page, pageSize)
+	if err != nil {
+		respondError(c, err, "Failed to list trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TrashHandler) Restore(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	project, err := h.service.Restore(c.Request.Context(), middleware.WorkspaceID(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to restore project")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionProjectRestore,
+		TargetType: models.AuditTargetProject,
+		TargetID:   &project.ID,
+		ProjectID:  &project.ID,
+	})
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *TrashHandler) Purge(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	err = h.service.Purge(c.Request.Context(), middleware.WorkspaceID(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to purge project")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionProjectPurge,
+		TargetType: models.AuditTargetProject,
+		TargetID:   &id,
+		ProjectID:  &id,
+	})
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTrashHandlerInvalidProjectID(t *testing.T) {
+	handler := NewTrashHandler(nil)
+
+	router := gin.New()
+	router.POST("/projects/:id/restore", handler.Restore)
+	router.DELETE("/trash/:id", handler.Purge)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "restore", method: http.MethodPost, path: "/projects/invalid/restore"},
+		{name: "purge", method: http.MethodDelete, path: "/trash/invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	webhook, err := h.service.Create(c.Request.Context(), middleware.WorkspaceID(c), req)
+	if err != nil {
+		respondError(c, err, "Failed to create webhook")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionWebhookCreate,
+		TargetType: models.AuditTargetWebhook,
+		TargetID:   &webhook.ID,
+		Details:    map[string]interface{}{"url": webhook.URL, "events": webhook.Events},
+	})
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	response, err := h.service.List(c.Request.Context(), middleware.WorkspaceID(c))
+	if err != nil {
+		respondError(c, err, "Failed to list webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid webhook ID")
+		return
+	}
+
+	webhook, err := h.service.GetByID(c.Request.Context(), middleware.WorkspaceID(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to get webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid webhook ID")
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	webhook, err := h.service.Update(c.Request.Context(), middleware.WorkspaceID(c), id, req)
+	if err != nil {
+		respondError(c, err, "Failed to update webhook")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionWebhookUpdate,
+		TargetType: models.AuditTargetWebhook,
+		TargetID:   &webhook.ID,
+		Details:    map[string]interface{}{"url": webhook.URL, "events": webhook.Events, "active": webhook.Active},
+	})
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid webhook ID")
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), middleware.WorkspaceID(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionWebhookDelete,
+		TargetType: models.AuditTargetWebhook,
+		TargetID:   &id,
+	})
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid webhook ID")
+		return
+	}
+
+	response, err := h.service.ListDeliveries(c.Request.Context(), middleware.WorkspaceID(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to list deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWebhookHandlerValidation(t *testing.T) {
+	handler := NewWebhookHandler(nil)
+
+	router := gin.New()
+	router.POST("/webhooks", handler.Create)
+	router.GET("/webhooks/:id", handler.Get)
+	router.PATCH("/webhooks/:id", handler.Update)
+	router.DELETE("/webhooks/:id", handler.Delete)
+	router.GET("/webhooks/:id/deliveries", handler.ListDeliveries)
+
+	validID := "00000000-0000-0000-0000-000000000001"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "create missing URL", method: http.MethodPost, path: "/webhooks", body: `{}`},
+		{name: "create invalid URL", method: http.MethodPost, path: "/webhooks", body: `{"url":"not a url"}`},
+		{name: "create unknown event", method: http.MethodPost, path: "/webhooks", body: `{"url":"http://localhost/hook","events":["project.exploded"]}`},
+		{name: "create short secret", method: http.MethodPost, path: "/webhooks", body: `{"url":"http://localhost/hook","secret":"short"}`},
+		{name: "get invalid ID", method: http.MethodGet, path: "/webhooks/invalid"},
+		{name: "update invalid ID", method: http.MethodPatch, path: "/webhooks/invalid", body: `{}`},
+		{name: "update invalid URL", method: http.MethodPatch, path: "/webhooks/" + validID, body: `{"url":"nope"}`},
+		{name: "delete invalid ID", method: http.MethodDelete, path: "/webhooks/invalid"},
+		{name: "deliveries invalid ID", method: http.MethodGet, path: "/webhooks/invalid/deliveries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+type WorkspaceHandler struct {
+	service        *services.WorkspaceService
+	projectService *services.ProjectService
+}
+
+func NewWorkspaceHandler(service *services.WorkspaceService, projectService *services.ProjectService) *WorkspaceHandler {
+	return &WorkspaceHandler{service: service, projectService: projectService}
+}
+
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	response, err := h.service.List(c.Request.Context(), middleware.CurrentUser(c))
+	if err != nil {
+		respondError(c, err, "Failed to list workspaces")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	var req models.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	workspace, err := h.service.Create(c.Request.Context(), middleware.CurrentUser(c), req.Name)
+	if err != nil {
+		respondError(c, err, "Failed to create workspace")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		WorkspaceID: workspace.ID,
+		Action:      models.AuditActionWorkspaceCreate,
+		TargetType:  models.AuditTargetWorkspace,
+		TargetID:    &workspace.ID,
+		Details:     map[string]interface{}{"name": workspace.Name},
+	})
+	c.JSON(http.StatusCreated, workspace)
+}
+
+func (h *WorkspaceHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid workspace ID")
+		return
+	}
+
+	var req models.UpdateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	workspace, err := h.service.Update(c.Request.Context(), middleware.CurrentUser(c), id, req)
+	if err != nil {
+		respondError(c, err, "Failed to update workspace")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		WorkspaceID: id,
+		Action:      models.AuditActionWorkspaceUpdate,
+		TargetType:  models.AuditTargetWorkspace,
+		TargetID:    &workspace.ID,
+		Details:     map[string]interface{}{"name": workspace.Name, "uniqueProjectNames": workspace.UniqueProjectNames},
+	})
+	c.JSON(http.StatusOK, workspace)
+}
+
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid workspace ID")
+		return
+	}
+
+	response, err := h.service.ListMembers(c.Request.Context(), middleware.CurrentUser(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to list members")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid workspace ID")
+		return
+	}
+
+	var req models.AddWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	member, err := h.service.AddMember(c.Request.Context(), middleware.CurrentUser(c), id, req)
+	if err != nil {
+		respondError(c, err, "Failed to add member")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		WorkspaceID: id,
+		Action:      models.AuditActionMemberAdd,
+		TargetType:  models.AuditTargetUser,
+		TargetID:    &member.UserID,
+		Details:     map[string]interface{}{"userName": member.UserName, "role": member.Role},
+	})
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid workspace ID")
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondInvalidParam(c, "userId", "invalid user ID")
+		return
+	}
+
+	err = h.service.RemoveMember(c.Request.Context(), middleware.CurrentUser(c), id, userID)
+	if err != nil {
+		respondError(c, err, "Failed to remove member")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		WorkspaceID: id,
+		Action:      models.AuditActionMemberRemove,
+		TargetType:  models.AuditTargetUser,
+		TargetID:    &userID,
+	})
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// TransferProject moves a project from the current workspace into the one
+// named in the request body. The client must have access to both.
+func (h *WorkspaceHandler) TransferProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "invalid project ID")
+		return
+	}
+
+	var req models.TransferProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.service.Authorize(ctx, middleware.CurrentUser(c), req.WorkspaceID); err != nil {
+		respondError(c, err, "Failed to transfer project")
+		return
+	}
+
+	project, err := h.projectService.Transfer(ctx, middleware.WorkspaceID(c), id, req.WorkspaceID)
+	if err != nil {
+		respondError(c, err, "Failed to transfer project")
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditActionProjectTransfer,
+		TargetType: models.AuditTargetProject,
+		TargetID:   &project.ID,
+		ProjectID:  &project.ID,
+		Details:    map[string]interface{}{"toWorkspaceId": req.WorkspaceID},
+	})
+	c.JSON(http.StatusOK, project)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWorkspaceHandlerInvalidIDs(t *testing.T) {
+	handler := NewWorkspaceHandler(nil, nil)
+
+	router := gin.New()
+	router.PATCH("/workspaces/:id", handler.Update)
+	router.GET("/workspaces/:id/members", handler.ListMembers)
+	router.POST("/workspaces/:id/members", handler.AddMember)
+	router.DELETE("/workspaces/:id/members/:userId", handler.RemoveMember)
+	router.POST("/projects/:id/transfer", handler.TransferProject)
+
+	validID := "00000000-0000-0000-0000-000000000001"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "update invalid workspace", method: http.MethodPatch, path: "/workspaces/invalid", body: `{"uniqueProjectNames":true}`},
+		{name: "update empty name", method: http.MethodPatch, path: "/workspaces/" + validID, body: `{"name":""}`},
+		{name: "list members invalid workspace", method: http.MethodGet, path: "/workspaces/invalid/members"},
+		{name: "add member invalid workspace", method: http.MethodPost, path: "/workspaces/invalid/members", body: `{"userName":"alice"}`},
+		{name: "add member missing user", method: http.MethodPost, path: "/workspaces/" + validID + "/members", body: `{}`},
+		{name: "add member invalid role", method: http.MethodPost, path: "/workspaces/" + validID + "/members", body: `{"userName":"alice","role":"admin"}`},
+		{name: "remove member invalid user", method: http.MethodDelete, path: "/workspaces/" + validID + "/members/invalid"},
+		{name: "transfer invalid project", method: http.MethodPost, path: "/projects/invalid/transfer", body: `{"workspaceId":"` + validID + `"}`},
+		{name: "transfer missing workspace", method: http.MethodPost, path: "/projects/" + validID + "/transfer", body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
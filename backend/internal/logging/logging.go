@@ -0,0 +1,86 @@
+// Package logging sets up the server's structured logs. Records logged
+// with a context carry the attributes added to it with With, so that every
+// line written while serving a request names the request.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Format is how records are written: FormatJSON, one object per line, or
+// FormatText, as key=value pairs for reading in a terminal.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New returns a logger writing records at level or above to w.
+func New(w io.Writer, format string, level slog.Leveler) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if format == FormatText {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(&contextHandler{Handler: h})
+}
+
+type attrsKey struct{}
+
+// attrs are the attributes of a context. They are shared by the contexts
+// derived from it, so that attributes learned while handling a request,
+// such as a project ID, also appear on lines logged by middleware.
+type attrs struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// NewContext returns a context that records logged with it, or with
+// contexts derived from it, carry the attributes passed to With.
+func NewContext(ctx context.Context, as ...slog.Attr) context.Context {
+	return context.WithValue(ctx, attrsKey{}, &attrs{attrs: as})
+}
+
+// With adds attributes to the records logged with ctx. It does nothing if
+// ctx does not come from NewContext.
+func With(ctx context.Context, as ...slog.Attr) {
+	if a, ok := ctx.Value(attrsKey{}).(*attrs); ok {
+		a.mu.Lock()
+		a.attrs = append(a.attrs, as...)
+		a.mu.Unlock()
+	}
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	a, ok := ctx.Value(attrsKey{}).(*attrs)
+	if !ok {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]slog.Attr(nil), a.attrs...)
+}
+
+// contextHandler adds the attributes of a record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		r.AddAttrs(contextAttrs(ctx)...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(as)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestContextAttributes(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, FormatJSON, slog.LevelInfo)
+
+	ctx := NewContext(context.Background(), slog.String("request_id", "req-1"))
+	// Attributes added later, and through a derived context, are shared.
+	derived, cancel := context.WithCancel(ctx)
+	defer cancel()
+	With(derived, slog.String("project_id", "p-1"))
+	With(context.Background(), slog.String("ignored", "x"))
+
+	logger.With("component", "test").InfoContext(ctx, "Saved")
+	logger.DebugContext(ctx, "Not logged")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q", out.String())
+	}
+	for key, want := range map[string]string{"msg": "Saved", "request_id": "req-1", "project_id": "p-1", "component": "test"} {
+		if record[key] != want {
+			t.Errorf("Expected %s=%s, got %v", key, want, record[key])
+		}
+	}
+	if _, ok := record["ignored"]; ok {
+		t.Errorf("Expected attributes of other contexts to be left out, got %v", record)
+	}
+}
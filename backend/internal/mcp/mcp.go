@@ -0,0 +1,213 @@
+// Package mcp serves the notes of a workspace over the Model Context
+// Protocol, so that agents can list, read, write and search them as typed
+// tools and read every topic as a resource.
+//
+// The protocol is JSON-RPC 2.0. Server.Handle answers one message and is
+// shared by the stdio transport in this package and the HTTP handler in the
+// handlers package.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+// serverVersion is reported to clients; it follows the API version.
+const serverVersion = "1.0"
+
+// protocolVersions are the protocol revisions the server speaks, newest
+// first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+const instructions = `Notes are organized by topic: each topic holds one Markdown note. ` +
+	`Before recording something, call list_topics and prefer appending to a topic with a similar name over creating a new one. ` +
+	`To recall, use search_notes or list_topics, then read_note.`
+
+// Projects is the part of the project service the server uses.
+type Projects interface {
+	List(ctx context.Context, q models.ProjectListQuery) (*models.ProjectListResponse, error)
+	Lookup(ctx context.Context, workspaceID uuid.UUID, name string, limit int) (*models.ProjectLookupResponse, error)
+	GetOrCreate(ctx context.Context, workspaceID uuid.UUID, name, author string) (*models.Project, bool, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, error)
+	GetDocument(ctx context.Context, workspaceID, projectID uuid.UUID) (*models.Document, error)
+}
+
+// Documents is the part of the document service the server uses.
+type Documents interface {
+	Update(ctx context.Context, workspaceID, id uuid.UUID, contentMD string, expectedVersion int, author string) (*models.Document, error)
+}
+
+// Caller is who a message is handled for.
+type Caller struct {
+	WorkspaceID uuid.UUID
+	// Author is the name document saves are made under.
+	Author string
+	// Record, if set, is called with an audit event for every change.
+	Record func(event models.AuditEvent)
+}
+
+func (c Caller) record(event models.AuditEvent) {
+	if c.Record != nil {
+		c.Record(event)
+	}
+}
+
+type Server struct {
+	projects  Projects
+	documents Documents
+}
+
+func New(projects Projects, documents Documents) *Server {
+	return &Server{projects: projects, documents: documents}
+}
+
+// JSON-RPC error codes.
+const (
+	codeParseError       = -32700
+	codeInvalidRequest   = -32600
+	codeMethodNotFound   = -32601
+	codeInvalidParams    = -32602
+	codeInternalError    = -32603
+	codeResourceNotFound = -32002
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func invalidParams(message string) *rpcError {
+	return &rpcError{Code: codeInvalidParams, Message: message}
+}
+
+// Handle answers a JSON-RPC message. It returns nil for notifications,
+// which get no response.
+func (s *Server) Handle(ctx context.Context, caller Caller, msg []byte) []byte {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "invalid JSON"}})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = json.RawMessage("null")
+		}
+		return encode(response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: codeInvalidRequest, Message: "not a JSON-RPC 2.0 request"}})
+	}
+	if req.ID == nil {
+		// Notifications such as notifications/initialized need no action.
+		return nil
+	}
+
+	resp := response{JSONRPC: "2.0", ID: req.ID}
+	result, err := s.dispatch(ctx, caller, req)
+	var rpcErr *rpcError
+	switch {
+	case err == nil:
+		resp.Result = result
+	case errors.As(err, &rpcErr):
+		resp.Error = rpcErr
+	default:
+		slog.ErrorContext(ctx, "MCP request failed", "mcp_method", req.Method, "error", err)
+		resp.Error = &rpcError{Code: codeInternalError, Message: "internal error"}
+	}
+	return encode(resp)
+}
+
+func (s *Server) dispatch(ctx context.Context, caller Caller, req request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools()}, nil
+	case "tools/call":
+		return s.callTool(ctx, caller, req.Params)
+	case "resources/list":
+		return s.listResources(ctx, caller)
+	case "resources/read":
+		return s.readResource(ctx, caller, req.Params)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	// Answer with the client's version if it is known, else the latest.
+	version := protocolVersions[0]
+	for _, v := range protocolVersions {
+		if v == p.ProtocolVersion {
+			version = v
+		}
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{"listChanged": false},
+			"resources": map[string]interface{}{"listChanged": false},
+		},
+		"serverInfo":   map[string]interface{}{"name": "md-editor", "version": serverVersion},
+		"instructions": instructions,
+	}, nil
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return invalidParams("invalid params: " + err.Error())
+	}
+	return nil
+}
+
+// toolError turns an error of the services into a message for the agent.
+// Other errors are internal and are not shown.
+func toolError(err error) (string, bool) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code + ": " + appErr.Message, true
+	}
+	return "", false
+}
+
+func encode(resp response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		slog.Error("Failed to encode MCP response", "error", err)
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{Code: codeInternalError, Message: "internal error"}})
+	}
+	return data
+}
@@ -0,0 +1,307 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository/sqlite"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+type testServer struct {
+	*Server
+	projects *services.ProjectService
+	caller   Caller
+	events   []models.AuditEvent
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	url := database.SQLiteScheme + filepath.Join(t.TempDir(), "test.db")
+	if err := database.MigrateUp(url); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db, err := database.NewSQLite(url)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	stores := sqlite.NewStores(db)
+	projects := services.NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, services.PageSizes{})
+	documents := services.NewDocumentService(stores.Tx, stores.Documents, stores.Projects, nil, nil, 0)
+
+	s := &testServer{Server: New(projects, documents), projects: projects}
+	s.caller = Caller{
+		WorkspaceID: models.DefaultWorkspaceID,
+		Author:      "agent",
+		Record:      func(event models.AuditEvent) { s.events = append(s.events, event) },
+	}
+	return s
+}
+
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// call sends a request and returns its response.
+func (s *testServer) call(t *testing.T, method string, params interface{}) rpcResponse {
+	t.Helper()
+	msg, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 7, "method": method, "params": params})
+	var resp rpcResponse
+	if err := json.Unmarshal(s.Handle(context.Background(), s.caller, msg), &resp); err != nil {
+		t.Fatalf("Invalid response to %s: %v", method, err)
+	}
+	if string(resp.ID) != "7" {
+		t.Errorf("Expected the request ID in the response, got %s", resp.ID)
+	}
+	return resp
+}
+
+// callTool calls a tool and decodes its structured result into out. It
+// returns the text of a tool error.
+func (s *testServer) callTool(t *testing.T, name string, args map[string]interface{}, out interface{}) string {
+	t.Helper()
+	resp := s.call(t, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	if resp.Error != nil {
+		t.Fatalf("Tool %s failed: %+v", name, resp.Error)
+	}
+
+	var result struct {
+		Content           []textContent   `json:"content"`
+		StructuredContent json.RawMessage `json:"structuredContent"`
+		IsError           bool            `json:"isError"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil || len(result.Content) != 1 {
+		t.Fatalf("Invalid tool result %s: %v", resp.Result, err)
+	}
+	if result.IsError {
+		return result.Content[0].Text
+	}
+	if out != nil {
+		if err := json.Unmarshal(result.StructuredContent, out); err != nil {
+			t.Fatalf("Invalid structured content %s: %v", result.StructuredContent, err)
+		}
+	}
+	return ""
+}
+
+func TestInitialize(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := map[string]string{
+		"2025-03-26": "2025-03-26",
+		"1999-01-01": protocolVersions[0],
+	}
+	for requested, want := range tests {
+		resp := s.call(t, "initialize", map[string]interface{}{"protocolVersion": requested})
+		var result struct {
+			ProtocolVersion string                 `json:"protocolVersion"`
+			Capabilities    map[string]interface{} `json:"capabilities"`
+		}
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			t.Fatalf("Invalid initialize result %s", resp.Result)
+		}
+		if result.ProtocolVersion != want || result.Capabilities["tools"] == nil || result.Capabilities["resources"] == nil {
+			t.Errorf("Requesting %s, expected version %s with tools and resources, got %+v", requested, want, result)
+		}
+	}
+}
+
+func TestListTools(t *testing.T) {
+	s := newTestServer(t)
+
+	var result struct {
+		Tools []struct {
+			Name        string                 `json:"name"`
+			InputSchema map[string]interface{} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(s.call(t, "tools/list", nil).Result, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+		if tool.InputSchema["type"] != "object" {
+			t.Errorf("Expected an object schema for %s, got %v", tool.Name, tool.InputSchema)
+		}
+	}
+	if got := strings.Join(names, ","); got != "list_topics,read_note,write_note,append_note,search_notes" {
+		t.Errorf("Unexpected tools %s", got)
+	}
+}
+
+func TestNotes(t *testing.T) {
+	s := newTestServer(t)
+
+	var saved savedNote
+	if msg := s.callTool(t, "append_note", map[string]interface{}{"topic": "Postgres Tuning", "content": "## Autovacuum\n"}, &saved); msg != "" {
+		t.Fatalf("append_note failed: %s", msg)
+	}
+	if !saved.Created || saved.Version != 2 {
+		t.Errorf("Expected a new topic at version 2, got %+v", saved)
+	}
+	if len(s.events) != 2 || s.events[0].Action != models.AuditActionProjectCreate || s.events[1].Action != models.AuditActionDocumentUpdate {
+		t.Errorf("Expected the creation and the save to be recorded, got %+v", s.events)
+	}
+
+	if msg := s.callTool(t, "append_note", map[string]interface{}{"topic": "postgres tuning", "content": "Raise the scale factor."}, &saved); msg != "" {
+		t.Fatalf("append_note failed: %s", msg)
+	}
+	if saved.Created || saved.Topic != "Postgres Tuning" {
+		t.Errorf("Expected the existing topic, got %+v", saved)
+	}
+
+	var read note
+	if msg := s.callTool(t, "read_note", map[string]interface{}{"topic": saved.ProjectID.String()}, &read); msg != "" {
+		t.Fatalf("read_note failed: %s", msg)
+	}
+	if read.Content != "## Autovacuum\n\nRaise the scale factor.\n" || read.Version != 3 {
+		t.Errorf("Expected both appends at version 3, got %+v", read)
+	}
+
+	// A write based on an old version is refused; one based on the current
+	// version goes through.
+	msg := s.callTool(t, "write_note", map[string]interface{}{"topic": "Postgres Tuning", "content": "# Stale", "version": 2}, nil)
+	if !strings.HasPrefix(msg, "version_conflict:") {
+		t.Errorf("Expected a version conflict, got %q", msg)
+	}
+	if msg := s.callTool(t, "write_note", map[string]interface{}{"topic": "Postgres Tuning", "content": "# Rewritten\n", "version": read.Version}, &saved); msg != "" || saved.Version != 4 {
+		t.Errorf("Expected version 4, got %+v, %q", saved, msg)
+	}
+
+	msg = s.callTool(t, "read_note", map[string]interface{}{"topic": "Postgres"}, nil)
+	if !strings.Contains(msg, `no topic named "Postgres"`) || !strings.Contains(msg, `"Postgres Tuning"`) {
+		t.Errorf("Expected a miss suggesting the similar topic, got %q", msg)
+	}
+
+	var topics struct {
+		Topics []topicSummary `json:"topics"`
+	}
+	if msg := s.callTool(t, "list_topics", nil, &topics); msg != "" || len(topics.Topics) != 1 || topics.Topics[0].Title != "Rewritten" {
+		t.Errorf("Expected the topic with its title, got %+v, %q", topics, msg)
+	}
+}
+
+func TestSearchNotes(t *testing.T) {
+	s := newTestServer(t)
+	for topic, content := range map[string]string{
+		"Vacuum Notes": "nothing here",
+		"Postgres":     "# Tuning\nAutovacuum settings\n",
+	} {
+		if msg := s.callTool(t, "write_note", map[string]interface{}{"topic": topic, "content": content}, nil); msg != "" {
+			t.Fatalf("write_note failed: %s", msg)
+		}
+	}
+
+	var result struct {
+		Matches []searchMatch `json:"matches"`
+	}
+	if msg := s.callTool(t, "search_notes", map[string]interface{}{"query": "VACUUM"}, &result); msg != "" {
+		t.Fatalf("search_notes failed: %s", msg)
+	}
+	if len(result.Matches) != 2 {
+		t.Fatalf("Expected a name match and a line match, got %+v", result.Matches)
+	}
+	if m := result.Matches[0]; m.Topic != "Vacuum Notes" || m.Line != 0 {
+		t.Errorf("Expected the name match first, got %+v", m)
+	}
+	if m := result.Matches[1]; m.Topic != "Postgres" || m.Line != 2 || m.Text != "Autovacuum settings" {
+		t.Errorf("Expected the matching line, got %+v", m)
+	}
+}
+
+func TestInvalidToolCalls(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"unknown tool", map[string]interface{}{"name": "delete_everything"}},
+		{"missing argument", map[string]interface{}{"name": "read_note", "arguments": map[string]interface{}{}}},
+		{"unknown argument", map[string]interface{}{"name": "read_note", "arguments": map[string]interface{}{"topic": "a", "page": 2}}},
+		{"wrong type", map[string]interface{}{"name": "write_note", "arguments": map[string]interface{}{"topic": "a", "content": "b", "version": "1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := s.call(t, "tools/call", tt.params)
+			if resp.Error == nil || resp.Error.Code != codeInvalidParams {
+				t.Errorf("Expected invalid params, got %+v %s", resp.Error, resp.Result)
+			}
+		})
+	}
+	if len(s.events) != 0 {
+		t.Errorf("Expected nothing to be recorded, got %+v", s.events)
+	}
+}
+
+func TestResources(t *testing.T) {
+	s := newTestServer(t)
+	var saved savedNote
+	s.callTool(t, "write_note", map[string]interface{}{"topic": "Decisions", "content": "# Decisions\nUse SQLite.\n"}, &saved)
+
+	var list struct {
+		Resources []resource `json:"resources"`
+	}
+	if err := json.Unmarshal(s.call(t, "resources/list", nil).Result, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Resources) != 1 || list.Resources[0].URI != resourceURI(saved.ProjectID) || list.Resources[0].Name != "Decisions" {
+		t.Fatalf("Expected the project as a resource, got %+v", list.Resources)
+	}
+
+	var read struct {
+		Contents []resourceContents `json:"contents"`
+	}
+	if err := json.Unmarshal(s.call(t, "resources/read", map[string]string{"uri": list.Resources[0].URI}).Result, &read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Contents) != 1 || read.Contents[0].Text != "# Decisions\nUse SQLite.\n" || read.Contents[0].MimeType != "text/markdown" {
+		t.Errorf("Expected the note, got %+v", read.Contents)
+	}
+
+	for _, uri := range []string{"md-editor://projects/" + models.DefaultWorkspaceID.String(), "file:///etc/passwd"} {
+		if resp := s.call(t, "resources/read", map[string]string{"uri": uri}); resp.Error == nil || resp.Error.Code != codeResourceNotFound {
+			t.Errorf("Expected %s not to be found, got %+v", uri, resp.Error)
+		}
+	}
+}
+
+func TestServeStdio(t *testing.T) {
+	s := newTestServer(t)
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":"two","method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"prompts/list"}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := s.ServeStdio(context.Background(), strings.NewReader(in), &out, s.caller); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a response to each request but not to the notification, got %q", out.String())
+	}
+	wants := []string{`"id":1,"result"`, `"id":"two","result":{}`, `"code":-32601`, `"code":-32700`}
+	for i, want := range wants {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("Expected response %d to contain %s, got %s", i, want, lines[i])
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+// resourcePrefix starts the URI of every project's note.
+const resourcePrefix = "md-editor://projects/"
+
+type resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType"`
+}
+
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+func resourceURI(id uuid.UUID) string {
+	return resourcePrefix + id.String()
+}
+
+// listResources offers the note of every project as a resource.
+func (s *Server) listResources(ctx context.Context, caller Caller) (interface{}, error) {
+	projects, err := s.listProjects(ctx, models.ProjectListQuery{
+		WorkspaceID:    caller.WorkspaceID,
+		Sort:           models.ProjectSortName,
+		IncludePreview: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]resource, 0, len(projects))
+	for _, p := range projects {
+		r := resource{URI: resourceURI(p.ID), Name: p.Name, MimeType: "text/markdown"}
+		if p.Preview != nil {
+			r.Description = p.Preview.Excerpt
+		}
+		resources = append(resources, r)
+	}
+	return map[string]interface{}{"resources": resources}, nil
+}
+
+func (s *Server) readResource(ctx context.Context, caller Caller, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	notFound := &rpcError{Code: codeResourceNotFound, Message: "resource not found: " + p.URI}
+	rest, ok := strings.CutPrefix(p.URI, resourcePrefix)
+	id, err := uuid.Parse(rest)
+	if !ok || err != nil {
+		return nil, notFound
+	}
+	project, err := s.findTopic(ctx, caller.WorkspaceID, id.String())
+	if err != nil {
+		if _, ok := toolError(err); ok {
+			return nil, notFound
+		}
+		return nil, err
+	}
+	doc, err := s.projects.GetDocument(ctx, caller.WorkspaceID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"contents": []resourceContents{{URI: p.URI, MimeType: "text/markdown", Text: doc.ContentMD}},
+	}, nil
+}
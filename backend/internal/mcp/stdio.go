@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// maxMessageBytes bounds a single message on stdio.
+const maxMessageBytes = 16 << 20
+
+// ServeStdio answers newline-delimited messages read from in on out, for
+// caller, until in is closed or ctx is cancelled.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer, caller Caller) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64<<10), maxMessageBytes)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		resp := s.Handle(ctx, caller, scanner.Bytes())
+		if resp == nil {
+			continue
+		}
+		if _, err := out.Write(append(resp, '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,478 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+const (
+	listPageSize = 100
+	// maxAppendAttempts bounds the retries of append_note when others save
+	// the note at the same time.
+	maxAppendAttempts = 5
+)
+
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations map[string]interface{} `json:"annotations"`
+
+	call func(ctx context.Context, caller Caller, args json.RawMessage) (interface{}, error)
+}
+
+// tools returns the tools the server offers, bound to s.
+func (s *Server) tools() []tool {
+	return []tool{
+		{
+			Name:        "list_topics",
+			Description: "List the note topics with the title and opening lines of each note. Check here before creating a topic: a topic with a similar name should usually be appended to instead.",
+			InputSchema: objectSchema(nil, map[string]interface{}{
+				"query": stringProperty("Only list topics whose name contains or resembles this text"),
+			}),
+			Annotations: map[string]interface{}{"readOnlyHint": true},
+			call:        s.listTopics,
+		},
+		{
+			Name:        "read_note",
+			Description: "Read the Markdown note of a topic, together with its version.",
+			InputSchema: objectSchema([]string{"topic"}, map[string]interface{}{
+				"topic": stringProperty("Topic name, ignoring case, or project ID"),
+			}),
+			Annotations: map[string]interface{}{"readOnlyHint": true},
+			call:        s.readNote,
+		},
+		{
+			Name:        "write_note",
+			Description: "Replace the whole note of a topic, creating the topic if there is none. Pass the version returned by read_note so that changes made since are not overwritten.",
+			InputSchema: objectSchema([]string{"topic", "content"}, map[string]interface{}{
+				"topic":   stringProperty("Topic name, ignoring case, or project ID"),
+				"content": stringProperty("The complete Markdown note"),
+				"version": map[string]interface{}{
+					"type":        "integer",
+					"minimum":     1,
+					"description": "Version the content is based on; the write fails if the note has changed since",
+				},
+			}),
+			Annotations: map[string]interface{}{"readOnlyHint": false, "destructiveHint": true, "idempotentHint": true},
+			call:        s.writeNote,
+		},
+		{
+			Name:        "append_note",
+			Description: "Append Markdown to the end of a topic's note, creating the topic if there is none.",
+			InputSchema: objectSchema([]string{"topic", "content"}, map[string]interface{}{
+				"topic":   stringProperty("Topic name, ignoring case, or project ID"),
+				"content": stringProperty("Markdown to append, e.g. a new section"),
+			}),
+			Annotations: map[string]interface{}{"readOnlyHint": false, "destructiveHint": false},
+			call:        s.appendNote,
+		},
+		{
+			Name:        "search_notes",
+			Description: "Find topics whose name matches and lines of notes that contain the query, ignoring case.",
+			InputSchema: objectSchema([]string{"query"}, map[string]interface{}{
+				"query": stringProperty("Text to search for"),
+			}),
+			Annotations: map[string]interface{}{"readOnlyHint": true},
+			call:        s.searchNotes,
+		},
+	}
+}
+
+func objectSchema(required []string, properties map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent interface{}   `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+// callTool runs a tool. Failures the agent can act on, such as a missing
+// topic or a version conflict, are tool results with isError set.
+func (s *Server) callTool(ctx context.Context, caller Caller, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	for _, t := range s.tools() {
+		if t.Name != p.Name {
+			continue
+		}
+		args := p.Arguments
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+
+		result, err := t.call(ctx, caller, args)
+		if err != nil {
+			var rpcErr *rpcError
+			if errors.As(err, &rpcErr) {
+				return nil, err
+			}
+			message, ok := toolError(err)
+			if !ok {
+				return nil, err
+			}
+			return toolResult{Content: []textContent{{Type: "text", Text: message}}, IsError: true}, nil
+		}
+
+		// Clients without structured content support read the text.
+		text, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		return toolResult{Content: []textContent{{Type: "text", Text: string(text)}}, StructuredContent: result}, nil
+	}
+	return nil, invalidParams("unknown tool: " + p.Name)
+}
+
+// decodeArgs decodes tool arguments, rejecting unknown ones.
+func decodeArgs(args json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return invalidParams("invalid arguments: " + err.Error())
+	}
+	return nil
+}
+
+// requireArgs checks that string arguments, given as name and value pairs,
+// are not blank.
+func requireArgs(pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if strings.TrimSpace(pairs[i+1]) == "" {
+			return invalidParams(pairs[i] + " is required")
+		}
+	}
+	return nil
+}
+
+type topicSummary struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Title     string    `json:"title,omitempty"`
+	Excerpt   string    `json:"excerpt,omitempty"`
+}
+
+func (s *Server) listTopics(ctx context.Context, caller Caller, args json.RawMessage) (interface{}, error) {
+	var a struct {
+		Query string `json:"query"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+
+	projects, err := s.listProjects(ctx, models.ProjectListQuery{
+		WorkspaceID:    caller.WorkspaceID,
+		Sort:           models.ProjectSortName,
+		Search:         a.Query,
+		IncludePreview: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]topicSummary, 0, len(projects))
+	for _, p := range projects {
+		topic := topicSummary{ID: p.ID, Name: p.Name, UpdatedAt: p.UpdatedAt}
+		if p.Preview != nil {
+			topic.Title, topic.Excerpt = p.Preview.Title, p.Preview.Excerpt
+		}
+		topics = append(topics, topic)
+	}
+	return map[string]interface{}{"topics": topics}, nil
+}
+
+// listProjects returns every project matching q.
+func (s *Server) listProjects(ctx context.Context, q models.ProjectListQuery) ([]models.Project, error) {
+	q.PageSize = listPageSize
+	var projects []models.Project
+	for q.Page = 1; ; q.Page++ {
+		resp, err := s.projects.List(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, resp.Projects...)
+		if resp.NextCursor == "" {
+			return projects, nil
+		}
+	}
+}
+
+type note struct {
+	Topic     string    `json:"topic"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Version   int       `json:"version"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (s *Server) readNote(ctx context.Context, caller Caller, args json.RawMessage) (interface{}, error) {
+	var a struct {
+		Topic string `json:"topic"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("topic", a.Topic); err != nil {
+		return nil, err
+	}
+
+	project, err := s.findTopic(ctx, caller.WorkspaceID, a.Topic)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.projects.GetDocument(ctx, caller.WorkspaceID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	return note{Topic: project.Name, ProjectID: project.ID, Version: doc.Version, Content: doc.ContentMD, UpdatedAt: doc.UpdatedAt}, nil
+}
+
+type savedNote struct {
+	Topic     string    `json:"topic"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Version   int       `json:"version"`
+	Created   bool      `json:"created"`
+}
+
+func (s *Server) writeNote(ctx context.Context, caller Caller, args json.RawMessage) (interface{}, error) {
+	var a struct {
+		Topic   string `json:"topic"`
+		Content string `json:"content"`
+		Version *int   `json:"version"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("topic", a.Topic); err != nil {
+		return nil, err
+	}
+
+	project, created, err := s.openTopic(ctx, caller, a.Topic)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.projects.GetDocument(ctx, caller.WorkspaceID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	version := doc.Version
+	if a.Version != nil {
+		version = *a.Version
+	}
+	saved, err := s.save(ctx, caller, doc, a.Content, version)
+	if errors.Is(err, services.ErrVersionConflict) {
+		return nil, apperror.New(http.StatusConflict, "version_conflict",
+			fmt.Sprintf("the note is at version %d, not %d; read it again and merge your changes", doc.Version, version))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return savedNote{Topic: project.Name, ProjectID: project.ID, Version: saved.Version, Created: created}, nil
+}
+
+func (s *Server) appendNote(ctx context.Context, caller Caller, args json.RawMessage) (interface{}, error) {
+	var a struct {
+		Topic   string `json:"topic"`
+		Content string `json:"content"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("topic", a.Topic, "content", a.Content); err != nil {
+		return nil, err
+	}
+
+	project, created, err := s.openTopic(ctx, caller, a.Topic)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		doc, err := s.projects.GetDocument(ctx, caller.WorkspaceID, project.ID)
+		if err != nil {
+			return nil, err
+		}
+		saved, err := s.save(ctx, caller, doc, appendMarkdown(doc.ContentMD, a.Content), doc.Version)
+		if errors.Is(err, services.ErrVersionConflict) && attempt < maxAppendAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return savedNote{Topic: project.Name, ProjectID: project.ID, Version: saved.Version, Created: created}, nil
+	}
+}
+
+// save updates doc on behalf of the caller and records the change.
+func (s *Server) save(ctx context.Context, caller Caller, doc *models.Document, content string, version int) (*models.Document, error) {
+	saved, err := s.documents.Update(ctx, caller.WorkspaceID, doc.ID, content, version, caller.Author)
+	if err != nil {
+		return nil, err
+	}
+
+	before := version
+	caller.record(models.AuditEvent{
+		Action:        models.AuditActionDocumentUpdate,
+		TargetType:    models.AuditTargetDocument,
+		TargetID:      &saved.ID,
+		ProjectID:     &saved.ProjectID,
+		BeforeVersion: &before,
+		AfterVersion:  &saved.Version,
+		Details:       map[string]interface{}{"bytes": len(saved.ContentMD)},
+	})
+	return saved, nil
+}
+
+// appendMarkdown adds addition to content as a block of its own, separated
+// by a blank line.
+func appendMarkdown(content, addition string) string {
+	addition = strings.Trim(addition, "\n") + "\n"
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return addition
+	}
+	return content + "\n\n" + addition
+}
+
+type searchMatch struct {
+	Topic     string    `json:"topic"`
+	ProjectID uuid.UUID `json:"projectId"`
+	// Line is zero for a match of the topic name.
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+func (s *Server) searchNotes(ctx context.Context, caller Caller, args json.RawMessage) (interface{}, error) {
+	var a struct {
+		Query string `json:"query"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("query", a.Query); err != nil {
+		return nil, err
+	}
+
+	named, err := s.listProjects(ctx, models.ProjectListQuery{WorkspaceID: caller.WorkspaceID, Sort: models.ProjectSortName, Search: a.Query})
+	if err != nil {
+		return nil, err
+	}
+	matches := []searchMatch{}
+	for _, p := range named {
+		matches = append(matches, searchMatch{Topic: p.Name, ProjectID: p.ID})
+	}
+
+	projects, err := s.listProjects(ctx, models.ProjectListQuery{WorkspaceID: caller.WorkspaceID, Sort: models.ProjectSortName})
+	if err != nil {
+		return nil, err
+	}
+	query := strings.ToLower(a.Query)
+	for _, p := range projects {
+		doc, err := s.projects.GetDocument(ctx, caller.WorkspaceID, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(strings.NewReader(doc.ContentMD))
+		scanner.Buffer(nil, len(doc.ContentMD)+1)
+		for line := 1; scanner.Scan(); line++ {
+			if strings.Contains(strings.ToLower(scanner.Text()), query) {
+				matches = append(matches, searchMatch{Topic: p.Name, ProjectID: p.ID, Line: line, Text: scanner.Text()})
+			}
+		}
+	}
+	return map[string]interface{}{"matches": matches}, nil
+}
+
+// findTopic returns the project a topic names: its ID, or its name ignoring
+// case. Merged projects resolve to the project they were merged into.
+func (s *Server) findTopic(ctx context.Context, workspaceID uuid.UUID, topic string) (*models.Project, error) {
+	if id, err := uuid.Parse(topic); err == nil {
+		project, err := s.projects.GetByID(ctx, workspaceID, id)
+		var moved *services.ProjectMovedError
+		if errors.As(err, &moved) {
+			return s.projects.GetByID(ctx, workspaceID, moved.To)
+		}
+		return project, err
+	}
+
+	lookup, err := s.projects.Lookup(ctx, workspaceID, topic, 3)
+	if err != nil {
+		return nil, err
+	}
+	var similar []string
+	for _, match := range lookup.Matches {
+		if match.Exact {
+			project := match.Project
+			return &project, nil
+		}
+		similar = append(similar, fmt.Sprintf("%q", match.Project.Name))
+	}
+
+	message := fmt.Sprintf("no topic named %q", topic)
+	if len(similar) > 0 {
+		message += "; similar topics: " + strings.Join(similar, ", ")
+	}
+	return nil, apperror.New(http.StatusNotFound, services.ErrProjectNotFound.Code, message)
+}
+
+// openTopic is findTopic for writes: a name that no topic has yet creates
+// one. It reports whether the topic was created.
+func (s *Server) openTopic(ctx context.Context, caller Caller, topic string) (*models.Project, bool, error) {
+	if _, err := uuid.Parse(topic); err == nil {
+		project, err := s.findTopic(ctx, caller.WorkspaceID, topic)
+		return project, false, err
+	}
+
+	project, created, err := s.projects.GetOrCreate(ctx, caller.WorkspaceID, strings.TrimSpace(topic), caller.Author)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		caller.record(models.AuditEvent{
+			Action:     models.AuditActionProjectCreate,
+			TargetType: models.AuditTargetProject,
+			TargetID:   &project.ID,
+			ProjectID:  &project.ID,
+			Details:    map[string]interface{}{"name": project.Name},
+		})
+	}
+	return project, created, nil
+}
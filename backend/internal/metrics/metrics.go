@@ -0,0 +1,96 @@
+// Package metrics exposes the server's Prometheus metrics: HTTP traffic,
+// database connection pool statistics and domain activity such as document
+// saves and version conflicts.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mdeditor"
+
+// Registry holds every metric the server exposes.
+var Registry = prometheus.NewRegistry()
+
+// Domain counters, incremented by the services.
+var (
+	DocumentsSaved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "documents_saved_total",
+		Help:      "Document versions saved.",
+	})
+	DocumentBytesWritten = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "document_bytes_written_total",
+		Help:      "Bytes of Markdown content saved.",
+	})
+	VersionConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "document_version_conflicts_total",
+		Help:      "Document saves rejected because the document had changed since the version they were based on.",
+	})
+	ProjectsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "projects_created_total",
+		Help:      "Projects created, including by duplication and splits.",
+	})
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by route and status.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		DocumentsSaved,
+		DocumentBytesWritten,
+		VersionConflicts,
+		ProjectsCreated,
+		httpRequests,
+		httpDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// unmatchedRoute labels requests that matched no route, so that arbitrary
+// paths do not each become a time series.
+const unmatchedRoute = "unmatched"
+
+// HTTP counts and times every request by its route template.
+func HTTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(HTTP())
+	router.GET("/api/projects/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/api/projects/a", "/api/projects/b", "/wp-login.php"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/api/projects/:id", "404")); got != 2 {
+		t.Errorf("Expected both requests counted under their route, got %v", got)
+	}
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", unmatchedRoute, "404")); got != 1 {
+		t.Errorf("Expected the unmatched request counted once, got %v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	VersionConflicts.Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, want := range []string{"mdeditor_document_version_conflicts_total 1", "mdeditor_documents_saved_total", "go_goroutines"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("Expected %q in the metrics", want)
+		}
+	}
+}
+
+func TestPoolCollector(t *testing.T) {
+	// The pool connects lazily, so no database is needed to read its
+	// statistics.
+	config, err := pgxpool.ParseConfig("postgres://user@127.0.0.1:1/db?pool_max_conns=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	collector := newPoolCollector(pool)
+	if got := testutil.CollectAndCount(collector); got != 9 {
+		t.Errorf("Expected 9 pool metrics, got %d", got)
+	}
+	expected := "# HELP mdeditor_db_pool_max_connections Maximum size of the pool.\n# TYPE mdeditor_db_pool_max_connections gauge\nmdeditor_db_pool_max_connections 7\n"
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "mdeditor_db_pool_max_connections"); err != nil {
+		t.Error(err)
+	}
+}
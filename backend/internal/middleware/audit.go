@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+const (
+	auditEventKey = "mdeditor.auditEvents"
+	skipAuditKey  = "mdeditor.skipAudit"
+)
+
+const maxUserAgentLength = 512
+
+// RecordAudit attaches the domain details of a mutation to the request. The
+// Audit middleware fills in who made the request and persists the event once
+// the handler has succeeded. A request that makes several changes records
+// each of them.
+func RecordAudit(c *gin.Context, event models.AuditEvent) {
+	v, _ := c.Get(auditEventKey)
+	events, _ := v.([]*models.AuditEvent)
+	c.Set(auditEventKey, append(events, &event))
+}
+
+// SkipAudit marks a mutating request as having changed nothing, such as a
+// POST that only reads.
+func SkipAudit(c *gin.Context) {
+	c.Set(skipAuditKey, true)
+}
+
+// Audit records an audit event for every successful mutating request. Handlers
+// describe the mutation with RecordAudit; mutating requests that do not are
+// still recorded, with the route as the action.
+func Audit(audit *services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !isMutation(c.Request.Method) || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		// A replayed response repeats a mutation that was already recorded.
+		if IsIdempotentReplay(c) || c.GetBool(skipAuditKey) {
+			return
+		}
+
+		events := []*models.AuditEvent{{
+			Action:     c.Request.Method + " " + c.FullPath(),
+			TargetType: models.AuditTargetRequest,
+		}}
+		if v, ok := c.Get(auditEventKey); ok {
+			events = v.([]*models.AuditEvent)
+		}
+		for _, event := range events {
+			record(c, audit, event)
+		}
+	}
+}
+
+// record fills in who made the request and persists event.
+func record(c *gin.Context, audit *services.AuditService, event *models.AuditEvent) {
+	if event.WorkspaceID == uuid.Nil {
+		event.WorkspaceID = WorkspaceID(c)
+	}
+	if user := CurrentUser(c); user != nil {
+		event.ActorID = &user.ID
+		event.ActorName = user.Name
+	}
+	event.RequestID = CurrentRequestID(c)
+	event.ClientIP = c.ClientIP()
+	event.UserAgent = c.Request.UserAgent()
+	if len(event.UserAgent) > maxUserAgentLength {
+		event.UserAgent = event.UserAgent[:maxUserAgentLength]
+	}
+
+	// The response has already been sent, so a failure here can only be
+	// reported in the server log.
+	if err := audit.Record(c.Request.Context(), event); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to record audit event", "action", event.Action, "error", err)
+	}
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
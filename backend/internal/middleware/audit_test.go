@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestIsMutation(t *testing.T) {
+	tests := map[string]bool{
+		http.MethodGet:     false,
+		http.MethodHead:    false,
+		http.MethodOptions: false,
+		http.MethodPost:    true,
+		http.MethodPut:     true,
+		http.MethodPatch:   true,
+		http.MethodDelete:  true,
+	}
+
+	for method, want := range tests {
+		if got := isMutation(method); got != want {
+			t.Errorf("isMutation(%s) = %v, want %v", method, got, want)
+		}
+	}
+}
+
+func TestRecordAudit(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	RecordAudit(c, models.AuditEvent{Action: models.AuditActionProjectCreate})
+	RecordAudit(c, models.AuditEvent{Action: models.AuditActionDocumentUpdate})
+
+	v, ok := c.Get(auditEventKey)
+	if !ok {
+		t.Fatal("Expected audit events to be attached to the context")
+	}
+	events := v.([]*models.AuditEvent)
+	if len(events) != 2 || events[0].Action != models.AuditActionProjectCreate || events[1].Action != models.AuditActionDocumentUpdate {
+		t.Errorf("Expected both events in order, got %+v", events)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, CurrentRequestID(c))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Body.String() != "abc-123" || w.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("Expected propagated request ID, got body %q header %q", w.Body.String(), w.Header().Get(RequestIDHeader))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Body.String() == "" || w.Header().Get(RequestIDHeader) != w.Body.String() {
+		t.Errorf("Expected generated request ID, got body %q header %q", w.Body.String(), w.Header().Get(RequestIDHeader))
+	}
+}
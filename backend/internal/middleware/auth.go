@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+const (
+	// WorkspaceHeader selects the workspace a request operates in. Requests
+	// without it use the default workspace.
+	WorkspaceHeader = "X-Workspace-ID"
+
+	userKey      = "mdeditor.user"
+	workspaceKey = "mdeditor.workspace"
+)
+
+// Authenticate resolves an optional "Authorization: Bearer <token>" header to
+// a user. Requests without the header continue anonymously; requests with an
+// unknown token are rejected.
+func Authenticate(users *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := BearerToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		user, err := users.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			AbortWithError(c, err, "Failed to authenticate")
+			return
+		}
+
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// RequireWorkspace resolves the X-Workspace-ID header and checks that the
+// current user may access that workspace.
+func RequireWorkspace(workspaces *services.WorkspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspaceID := models.DefaultWorkspaceID
+		if header := c.GetHeader(WorkspaceHeader); header != "" {
+			id, err := uuid.Parse(header)
+			if err != nil {
+				AbortWithError(c, apperror.InvalidParam(WorkspaceHeader, "invalid workspace ID"), "")
+				return
+			}
+			workspaceID = id
+		}
+
+		err := workspaces.Authorize(c.Request.Context(), CurrentUser(c), workspaceID)
+		if err != nil {
+			// Missing workspaces look like forbidden ones, so that their
+			// IDs cannot be probed.
+			if errors.Is(err, services.ErrWorkspaceNotFound) {
+				err = services.ErrWorkspaceForbidden
+			}
+			AbortWithError(c, err, "Failed to authorize workspace")
+			return
+		}
+
+		c.Set(workspaceKey, workspaceID)
+		c.Next()
+	}
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header, or an
+// empty string.
+func BearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// CurrentUser returns the authenticated user, or nil for anonymous requests.
+func CurrentUser(c *gin.Context) *models.User {
+	if v, ok := c.Get(userKey); ok {
+		return v.(*models.User)
+	}
+	return nil
+}
+
+// ActorName returns the name of the authenticated user, or the anonymous
+// actor name for anonymous requests.
+func ActorName(c *gin.Context) string {
+	if user := CurrentUser(c); user != nil {
+		return user.Name
+	}
+	return models.AuditActorAnonymous
+}
+
+// WorkspaceID returns the workspace resolved by RequireWorkspace, falling
+// back to the default workspace.
+func WorkspaceID(c *gin.Context) uuid.UUID {
+	if v, ok := c.Get(workspaceKey); ok {
+		return v.(uuid.UUID)
+	}
+	return models.DefaultWorkspaceID
+}
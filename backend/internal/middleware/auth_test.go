@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "missing", header: "", want: ""},
+		{name: "bearer", header: "Bearer abc", want: "abc"},
+		{name: "lowercase scheme", header: "bearer abc", want: "abc"},
+		{name: "other scheme", header: "Basic abc", want: ""},
+		{name: "empty token", header: "Bearer ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("Authorization", tt.header)
+			}
+
+			if got := BearerToken(c); got != tt.want {
+				t.Errorf("Expected '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRequireWorkspaceInvalidHeader(t *testing.T) {
+	router := gin.New()
+	router.Use(RequireWorkspace(nil))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(WorkspaceHeader, "not-a-uuid")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestContextDefaults(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	if CurrentUser(c) != nil {
+		t.Error("Expected no user on a fresh context")
+	}
+	if WorkspaceID(c) != models.DefaultWorkspaceID {
+		t.Errorf("Expected default workspace, got %s", WorkspaceID(c))
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+)
+
+var (
+	ErrBodyTooLarge = apperror.New(http.StatusRequestEntityTooLarge, apperror.CodeBodyTooLarge, "request body too large")
+	errRateLimited  = apperror.New(http.StatusTooManyRequests, apperror.CodeRateLimited, "rate limit exceeded")
+	errUnreadable   = apperror.New(http.StatusBadRequest, apperror.CodeMalformedBody, "failed to read request body")
+)
+
+// AbortWithError answers the request with the problem details of err and
+// stops the handler chain. Errors without a code are logged and reported as
+// internal errors with the fallback detail.
+func AbortWithError(c *gin.Context, err error, fallback string) {
+	problem := apperror.ProblemFor(err, fallback)
+	if problem.Code == apperror.CodeInternal {
+		slog.ErrorContext(c.Request.Context(), "Request failed", "error", err)
+	}
+	AbortWithProblem(c, problem)
+}
+
+// AbortWithProblem writes problem as an application/problem+json response
+// and stops the handler chain.
+func AbortWithProblem(c *gin.Context, problem apperror.Problem) {
+	c.Header("Content-Type", apperror.ContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
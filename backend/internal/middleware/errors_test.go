@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+)
+
+func TestAbortWithError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "coded", err: errRateLimited, wantStatus: http.StatusTooManyRequests, wantBody: `"code":"rate_limited"`},
+		{name: "internal", err: errors.New("pq: connection refused"), wantStatus: http.StatusInternalServerError, wantBody: `"detail":"Failed to load"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			AbortWithError(c, tt.err, "Failed to load")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != apperror.ContentType {
+				t.Errorf("Expected Content-Type %s, got %s", apperror.ContentType, ct)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "pq:") {
+				t.Errorf("Expected internal errors to be hidden, got %s", w.Body.String())
+			}
+			if !c.IsAborted() {
+				t.Error("Expected the handler chain to be aborted")
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+const idempotentReplayKey = "mdeditor.idempotentReplay"
+
+// IdempotencyKeyHeader lets clients retry a mutation without repeating it.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks responses that were replayed from an earlier
+// request with the same idempotency key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored along with the body.
+var replayedHeaders = []string{"Content-Type", "Location", "X-Document-Version"}
+
+// IdempotencyStore keeps the outcome of requests made with an idempotency
+// key. It is implemented by services.IdempotencyService.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, scope, key, requestHash string) (*models.IdempotentResponse, error)
+	Complete(ctx context.Context, scope, key string, resp models.IdempotentResponse) error
+	Release(ctx context.Context, scope, key string) error
+}
+
+// Idempotency makes mutating requests that carry an Idempotency-Key header
+// safe to retry. The first request with a key runs normally and its response
+// is stored; later requests with the same key, query, version precondition
+// and body get the stored response replayed. Keys are scoped to the
+// workspace and the caller, and server errors are not stored so that they
+// can be retried.
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutation(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			AbortWithError(c, apperror.InvalidParam(IdempotencyKeyHeader, "must be at most 255 characters"), "")
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					AbortWithError(c, ErrBodyTooLarge, "")
+					return
+				}
+				AbortWithError(c, errUnreadable, "")
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		scope := idempotencyScope(c)
+		hash := requestHash(c.Request, body)
+
+		stored, err := store.Begin(c.Request.Context(), scope, key, hash)
+		if err != nil {
+			AbortWithError(c, err, "Failed to check Idempotency-Key")
+			return
+		}
+		if stored != nil {
+			replay(c, stored)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// The client may already have given up on this request; its response
+		// must be stored regardless so that the retry gets it.
+		ctx := context.WithoutCancel(c.Request.Context())
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Release(ctx, scope, key); err != nil {
+				slog.ErrorContext(ctx, "Failed to release idempotency key", "error", err)
+			}
+			return
+		}
+
+		resp := models.IdempotentResponse{
+			RequestHash: hash,
+			StatusCode:  status,
+			Headers:     map[string]string{},
+			Body:        recorder.body.Bytes(),
+		}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				resp.Headers[name] = value
+			}
+		}
+		if err := store.Complete(ctx, scope, key, resp); err != nil {
+			slog.ErrorContext(ctx, "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// IsIdempotentReplay reports whether the response was replayed rather than
+// produced by the handler.
+func IsIdempotentReplay(c *gin.Context) bool {
+	return c.GetBool(idempotentReplayKey)
+}
+
+func replay(c *gin.Context, stored *models.IdempotentResponse) {
+	c.Set(idempotentReplayKey, true)
+	for name, value := range stored.Headers {
+		c.Header(name, value)
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(stored.StatusCode)
+	if len(stored.Body) > 0 {
+		c.Writer.Write(stored.Body)
+	}
+	c.Abort()
+}
+
+func idempotencyScope(c *gin.Context) string {
+	caller := "anonymous"
+	if user := CurrentUser(c); user != nil {
+		caller = user.ID.String()
+	}
+	return WorkspaceID(c).String() + "/" + caller
+}
+
+// hashedHeaders are the request headers that change what a request does, and
+// so must match for a retry to be replayed.
+var hashedHeaders = []string{"X-Document-Version", "If-Match"}
+
+// requestHash identifies a request by its method, path, query, hashedHeaders
+// and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	for _, name := range hashedHeaders {
+		h.Write([]byte(name + ": " + r.Header.Get(name) + "\n"))
+	}
+	h.Write([]byte("\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body as it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
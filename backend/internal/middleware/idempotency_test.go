@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+// memoryIdempotencyStore mimics services.IdempotencyService in memory.
+type memoryIdempotencyStore struct {
+	responses map[string]*models.IdempotentResponse
+	released  int
+}
+
+func (s *memoryIdempotencyStore) Begin(ctx context.Context, scope, key, requestHash string) (*models.IdempotentResponse, error) {
+	stored, ok := s.responses[scope+key]
+	if !ok {
+		s.responses[scope+key] = &models.IdempotentResponse{RequestHash: requestHash}
+		return nil, nil
+	}
+	if stored.RequestHash != requestHash {
+		return nil, services.ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return nil, services.ErrIdempotencyKeyInProgress
+	}
+	return stored, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, scope, key string, resp models.IdempotentResponse) error {
+	s.responses[scope+key] = &resp
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, scope, key string) error {
+	delete(s.responses, scope+key)
+	s.released++
+	return nil
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	store := &memoryIdempotencyStore{responses: map[string]*models.IdempotentResponse{}}
+	calls := 0
+
+	router := gin.New()
+	router.Use(Idempotency(store))
+	router.POST("/projects", func(c *gin.Context) {
+		calls++
+		c.Header("X-Document-Version", "1")
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("key-1", `{"name":"a"}`)
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("Expected first request to run, got %d", first.Code)
+	}
+
+	retry := send("key-1", `{"name":"a"}`)
+	if retry.Code != http.StatusCreated {
+		t.Errorf("Expected replayed status %d, got %d", http.StatusCreated, retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected replayed body %q, got %q", first.Body.String(), retry.Body.String())
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || retry.Header().Get("X-Document-Version") != "1" {
+		t.Errorf("Expected replay headers, got %v", retry.Header())
+	}
+	if calls != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", calls)
+	}
+
+	if w := send("key-1", `{"name":"b"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a different body, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+
+	send("", `{"name":"a"}`)
+	send("", `{"name":"a"}`)
+	if calls != 3 {
+		t.Errorf("Expected requests without a key to always run, ran %d times", calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := &memoryIdempotencyStore{responses: map[string]*models.IdempotentResponse{}}
+	calls := 0
+
+	router := gin.New()
+	router.Use(Idempotency(store))
+	router.POST("/projects", func(c *gin.Context) {
+		calls++
+		c.Status(http.StatusCreated)
+	})
+
+	// The first request with the key is still running.
+	scope := models.DefaultWorkspaceID.String() + "/anonymous"
+	req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader("{}"))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	store.Begin(context.Background(), scope, "key-1", requestHash(req, []byte("{}")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+	if calls != 0 {
+		t.Errorf("Expected handler not to run, ran %d times", calls)
+	}
+}
+
+func TestIdempotencyReleasesServerErrors(t *testing.T) {
+	store := &memoryIdempotencyStore{responses: map[string]*models.IdempotentResponse{}}
+	calls := 0
+
+	router := gin.New()
+	router.Use(Idempotency(store))
+	router.PUT("/documents/:id", func(c *gin.Context) {
+		calls++
+		c.Status(http.StatusInternalServerError)
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPut, "/documents/1", strings.NewReader("{}"))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if calls != 2 || store.released != 2 {
+		t.Errorf("Expected failed requests to be retried, got %d calls and %d releases", calls, store.released)
+	}
+}
+
+func TestIdempotencyKeyTooLong(t *testing.T) {
+	router := gin.New()
+	router.Use(Idempotency(&memoryIdempotencyStore{responses: map[string]*models.IdempotentResponse{}}))
+	router.POST("/projects", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/projects", nil)
+	req.Header.Set(IdempotencyKeyHeader, strings.Repeat("k", 256))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestIdempotencyRejectsDifferentPreconditions(t *testing.T) {
+	store := &memoryIdempotencyStore{responses: map[string]*models.IdempotentResponse{}}
+
+	router := gin.New()
+	router.Use(Idempotency(store))
+	router.PUT("/documents/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(query, version string) int {
+		req := httptest.NewRequest(http.MethodPut, "/documents/1"+query, strings.NewReader(`{"contentMd":"a"}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		req.Header.Set("X-Document-Version", version)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("", "1"); code != http.StatusOK {
+		t.Fatalf("Expected first request to run, got %d", code)
+	}
+	if code := send("", "1"); code != http.StatusOK {
+		t.Errorf("Expected identical retry to be replayed, got %d", code)
+	}
+	if code := send("", "2"); code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a different version, got %d", http.StatusUnprocessableEntity, code)
+	}
+	if code := send("?section=Intro", "1"); code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a different query, got %d", http.StatusUnprocessableEntity, code)
+	}
+}
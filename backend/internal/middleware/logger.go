@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/logging"
+)
+
+// Logger logs a line for every request, and makes every line logged while
+// serving it carry its request ID, route and the project or document it
+// addresses. It must run after RequestID.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		attrs := []slog.Attr{
+			slog.String("request_id", CurrentRequestID(c)),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+		}
+		attrs = append(attrs, resourceAttrs(c)...)
+		ctx := logging.NewContext(c.Request.Context(), attrs...)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(ctx, level, "Request served",
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// resourceAttrs names the project or document the route's :id addresses.
+func resourceAttrs(c *gin.Context) []slog.Attr {
+	id := c.Param("id")
+	if id == "" {
+		return nil
+	}
+	switch route := c.FullPath(); {
+	case strings.HasPrefix(route, "/api/projects/:id"), strings.HasPrefix(route, "/api/trash/:id"):
+		return []slog.Attr{slog.String("project_id", id)}
+	case strings.HasPrefix(route, "/api/documents/:id"):
+		return []slog.Attr{slog.String("document_id", id)}
+	}
+	return nil
+}
+
+// Recovery answers requests whose handler panicked with an internal error
+// and logs the panic with its stack.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "Handler panicked",
+			"error", fmt.Sprint(recovered),
+			"stack", string(debug.Stack()),
+		)
+		AbortWithProblem(c, apperror.ProblemFor(nil, "internal server error"))
+	})
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/logging"
+)
+
+// captureLogs sends the default logger's JSON records to the returned
+// buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&logs, logging.FormatJSON, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &logs
+}
+
+func decodeLogs(t *testing.T, logs *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected a JSON record, got %q", line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerLogsInternalErrors(t *testing.T) {
+	logs := captureLogs(t)
+
+	router := gin.New()
+	router.Use(RequestID(), Logger(), Recovery())
+	router.GET("/api/projects/:id/document", func(c *gin.Context) {
+		AbortWithError(c, errors.New("pq: connection refused"), "Failed to load")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/projects/0b1c/document", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	records := decodeLogs(t, logs)
+	if len(records) != 2 {
+		t.Fatalf("Expected the error and the request to be logged, got %s", logs.String())
+	}
+	for _, record := range records {
+		if record["request_id"] != "req-1" || record["route"] != "/api/projects/:id/document" || record["project_id"] != "0b1c" {
+			t.Errorf("Expected the request's attributes, got %v", record)
+		}
+	}
+	if records[0]["level"] != "ERROR" || records[0]["error"] != "pq: connection refused" {
+		t.Errorf("Expected the underlying error, got %v", records[0])
+	}
+	if records[1]["status"] != float64(http.StatusInternalServerError) || records[1]["latency_ms"] == nil {
+		t.Errorf("Expected the status and latency, got %v", records[1])
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	logs := captureLogs(t)
+
+	router := gin.New()
+	router.Use(RequestID(), Logger(), Recovery())
+	router.PUT("/api/documents/:id", func(c *gin.Context) {
+		panic("nil map")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/documents/9f2e", nil))
+
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), `"code":"internal_error"`) {
+		t.Errorf("Expected an internal error problem, got %d %s", w.Code, w.Body.String())
+	}
+	records := decodeLogs(t, logs)
+	if len(records) == 0 || records[0]["error"] != "nil map" || records[0]["document_id"] != "9f2e" || records[0]["stack"] == nil {
+		t.Errorf("Expected the panic to be logged with its stack, got %s", logs.String())
+	}
+}
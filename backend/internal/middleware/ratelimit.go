@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitConfig sets the token bucket budgets per client. Reads are GET,
+// HEAD and OPTIONS requests; everything else counts against the write
+// budget. A rate of zero disables that budget.
+type RateLimitConfig struct {
+	ReadPerMinute  int
+	ReadBurst      int
+	WritePerMinute int
+	WriteBurst     int
+}
+
+// bucketSweepInterval is how often idle buckets are dropped so that clients
+// that went away do not accumulate.
+const bucketSweepInterval = time.Minute
+
+// RateLimit throttles clients with token buckets keyed by authenticated user,
+// or by client IP for anonymous requests. It must run after Authenticate:
+// keying on the raw bearer token would let every made-up token start with a
+// full bucket. Rejected requests get 429 with a Retry-After header.
+func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	read := newTokenBuckets(cfg.ReadPerMinute, cfg.ReadBurst, time.Now)
+	write := newTokenBuckets(cfg.WritePerMinute, cfg.WriteBurst, time.Now)
+
+	return func(c *gin.Context) {
+		buckets := write
+		if isRead(c.Request.Method) {
+			buckets = read
+		}
+
+		if buckets == nil {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.ClientIP()
+		if user := CurrentUser(c); user != nil {
+			key = "user:" + user.ID.String()
+		}
+
+		if ok, retryAfter := buckets.take(key); !ok {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			AbortWithError(c, errRateLimited, "")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func isRead(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type tokenBuckets struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64
+	now       func() time.Time
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newTokenBuckets(perMinute, burst int, now func() time.Time) *tokenBuckets {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBuckets{
+		rate:      float64(perMinute) / 60,
+		burst:     float64(burst),
+		now:       now,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: now(),
+	}
+}
+
+// take consumes one token for key. If none is available it reports how long
+// the client has to wait for the next one.
+func (b *tokenBuckets) take(key string) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if now.Sub(b.lastSweep) >= bucketSweepInterval {
+		b.sweep(now)
+	}
+
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: b.burst, last: now}
+		b.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(b.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*b.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := (1 - bucket.tokens) / b.rate
+		return false, time.Duration(wait * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+// sweep drops buckets that would have refilled completely, since a fresh
+// bucket is indistinguishable from them.
+func (b *tokenBuckets) sweep(now time.Time) {
+	for key, bucket := range b.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*b.rate >= b.burst {
+			delete(b.buckets, key)
+		}
+	}
+	b.lastSweep = now
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestTokenBucketsTake(t *testing.T) {
+	now := time.Unix(0, 0)
+	buckets := newTokenBuckets(60, 2, func() time.Time { return now })
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := buckets.take("a"); !ok {
+			t.Fatalf("Expected request %d within burst to pass", i+1)
+		}
+	}
+
+	ok, retryAfter := buckets.take("a")
+	if ok {
+		t.Fatal("Expected request beyond burst to be rejected")
+	}
+	if retryAfter != time.Second {
+		t.Errorf("Expected retry after 1s, got %v", retryAfter)
+	}
+
+	if ok, _ := buckets.take("b"); !ok {
+		t.Error("Expected other clients to have their own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := buckets.take("a"); !ok {
+		t.Error("Expected bucket to refill after one second")
+	}
+}
+
+func TestTokenBucketsSweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	buckets := newTokenBuckets(60, 2, func() time.Time { return now })
+
+	buckets.take("a")
+	now = now.Add(2 * bucketSweepInterval)
+	buckets.take("b")
+
+	if _, ok := buckets.buckets["a"]; ok {
+		t.Error("Expected idle bucket to be swept")
+	}
+	if _, ok := buckets.buckets["b"]; !ok {
+		t.Error("Expected active bucket to be kept")
+	}
+}
+
+func TestNewTokenBucketsDisabled(t *testing.T) {
+	if newTokenBuckets(0, 10, time.Now) != nil {
+		t.Error("Expected a zero rate to disable the limit")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	users := map[string]*models.User{"token": {ID: uuid.New(), Name: "agent"}}
+
+	router := gin.New()
+	// Stands in for Authenticate.
+	router.Use(func(c *gin.Context) {
+		if user, ok := users[BearerToken(c)]; ok {
+			c.Set(userKey, user)
+		}
+	})
+	router.Use(RateLimit(RateLimitConfig{ReadPerMinute: 60, ReadBurst: 1, WritePerMinute: 0}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(method, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(http.MethodGet, ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected first read to pass, got %d", w.Code)
+	}
+
+	w := send(http.MethodGet, "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected second read to be limited, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", w.Header().Get("Retry-After"))
+	}
+
+	if w := send(http.MethodGet, "token"); w.Code != http.StatusOK {
+		t.Errorf("Expected users to be limited separately from their IP, got %d", w.Code)
+	}
+	if w := send(http.MethodGet, "token"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected second read of the user to be limited, got %d", w.Code)
+	}
+	if w := send(http.MethodGet, "made-up"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a token that is not authenticated to be limited by IP, got %d", w.Code)
+	}
+
+	for i := 0; i < 5; i++ {
+		if w := send(http.MethodPost, ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected writes to be unlimited, got %d", w.Code)
+		}
+	}
+}
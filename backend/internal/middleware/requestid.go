@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDKey = "mdeditor.requestId"
+
+	maxRequestIDLength = 128
+)
+
+// RequestID propagates the client's X-Request-ID, or generates one, and echoes
+// it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// CurrentRequestID returns the id assigned by RequestID, or an empty string.
+func CurrentRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
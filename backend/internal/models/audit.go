@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionProjectCreate    = "project.create"
+	AuditActionProjectRename    = "project.rename"
+	AuditActionProjectDelete    = "project.delete"
+	AuditActionProjectTransfer  = "project.transfer"
+	AuditActionProjectRestore   = "project.restore"
+	AuditActionProjectPurge     = "project.purge"
+	AuditActionProjectDuplicate = "project.duplicate"
+	AuditActionProjectMerge     = "project.merge"
+	AuditActionProjectSplit     = "project.split"
+	AuditActionProjectArchive   = "project.archive"
+	AuditActionProjectUnarchive = "project.unarchive"
+	AuditActionProjectPin       = "project.pin"
+	AuditActionProjectUnpin     = "project.unpin"
+	AuditActionDocumentUpdate   = "document.update"
+	AuditActionShareLinkCreate  = "share_link.create"
+	AuditActionShareLinkRevoke  = "share_link.revoke"
+	AuditActionWorkspaceCreate  = "workspace.create"
+	AuditActionWorkspaceUpdate  = "workspace.update"
+	AuditActionMemberAdd        = "workspace.member_add"
+	AuditActionMemberRemove     = "workspace.member_remove"
+	AuditActionWebhookCreate    = "webhook.create"
+	AuditActionWebhookUpdate    = "webhook.update"
+	AuditActionWebhookDelete    = "webhook.delete"
+	AuditActionTemplateCreate   = "template.create"
+	AuditActionTemplateUpdate   = "template.update"
+	AuditActionTemplateDelete   = "template.delete"
+
+	AuditTargetProject   = "project"
+	AuditTargetDocument  = "document"
+	AuditTargetShareLink = "share_link"
+	AuditTargetWorkspace = "workspace"
+	AuditTargetUser      = "user"
+	AuditTargetWebhook   = "webhook"
+	AuditTargetTemplate  = "template"
+	AuditTargetRequest   = "request"
+
+	// AuditActorAnonymous is recorded for requests without a token.
+	AuditActorAnonymous = "anonymous"
+)
+
+type AuditEvent struct {
+	ID            int64                  `json:"id"`
+	OccurredAt    time.Time              `json:"occurredAt"`
+	WorkspaceID   uuid.UUID              `json:"workspaceId"`
+	ActorID       *uuid.UUID             `json:"actorId,omitempty"`
+	ActorName     string                 `json:"actor"`
+	Action        string                 `json:"action"`
+	TargetType    string                 `json:"targetType"`
+	TargetID      *uuid.UUID             `json:"targetId,omitempty"`
+	ProjectID     *uuid.UUID             `json:"projectId,omitempty"`
+	RequestID     string                 `json:"requestId"`
+	ClientIP      string                 `json:"clientIp"`
+	UserAgent     string                 `json:"userAgent"`
+	BeforeVersion *int                   `json:"beforeVersion,omitempty"`
+	AfterVersion  *int                   `json:"afterVersion,omitempty"`
+	Details       map[string]interface{} `json:"details"`
+}
+
+type AuditFilter struct {
+	WorkspaceID uuid.UUID
+	ProjectID   *uuid.UUID
+	ActorName   string
+	Action      string
+	Since       *time.Time
+	// BeforeID returns only events older than the given event, for paging.
+	BeforeID int64
+	Limit    int
+}
+
+type AuditListResponse struct {
+	Events []AuditEvent `json:"events"`
+	// NextBefore is the value to pass as ?before= to fetch the next page, or
+	// zero when there are no more events.
+	NextBefore int64 `json:"nextBefore,omitempty"`
+}
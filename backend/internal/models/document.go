@@ -26,3 +26,50 @@ type DocumentResponse struct {
 	Version   int       `json:"version"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+type DocumentRevision struct {
+	DocumentID uuid.UUID `json:"documentId"`
+	Version    int       `json:"version"`
+	ContentMD  string    `json:"contentMd"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// DocumentSummary is what project listings need to describe a document.
+type DocumentSummary struct {
+	ProjectID uuid.UUID
+	ContentMD string
+	Version   int
+	UpdatedAt time.Time
+	// LastEditor is the actor of the latest audited document update.
+	LastEditor *string
+}
+
+// DocumentCommit is a saved document version in git storage.
+// PendingDocumentCommit is a saved document version still to be committed
+// to git storage. Document holds the content of that version.
+type PendingDocumentCommit struct {
+	ID       int64
+	Project  Project
+	Document Document
+	Author   string
+}
+
+type DocumentCommit struct {
+	Hash        string    `json:"hash"`
+	Version     int       `json:"version"`
+	Author      string    `json:"author"`
+	Message     string    `json:"message"`
+	CommittedAt time.Time `json:"committedAt"`
+}
+
+type DocumentHistoryResponse struct {
+	Commits []DocumentCommit `json:"commits"`
+}
+
+// DocumentDiff is the unified diff between two committed versions. A
+// FromVersion of zero means the diff starts from an empty document.
+type DocumentDiff struct {
+	FromVersion int    `json:"fromVersion,omitempty"`
+	ToVersion   int    `json:"toVersion"`
+	Diff        string `json:"diff"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// IdempotentResponse is the stored outcome of a request made with an
+// idempotency key. StatusCode is zero while the request is still running.
+type IdempotentResponse struct {
+	RequestHash string
+	StatusCode  int
+	Headers     map[string]string
+	Body        []byte
+}
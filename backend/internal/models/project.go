@@ -6,25 +6,173 @@ import (
 	"github.com/google/uuid"
 )
 
+// MaxProjectNameLength is the longest project name in characters, the size
+// of the name column.
+const MaxProjectNameLength = 255
+
 type Project struct {
-	ID        uuid.UUID  `json:"id"`
-	Name      string     `json:"name"`
-	CreatedAt time.Time  `json:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt"`
-	DeletedAt *time.Time `json:"-"`
+	ID          uuid.UUID  `json:"id"`
+	WorkspaceID uuid.UUID  `json:"workspaceId"`
+	Name        string     `json:"name"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	DeletedAt   *time.Time `json:"-"`
+	// ArchivedAt is set while the project is archived and read-only.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	PinnedAt   *time.Time `json:"pinnedAt,omitempty"`
+	// Favourite is only filled in by listings for the authenticated user.
+	Favourite bool `json:"favourite,omitempty"`
+
+	// Preview and Stats are only filled in by listings that ask for them.
+	Preview *ProjectPreview `json:"preview,omitempty"`
+	Stats   *ProjectStats   `json:"stats,omitempty"`
+}
+
+type ProjectPreview struct {
+	// Title is the text of the document's first heading.
+	Title   string `json:"title,omitempty"`
+	Excerpt string `json:"excerpt"`
+}
+
+type ProjectStats struct {
+	WordCount    int       `json:"wordCount"`
+	HeadingCount int       `json:"headingCount"`
+	Version      int       `json:"version"`
+	EditedAt     time.Time `json:"editedAt"`
+	LastEditor   *string   `json:"lastEditor,omitempty"`
 }
 
 type CreateProjectRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=255"`
+	// TemplateID starts the document from a template, with Variables filling
+	// in its placeholders.
+	TemplateID *uuid.UUID        `json:"templateId"`
+	Variables  map[string]string `json:"variables"`
 }
 
 type UpdateProjectRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=255"`
 }
 
+const (
+	ProjectSortCreatedAt = "createdAt"
+	ProjectSortUpdatedAt = "updatedAt"
+	ProjectSortName      = "name"
+)
+
+// ProjectCursor marks the last project of a page in a given ordering; the
+// next page starts right after it.
+type ProjectCursor struct {
+	Sort       string    `json:"s"`
+	Descending bool      `json:"d"`
+	Value      string    `json:"v"`
+	ID         uuid.UUID `json:"i"`
+	// Pinned records whether the last project was pinned, since pinned
+	// projects come first in every ordering.
+	Pinned bool `json:"p,omitempty"`
+}
+
+const (
+	ProjectArchivedExclude = "exclude"
+	ProjectArchivedOnly    = "only"
+	ProjectArchivedInclude = "include"
+)
+
+type ProjectListQuery struct {
+	WorkspaceID uuid.UUID
+	Sort        string
+	Descending  bool
+	// Search matches project names by substring or trigram similarity.
+	Search       string
+	UpdatedSince *time.Time
+	// Archived is one of the ProjectArchived* values; archived projects are
+	// excluded by default.
+	Archived   string
+	PinnedOnly bool
+	// UserID marks the favourites of the current user in the results;
+	// FavouritesOnly restricts the listing to them.
+	UserID         *uuid.UUID
+	FavouritesOnly bool
+	// After switches from offset to keyset pagination.
+	After    *ProjectCursor
+	Page     int
+	PageSize int
+
+	IncludePreview bool
+	IncludeStats   bool
+}
+
+// ProjectMatch is a project whose name resembles a looked-up name. Score is
+// the trigram similarity between the two, from 0 to 1.
+type ProjectMatch struct {
+	Project Project `json:"project"`
+	Score   float64 `json:"score"`
+	Exact   bool    `json:"exact"`
+}
+
+type ProjectLookupResponse struct {
+	Name    string         `json:"name"`
+	Matches []ProjectMatch `json:"matches"`
+}
+
 type ProjectListResponse struct {
 	Projects   []Project `json:"projects"`
 	TotalCount int       `json:"totalCount"`
-	Page       int       `json:"page"`
+	Page       int       `json:"page,omitempty"`
 	PageSize   int       `json:"pageSize"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
+
+const (
+	MergeModeConcatenate = "concatenate"
+	MergeModeInterleave  = "interleave"
+)
+
+type DuplicateProjectRequest struct {
+	Name string `json:"name" binding:"max=255"`
+}
+
+// MergeProjectsRequest merges the documents of all projects into the first
+// one. The others are soft-deleted and redirect to it.
+type MergeProjectsRequest struct {
+	ProjectIDs []uuid.UUID `json:"projectIds" binding:"required,min=2,max=50"`
+	Name       *string     `json:"name" binding:"omitempty,min=1,max=255"`
+	Mode       string      `json:"mode" binding:"omitempty,oneof=concatenate interleave"`
+}
+
+// SplitProjectRequest moves the named top-level sections of a document into
+// new projects of their own.
+type SplitProjectRequest struct {
+	Headings []string `json:"headings" binding:"required,min=1,max=50,dive,min=1"`
+}
+
+type ProjectDocumentResponse struct {
+	Project  Project  `json:"project"`
+	Document Document `json:"document"`
+}
+
+type SplitProjectResponse struct {
+	Project  Project   `json:"project"`
+	Document Document  `json:"document"`
+	Created  []Project `json:"created"`
+}
+
+// TrashedProject is a soft-deleted project as listed in the trash.
+type TrashedProject struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspaceId"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	DeletedAt   time.Time `json:"deletedAt"`
+	// PurgeAt is when the project will be removed permanently, if trash
+	// retention is enabled.
+	PurgeAt *time.Time `json:"purgeAt,omitempty"`
+}
+
+type TrashListResponse struct {
+	Projects   []TrashedProject `json:"projects"`
+	TotalCount int              `json:"totalCount"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"pageSize"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ShareLink struct {
+	ID            uuid.UUID  `json:"id"`
+	ProjectID     uuid.UUID  `json:"projectId"`
+	Token         string     `json:"token"`
+	URL           string     `json:"url"`
+	PasswordHash  *string    `json:"-"`
+	HasPassword   bool       `json:"hasPassword"`
+	PinnedVersion *int       `json:"pinnedVersion,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt     *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	Password      string     `json:"password" binding:"max=72"`
+	PinnedVersion *int       `json:"pinnedVersion" binding:"omitempty,min=1"`
+}
+
+type ShareLinkListResponse struct {
+	ShareLinks []ShareLink `json:"shareLinks"`
+}
+
+type SharedDocument struct {
+	ProjectName string    `json:"projectName"`
+	ContentMD   string    `json:"contentMd"`
+	Version     int       `json:"version"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestShareLinkJSONHidesPasswordHash(t *testing.T) {
+	hash := "$2a$10$secret"
+	link := ShareLink{
+		ID:           uuid.New(),
+		ProjectID:    uuid.New(),
+		Token:        "token",
+		PasswordHash: &hash,
+		HasPassword:  true,
+	}
+
+	data, err := json.Marshal(link)
+	if err != nil {
+		t.Fatalf("Failed to marshal share link: %v", err)
+	}
+
+	if strings.Contains(string(data), hash) {
+		t.Errorf("Expected password hash to be omitted, got %s", data)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal share link: %v", err)
+	}
+
+	if decoded["hasPassword"] != true {
+		t.Errorf("Expected hasPassword true, got %v", decoded["hasPassword"])
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Template is a markdown skeleton that new projects can start from. Its
+// content may contain {{placeholders}} that are filled in on creation.
+type Template struct {
+	ID              uuid.UUID  `json:"id"`
+	WorkspaceID     uuid.UUID  `json:"workspaceId"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	ContentMD       string     `json:"contentMd"`
+	SourceProjectID *uuid.UUID `json:"sourceProjectId,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+type CreateTemplateRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+	ContentMD   string `json:"contentMd"`
+}
+
+type UpdateTemplateRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+	ContentMD   *string `json:"contentMd"`
+}
+
+// SaveAsTemplateRequest turns a project's current document into a template.
+// The name defaults to the project's name.
+type SaveAsTemplateRequest struct {
+	Name        string `json:"name" binding:"max=255"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+type TemplateListResponse struct {
+	Templates []Template `json:"templates"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventProjectCreated  = "project.created"
+	EventProjectRenamed  = "project.renamed"
+	EventProjectDeleted  = "project.deleted"
+	EventDocumentUpdated = "document.updated"
+)
+
+// WebhookEvents lists the event types subscribers can filter on.
+var WebhookEvents = []string{
+	EventProjectCreated,
+	EventProjectRenamed,
+	EventProjectDeleted,
+	EventDocumentUpdated,
+}
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+type Webhook struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspaceId"`
+	URL         string    `json:"url"`
+	// Events is the subscribed event types; empty means all events.
+	Events []string `json:"events"`
+	// Secret is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=2048"`
+	Events []string `json:"events" binding:"dive,oneof=project.created project.renamed project.deleted document.updated"`
+	Secret string   `json:"secret" binding:"omitempty,min=16,max=255"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string   `json:"url" binding:"omitempty,url,max=2048"`
+	Events *[]string `json:"events" binding:"omitempty,dive,oneof=project.created project.renamed project.deleted document.updated"`
+	Active *bool     `json:"active"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookPayload is the JSON body posted to subscribers.
+type WebhookPayload struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	OccurredAt  time.Time   `json:"occurredAt"`
+	WorkspaceID uuid.UUID   `json:"workspaceId"`
+	Data        interface{} `json:"data"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhookId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// PendingWebhookDelivery is a delivery claimed by the dispatcher together
+// with the target it must be sent to.
+type PendingWebhookDelivery struct {
+	ID        int64
+	WebhookID uuid.UUID
+	EventType string
+	Payload   []byte
+	Attempts  int
+	URL       string
+	Secret    string
+}
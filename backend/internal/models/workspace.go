@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultWorkspaceID is the workspace that existing projects were migrated
+// into. It is the only workspace anonymous clients can reach.
+var DefaultWorkspaceID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleMember = "member"
+)
+
+type Workspace struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Role string    `json:"role,omitempty"`
+	// UniqueProjectNames forbids two live projects whose names differ only
+	// in case.
+	UniqueProjectNames bool      `json:"uniqueProjectNames"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID `json:"workspaceId"`
+	UserID      uuid.UUID `json:"userId"`
+	UserName    string    `json:"userName"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=255"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name               *string `json:"name" binding:"omitempty,min=1,max=255"`
+	UniqueProjectNames *bool   `json:"uniqueProjectNames"`
+}
+
+type AddWorkspaceMemberRequest struct {
+	UserName string `json:"userName" binding:"required,min=1,max=255"`
+	Role     string `json:"role" binding:"omitempty,oneof=owner member"`
+}
+
+type TransferProjectRequest struct {
+	WorkspaceID uuid.UUID `json:"workspaceId" binding:"required"`
+}
+
+type WorkspaceListResponse struct {
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+type WorkspaceMemberListResponse struct {
+	Members []WorkspaceMember `json:"members"`
+}
@@ -0,0 +1,60 @@
+// Package openapi holds the OpenAPI description of the HTTP API. It serves
+// the document and a page rendering it, and validates requests, and in
+// development responses, against it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+//go:embed openapi.yaml
+var document []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+func init() {
+	// The predefined uuid format rejects the nil-prefixed ID of the default
+	// workspace, so accept whatever the handlers accept.
+	openapi3.DefineStringFormatCallback("uuid", func(value string) error {
+		_, err := uuid.Parse(value)
+		return err
+	})
+}
+
+// Load parses the embedded document and checks that it is valid.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return doc, nil
+}
+
+// SpecHandler serves doc as JSON.
+func SpecHandler(doc *openapi3.T) (gin.HandlerFunc, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	}, nil
+}
+
+// DocsHandler serves a page that renders the document of SpecHandler. The
+// page is self-contained, so the docs work without internet access.
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
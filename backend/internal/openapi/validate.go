@@ -0,0 +1,265 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+)
+
+var errMalformedBody = apperror.New(http.StatusBadRequest, apperror.CodeMalformedBody, "request body is not valid JSON")
+
+// fieldCodes maps the schema keywords a value can fail to the field error
+// codes that request binding reports for the same rules.
+var fieldCodes = map[string]string{
+	"required":  "required",
+	"type":      "invalid_type",
+	"enum":      "oneof",
+	"minLength": "min",
+	"minItems":  "min",
+	"minimum":   "min",
+	"maxLength": "max",
+	"maxItems":  "max",
+	"maximum":   "max",
+}
+
+// Validate rejects requests that do not match the operation of their route
+// in doc with a validation_failed problem listing the invalid fields. With
+// validateResponses, responses are checked too and mismatches are logged,
+// which is meant for development: it keeps a copy of every response body.
+// Routes that doc does not describe pass through unchecked.
+func Validate(doc *openapi3.T, validateResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := findRoute(doc, c)
+		if route == nil {
+			c.Next()
+			return
+		}
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError: true,
+				// Tokens are checked by the Authenticate middleware.
+				AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+				SkipSettingDefaults: true,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			middleware.AbortWithError(c, requestError(err), "")
+			return
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		err := openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 recorder.Status(),
+			Header:                 recorder.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+			Options: &openapi3filter.Options{
+				MultiError:            true,
+				IncludeResponseStatus: true,
+			},
+		})
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "Response does not match the OpenAPI document", "error", err)
+		}
+	}
+}
+
+// findRoute returns the operation of the gin route that matched the
+// request, or nil if doc does not describe it. Relying on gin's match keeps
+// both sides agreeing on which of /projects/lookup and /projects/{id} a
+// request is for.
+func findRoute(doc *openapi3.T, c *gin.Context) *routers.Route {
+	fullPath := c.FullPath()
+	if fullPath == "" {
+		return nil
+	}
+
+	path := PathFromGin(fullPath)
+	item := doc.Paths.Value(path)
+	if item == nil {
+		return nil
+	}
+	operation := item.GetOperation(c.Request.Method)
+	if operation == nil {
+		return nil
+	}
+
+	return &routers.Route{
+		Spec:      doc,
+		Path:      path,
+		PathItem:  item,
+		Method:    c.Request.Method,
+		Operation: operation,
+	}
+}
+
+// PathFromGin converts a gin route path such as /projects/:id to the
+// OpenAPI form /projects/{id}.
+func PathFromGin(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// requestError turns the errors of a request validation into a problem the
+// client can act on.
+func requestError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return middleware.ErrBodyTooLarge
+	}
+
+	var fields []apperror.FieldError
+	for _, err := range flatten(err) {
+		var reqErr *openapi3filter.RequestError
+		if !errors.As(err, &reqErr) {
+			// Only request errors are expected; anything else is a bug.
+			return err
+		}
+
+		var parseErr *openapi3filter.ParseError
+		switch {
+		case reqErr.RequestBody != nil && errors.As(reqErr.Err, &parseErr):
+			return errMalformedBody
+		case reqErr.RequestBody != nil && reqErr.Err == nil:
+			// The only body error without a cause is an unexpected
+			// Content-Type.
+			fields = append(fields, apperror.FieldError{Field: "Content-Type", Code: "invalid", Message: "must be application/json"})
+		default:
+			fields = append(fields, parameterErrors(reqErr)...)
+		}
+	}
+	return apperror.Validation(fields...)
+}
+
+// parameterErrors describes the failures of a single parameter or of the
+// request body.
+func parameterErrors(reqErr *openapi3filter.RequestError) []apperror.FieldError {
+	field := "body"
+	if reqErr.Parameter != nil {
+		field = reqErr.Parameter.Name
+	}
+
+	if errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired) {
+		return []apperror.FieldError{{Field: field, Code: "required", Message: "is required"}}
+	}
+
+	var parseErr *openapi3filter.ParseError
+	if errors.As(reqErr.Err, &parseErr) && reqErr.Parameter != nil {
+		message := "has an invalid value"
+		if schema := reqErr.Parameter.Schema; schema != nil && schema.Value != nil && schema.Value.Type != nil {
+			message = "expected " + strings.Join(schema.Value.Type.Slice(), " or ")
+		}
+		return []apperror.FieldError{{Field: field, Code: "invalid_type", Message: message}}
+	}
+
+	var fields []apperror.FieldError
+	for _, err := range flatten(reqErr.Err) {
+		var schemaErr *openapi3.SchemaError
+		if !errors.As(err, &schemaErr) {
+			fields = append(fields, apperror.FieldError{Field: field, Code: "invalid", Message: reqErr.Reason})
+			continue
+		}
+
+		name := field
+		if reqErr.Parameter == nil {
+			name = fieldName(schemaErr.JSONPointer())
+		}
+		code, ok := fieldCodes[schemaErr.SchemaField]
+		if !ok {
+			code = "invalid"
+		}
+		message := schemaErr.Reason
+		if code == "required" {
+			message = "is required"
+		}
+		fields = append(fields, apperror.FieldError{Field: name, Code: code, Message: message})
+	}
+	return fields
+}
+
+// fieldName formats the JSON pointer of a body value the way request
+// binding names fields, e.g. headings[0].
+func fieldName(pointer []string) string {
+	if len(pointer) == 0 {
+		return "body"
+	}
+
+	var b strings.Builder
+	for i, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil && i > 0 {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// flatten returns the errors that a multi-error validation collected, or
+// err itself.
+func flatten(err error) []error {
+	// A type assertion rather than errors.As, which would also look
+	// inside the request error that wraps the schema errors of a body.
+	multi, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, err := range multi {
+		errs = append(errs, flatten(err)...)
+	}
+	return errs
+}
+
+// responseRecorder keeps a copy of the response body as it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,216 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+)
+
+const projectID = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+// newTestRouter serves a few documented routes with handlers that echo the
+// request body, so tests can check that validation leaves it readable.
+func newTestRouter(t *testing.T, validateResponses bool) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	doc, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load OpenAPI document: %v", err)
+	}
+
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusCreated, "application/json", body)
+	}
+
+	router := gin.New()
+	api := router.Group("/api", Validate(doc, validateResponses))
+	api.POST("/projects", echo)
+	api.GET("/projects", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"projects": []gin.H{}, "totalCount": 0, "pageSize": 20})
+	})
+	api.POST("/projects/merge", echo)
+	api.GET("/projects/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	api.PUT("/documents/:id", echo)
+	api.GET("/undocumented", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+type problemBody struct {
+	Code   string                `json:"code"`
+	Errors []apperror.FieldError `json:"errors"`
+}
+
+func TestValidateRequests(t *testing.T) {
+	router := newTestRouter(t, false)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		header     map[string]string
+		wantStatus int
+		wantCode   string
+		wantFields []apperror.FieldError
+	}{
+		{
+			name: "valid", method: http.MethodPost, path: "/api/projects",
+			body: `{"name":"Notes"}`, wantStatus: http.StatusCreated,
+		},
+		{
+			name: "missing field", method: http.MethodPost, path: "/api/projects",
+			body: `{}`, wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "name", Code: "required"}},
+		},
+		{
+			name: "too short", method: http.MethodPost, path: "/api/projects",
+			body: `{"name":""}`, wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "name", Code: "min"}},
+		},
+		{
+			name: "wrong type", method: http.MethodPost, path: "/api/projects",
+			body: `{"name":42}`, wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "name", Code: "invalid_type"}},
+		},
+		{
+			name: "array items", method: http.MethodPost, path: "/api/projects/merge",
+			body: `{"projectIds":["` + projectID + `","nope"]}`, wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "projectIds[1]", Code: "invalid"}},
+		},
+		{
+			name: "malformed body", method: http.MethodPost, path: "/api/projects",
+			body: `{"name":`, wantStatus: http.StatusBadRequest, wantCode: apperror.CodeMalformedBody,
+		},
+		{
+			name: "content type", method: http.MethodPost, path: "/api/projects",
+			body: `{"name":"Notes"}`, header: map[string]string{"Content-Type": "text/plain"},
+			wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "Content-Type", Code: "invalid"}},
+		},
+		{
+			name: "query enum", method: http.MethodGet, path: "/api/projects?sort=size",
+			wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "sort", Code: "oneof"}},
+		},
+		{
+			name: "query type", method: http.MethodGet, path: "/api/projects?page=first",
+			wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "page", Code: "invalid_type"}},
+		},
+		{
+			name: "path format", method: http.MethodGet, path: "/api/projects/not-a-uuid",
+			wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "id", Code: "invalid"}},
+		},
+		{
+			name: "default workspace", method: http.MethodGet, path: "/api/projects",
+			header:     map[string]string{"X-Workspace-ID": "00000000-0000-0000-0000-000000000001"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "missing header", method: http.MethodPut, path: "/api/documents/" + projectID,
+			body: `{"contentMd":"# Notes"}`, wantStatus: http.StatusBadRequest, wantCode: apperror.CodeValidationFailed,
+			wantFields: []apperror.FieldError{{Field: "X-Document-Version", Code: "required"}},
+		},
+		{
+			name: "undocumented", method: http.MethodGet, path: "/api/undocumented",
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantCode == "" {
+				if tt.body != "" && w.Body.String() != tt.body {
+					t.Errorf("Expected the handler to read the body %s, got %s", tt.body, w.Body.String())
+				}
+				return
+			}
+
+			var problem problemBody
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("Expected problem details, got %q", w.Body.String())
+			}
+			if problem.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, problem.Code)
+			}
+			if len(problem.Errors) != len(tt.wantFields) {
+				t.Fatalf("Expected field errors %+v, got %+v", tt.wantFields, problem.Errors)
+			}
+			for i, want := range tt.wantFields {
+				got := problem.Errors[i]
+				if got.Field != want.Field || got.Code != want.Code || got.Message == "" {
+					t.Errorf("Expected field error %+v, got %+v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateResponses(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for _, validateResponses := range []bool{false, true} {
+		logs.Reset()
+		router := newTestRouter(t, validateResponses)
+
+		// The project handler omits most required fields.
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/projects/"+projectID, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected the response to be sent regardless, got %d", w.Code)
+		}
+		if got := strings.Contains(logs.String(), "does not match"); got != validateResponses {
+			t.Errorf("With response validation %v, expected a logged mismatch to be %v: %q", validateResponses, validateResponses, logs.String())
+		}
+
+		// A matching response is not reported.
+		logs.Reset()
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/projects", nil))
+		if logs.Len() != 0 {
+			t.Errorf("Expected no mismatch for a valid response, got %q", logs.String())
+		}
+	}
+}
+
+func TestPathFromGin(t *testing.T) {
+	got := PathFromGin("/api/projects/:id/share-links/:linkId")
+	if want := "/api/projects/{id}/share-links/{linkId}"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type AuditRepository struct {
+	db *database.Postgres
+}
+
+func NewAuditRepository(db *database.Postgres) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (
+			occurred_at, workspace_id, actor_id, actor_name, action, target_type, target_id, project_id,
+			request_id, client_ip, user_agent, before_version, after_version, details
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+
+	details := event.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	return r.db.Querier(ctx).QueryRow(ctx, query,
+		event.OccurredAt, event.WorkspaceID, event.ActorID, event.ActorName, event.Action, event.TargetType,
+		event.TargetID, event.ProjectID, event.RequestID, event.ClientIP, event.UserAgent,
+		event.BeforeVersion, event.AfterVersion, details,
+	).Scan(&event.ID)
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	conditions := []string{"workspace_id = $1"}
+	args := []interface{}{filter.WorkspaceID}
+
+	addCondition := func(column string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, column+" $"+strconv.Itoa(len(args)))
+	}
+
+	if filter.ProjectID != nil {
+		addCondition("project_id =", *filter.ProjectID)
+	}
+	if filter.ActorName != "" {
+		addCondition("actor_name =", filter.ActorName)
+	}
+	if filter.Action != "" {
+		addCondition("action =", filter.Action)
+	}
+	if filter.Since != nil {
+		addCondition("occurred_at >=", *filter.Since)
+	}
+	if filter.BeforeID > 0 {
+		addCondition("id <", filter.BeforeID)
+	}
+
+	args = append(args, filter.Limit)
+	query := `
+		SELECT id, occurred_at, workspace_id, actor_id, actor_name, action, target_type, target_id, project_id,
+			request_id, client_ip, user_agent, before_version, after_version, details
+		FROM audit_events
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY id DESC
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var e models.AuditEvent
+		if err := rows.Scan(
+			&e.ID, &e.OccurredAt, &e.WorkspaceID, &e.ActorID, &e.ActorName, &e.Action, &e.TargetType,
+			&e.TargetID, &e.ProjectID, &e.RequestID, &e.ClientIP, &e.UserAgent,
+			&e.BeforeVersion, &e.AfterVersion, &e.Details,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
@@ -18,23 +18,29 @@ func NewDocumentRepository(db *database.Postgres) *DocumentRepository {
 	return &DocumentRepository{db: db}
 }
 
-func (r *DocumentRepository) Create(ctx context.Context, projectID uuid.UUID) (*models.Document, error) {
+func (r *DocumentRepository) Create(ctx context.Context, projectID uuid.UUID, contentMD string) (*models.Document, error) {
 	doc := &models.Document{
 		ID:        uuid.New(),
 		ProjectID: projectID,
-		ContentMD: "",
+		ContentMD: contentMD,
 		Version:   1,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	query := `
-		INSERT INTO documents (id, project_id, content_md, version, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, project_id, content_md, version, created_at, updated_at
+		WITH inserted AS (
+			INSERT INTO documents (id, project_id, content_md, version, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, project_id, content_md, version, created_at, updated_at
+		), revision AS (
+			INSERT INTO document_revisions (document_id, version, content_md, created_at)
+			SELECT id, version, content_md, updated_at FROM inserted
+		)
+		SELECT id, project_id, content_md, version, created_at, updated_at FROM inserted
 	`
 
-	err := r.db.Pool.QueryRow(ctx, query,
+	err := r.db.Querier(ctx).QueryRow(ctx, query,
 		doc.ID, doc.ProjectID, doc.ContentMD, doc.Version, doc.CreatedAt, doc.UpdatedAt,
 	).Scan(&doc.ID, &doc.ProjectID, &doc.ContentMD, &doc.Version, &doc.CreatedAt, &doc.UpdatedAt)
 
@@ -53,7 +59,7 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	`
 
 	doc := &models.Document{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Querier(ctx).QueryRow(ctx, query, id).Scan(
 		&doc.ID, &doc.ProjectID, &doc.ContentMD, &doc.Version, &doc.CreatedAt, &doc.UpdatedAt,
 	)
 
@@ -76,7 +82,34 @@ func (r *DocumentRepository) GetByProjectID(ctx context.Context, projectID uuid.
 	`
 
 	doc := &models.Document{}
-	err := r.db.Pool.QueryRow(ctx, query, projectID).Scan(
+	err := r.db.Querier(ctx).QueryRow(ctx, query, projectID).Scan(
+		&doc.ID, &doc.ProjectID, &doc.ContentMD, &doc.Version, &doc.CreatedAt, &doc.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// LockByProjectID returns the document of a live project like
+// GetByProjectID, locking the document and project rows until the
+// transaction of ctx ends.
+func (r *DocumentRepository) LockByProjectID(ctx context.Context, projectID uuid.UUID) (*models.Document, error) {
+	query := `
+		SELECT d.id, d.project_id, d.content_md, d.version, d.created_at, d.updated_at
+		FROM documents d
+		INNER JOIN projects p ON d.project_id = p.id
+		WHERE d.project_id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	doc := &models.Document{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, projectID).Scan(
 		&doc.ID, &doc.ProjectID, &doc.ContentMD, &doc.Version, &doc.CreatedAt, &doc.UpdatedAt,
 	)
 
@@ -92,14 +125,20 @@ func (r *DocumentRepository) GetByProjectID(ctx context.Context, projectID uuid.
 
 func (r *DocumentRepository) Update(ctx context.Context, id uuid.UUID, contentMD string, expectedVersion int) (*models.Document, error) {
 	query := `
-		UPDATE documents
-		SET content_md = $1, version = version + 1, updated_at = $2
-		WHERE id = $3 AND version = $4
-		RETURNING id, project_id, content_md, version, created_at, updated_at
+		WITH updated AS (
+			UPDATE documents
+			SET content_md = $1, version = version + 1, updated_at = $2
+			WHERE id = $3 AND version = $4
+			RETURNING id, project_id, content_md, version, created_at, updated_at
+		), revision AS (
+			INSERT INTO document_revisions (document_id, version, content_md, created_at)
+			SELECT id, version, content_md, updated_at FROM updated
+		)
+		SELECT id, project_id, content_md, version, created_at, updated_at FROM updated
 	`
 
 	doc := &models.Document{}
-	err := r.db.Pool.QueryRow(ctx, query, contentMD, time.Now(), id, expectedVersion).Scan(
+	err := r.db.Querier(ctx).QueryRow(ctx, query, contentMD, time.Now(), id, expectedVersion).Scan(
 		&doc.ID, &doc.ProjectID, &doc.ContentMD, &doc.Version, &doc.CreatedAt, &doc.UpdatedAt,
 	)
 
@@ -112,3 +151,113 @@ func (r *DocumentRepository) Update(ctx context.Context, id uuid.UUID, contentMD
 
 	return doc, nil
 }
+
+func (r *DocumentRepository) GetRevision(ctx context.Context, documentID uuid.UUID, version int) (*models.DocumentRevision, error) {
+	query := `
+		SELECT document_id, version, content_md, created_at
+		FROM document_revisions
+		WHERE document_id = $1 AND version = $2
+	`
+
+	rev := &models.DocumentRevision{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, documentID, version).Scan(
+		&rev.DocumentID, &rev.Version, &rev.ContentMD, &rev.CreatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+// ListSummaries returns the documents of the given projects keyed by project.
+// Unless fullContent is set only the start of each document is loaded.
+func (r *DocumentRepository) ListSummaries(ctx context.Context, projectIDs []uuid.UUID, fullContent bool) (map[uuid.UUID]models.DocumentSummary, error) {
+	content := "left(d.content_md, 4096)"
+	if fullContent {
+		content = "d.content_md"
+	}
+
+	query := `
+		SELECT d.project_id, ` + content + `, d.version, d.updated_at, editor.actor_name
+		FROM documents d
+		LEFT JOIN LATERAL (
+			SELECT actor_name FROM audit_events a
+			WHERE a.project_id = d.project_id AND a.action = $2
+			ORDER BY a.id DESC
+			LIMIT 1
+		) editor ON true
+		WHERE d.project_id = ANY($1)
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, projectIDs, models.AuditActionDocumentUpdate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make(map[uuid.UUID]models.DocumentSummary, len(projectIDs))
+	for rows.Next() {
+		var s models.DocumentSummary
+		if err := rows.Scan(&s.ProjectID, &s.ContentMD, &s.Version, &s.UpdatedAt, &s.LastEditor); err != nil {
+			return nil, err
+		}
+		summaries[s.ProjectID] = s
+	}
+
+	return summaries, rows.Err()
+}
+
+func (r *DocumentRepository) EnqueueCommit(ctx context.Context, documentID uuid.UUID, version int, author string) error {
+	query := `
+		INSERT INTO document_commits (document_id, version, author, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.Querier(ctx).Exec(ctx, query, documentID, version, author, time.Now())
+	return err
+}
+
+// ListPendingCommits returns the queued versions with the content they were
+// saved with and the current name of their project.
+func (r *DocumentRepository) ListPendingCommits(ctx context.Context, limit int) ([]models.PendingDocumentCommit, error) {
+	query := `
+		SELECT c.id, p.id, p.workspace_id, p.name, d.id, d.project_id, rev.content_md, rev.version, d.created_at, rev.created_at, c.author
+		FROM document_commits c
+		INNER JOIN documents d ON d.id = c.document_id
+		INNER JOIN projects p ON p.id = d.project_id
+		INNER JOIN document_revisions rev ON rev.document_id = c.document_id AND rev.version = c.version
+		ORDER BY c.id
+		LIMIT $1
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commits []models.PendingDocumentCommit
+	for rows.Next() {
+		var c models.PendingDocumentCommit
+		if err := rows.Scan(
+			&c.ID, &c.Project.ID, &c.Project.WorkspaceID, &c.Project.Name,
+			&c.Document.ID, &c.Document.ProjectID, &c.Document.ContentMD, &c.Document.Version,
+			&c.Document.CreatedAt, &c.Document.UpdatedAt, &c.Author,
+		); err != nil {
+			return nil, err
+		}
+		commits = append(commits, c)
+	}
+
+	return commits, rows.Err()
+}
+
+func (r *DocumentRepository) DeleteCommit(ctx context.Context, id int64) error {
+	_, err := r.db.Querier(ctx).Exec(ctx, `DELETE FROM document_commits WHERE id = $1`, id)
+	return err
+}
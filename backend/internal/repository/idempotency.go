@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type IdempotencyRepository struct {
+	db *database.Postgres
+}
+
+func NewIdempotencyRepository(db *database.Postgres) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim reserves key for a new request and reports whether it succeeded. A
+// key can be claimed if it is unused, expired, or still in progress since
+// before staleBefore, which means the request that claimed it never finished.
+func (r *IdempotencyRepository) Claim(ctx context.Context, scope, key, requestHash string, expiredBefore, staleBefore time.Time) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, key, request_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_headers = NULL,
+			response_body = NULL, created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.created_at < $5
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < $6)
+		RETURNING key
+	`
+
+	var claimed string
+	err := r.db.Querier(ctx).QueryRow(ctx, query, scope, key, requestHash, time.Now(), expiredBefore, staleBefore).Scan(&claimed)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, scope, key string) (*models.IdempotentResponse, error) {
+	query := `
+		SELECT request_hash, COALESCE(status_code, 0), COALESCE(response_headers, '{}'), COALESCE(response_body, '')
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2
+	`
+
+	resp := &models.IdempotentResponse{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, scope, key).Scan(&resp.RequestHash, &resp.StatusCode, &resp.Headers, &resp.Body)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, resp models.IdempotentResponse) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_headers = $2, response_body = $3
+		WHERE scope = $4 AND key = $5 AND request_hash = $6
+	`
+
+	_, err := r.db.Querier(ctx).Exec(ctx, query, resp.StatusCode, resp.Headers, resp.Body, scope, key, resp.RequestHash)
+	return err
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	_, err := r.db.Querier(ctx).Exec(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`, scope, key)
+	return err
+}
+
+func (r *IdempotencyRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.Querier(ctx).Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,23 +21,31 @@ func NewProjectRepository(db *database.Postgres) *ProjectRepository {
 	return &ProjectRepository{db: db}
 }
 
-func (r *ProjectRepository) Create(ctx context.Context, name string) (*models.Project, error) {
+// projectColumns are the columns scanned by projectFields.
+const projectColumns = `id, workspace_id, name, created_at, updated_at, archived_at, pinned_at`
+
+func projectFields(p *models.Project) []interface{} {
+	return []interface{}{&p.ID, &p.WorkspaceID, &p.Name, &p.CreatedAt, &p.UpdatedAt, &p.ArchivedAt, &p.PinnedAt}
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Project, error) {
 	project := &models.Project{
-		ID:        uuid.New(),
-		Name:      name,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Name:        name,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
 	query := `
-		INSERT INTO projects (id, name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, name, created_at, updated_at
+		INSERT INTO projects (id, workspace_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + projectColumns + `
 	`
 
-	err := r.db.Pool.QueryRow(ctx, query,
-		project.ID, project.Name, project.CreatedAt, project.UpdatedAt,
-	).Scan(&project.ID, &project.Name, &project.CreatedAt, &project.UpdatedAt)
+	err := r.db.Querier(ctx).QueryRow(ctx, query,
+		project.ID, project.WorkspaceID, project.Name, project.CreatedAt, project.UpdatedAt,
+	).Scan(projectFields(project)...)
 
 	if err != nil {
 		return nil, err
@@ -43,17 +54,17 @@ func (r *ProjectRepository) Create(ctx context.Context, name string) (*models.Pr
 	return project, nil
 }
 
+// GetByID looks a project up regardless of its workspace; callers acting on
+// behalf of a client must compare the returned WorkspaceID themselves.
 func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
 	query := `
-		SELECT id, name, created_at, updated_at, deleted_at
+		SELECT ` + projectColumns + `, deleted_at
 		FROM projects
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	project := &models.Project{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&project.ID, &project.Name, &project.CreatedAt, &project.UpdatedAt, &project.DeletedAt,
-	)
+	err := r.db.Querier(ctx).QueryRow(ctx, query, id).Scan(append(projectFields(project), &project.DeletedAt)...)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -65,57 +76,191 @@ func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 	return project, nil
 }
 
-func (r *ProjectRepository) List(ctx context.Context, page, pageSize int) ([]models.Project, int, error) {
-	offset := (page - 1) * pageSize
+// projectSortColumns maps the public sort keys onto columns; anything else
+// is rejected before it reaches SQL.
+var projectSortColumns = map[string]string{
+	models.ProjectSortCreatedAt: "created_at",
+	models.ProjectSortUpdatedAt: "updated_at",
+	models.ProjectSortName:      "name",
+}
+
+// List returns up to limit projects matching q. Pinned projects come first,
+// then the rest ordered by q.Sort with the id as tie-breaker so that keyset
+// pages stay stable while projects are added. The total count ignores the
+// cursor.
+func (r *ProjectRepository) List(ctx context.Context, q models.ProjectListQuery, limit int) ([]models.Project, int, error) {
+	column, ok := projectSortColumns[q.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown sort %q", q.Sort)
+	}
+
+	conditions := []string{"workspace_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{q.WorkspaceID}
+
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if q.Search != "" {
+		pattern := addArg("%" + escapeLike(q.Search) + "%")
+		conditions = append(conditions, "(name ILIKE "+pattern+" OR name % "+addArg(q.Search)+")")
+	}
+	if q.UpdatedSince != nil {
+		conditions = append(conditions, "updated_at >= "+addArg(*q.UpdatedSince))
+	}
+	switch q.Archived {
+	case models.ProjectArchivedOnly:
+		conditions = append(conditions, "archived_at IS NOT NULL")
+	case models.ProjectArchivedInclude:
+	default:
+		conditions = append(conditions, "archived_at IS NULL")
+	}
+	if q.PinnedOnly {
+		conditions = append(conditions, "pinned_at IS NOT NULL")
+	}
+
+	favourite := "FALSE"
+	if q.UserID != nil {
+		favourite = "EXISTS (SELECT 1 FROM project_favourites f WHERE f.project_id = projects.id AND f.user_id = " + addArg(*q.UserID) + ")"
+		if q.FavouritesOnly {
+			conditions = append(conditions, favourite)
+		}
+	}
 
-	countQuery := `SELECT COUNT(*) FROM projects WHERE deleted_at IS NULL`
+	countQuery := `SELECT COUNT(*) FROM projects WHERE ` + strings.Join(conditions, " AND ")
 	var totalCount int
-	if err := r.db.Pool.QueryRow(ctx, countQuery).Scan(&totalCount); err != nil {
+	if err := r.db.Querier(ctx).QueryRow(ctx, countQuery, args...).Scan(&totalCount); err != nil {
 		return nil, 0, err
 	}
 
+	// The pinned key sorts pinned projects first in either direction.
+	direction, comparison, pinned := "ASC", ">", "(pinned_at IS NULL)"
+	if q.Descending {
+		direction, comparison, pinned = "DESC", "<", "(pinned_at IS NOT NULL)"
+	}
+
+	offset := 0
+	if q.After != nil {
+		var value interface{} = q.After.Value
+		if column != "name" {
+			t, err := time.Parse(time.RFC3339Nano, q.After.Value)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid cursor value: %w", err)
+			}
+			value = t
+		}
+		afterPinned := q.After.Pinned == q.Descending
+		conditions = append(conditions, "("+pinned+", "+column+", id) "+comparison+" ("+addArg(afterPinned)+", "+addArg(value)+", "+addArg(q.After.ID)+")")
+	} else {
+		offset = (q.Page - 1) * q.PageSize
+	}
+
 	query := `
-		SELECT id, name, created_at, updated_at
+		SELECT ` + projectColumns + `, ` + favourite + `
 		FROM projects
-		WHERE deleted_at IS NULL
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + pinned + ` ` + direction + `, ` + column + ` ` + direction + `, id ` + direction + `
+		LIMIT ` + addArg(limit) + ` OFFSET ` + addArg(offset)
 
-	rows, err := r.db.Pool.Query(ctx, query, pageSize, offset)
+	rows, err := r.db.Querier(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var projects []models.Project
+	projects := []models.Project{}
 	for rows.Next() {
 		var p models.Project
-		if err := rows.Scan(&p.ID, &p.Name, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(append(projectFields(&p), &p.Favourite)...); err != nil {
 			return nil, 0, err
 		}
 		projects = append(projects, p)
 	}
 
-	if projects == nil {
-		projects = []models.Project{}
+	return projects, totalCount, rows.Err()
+}
+
+// Lookup returns live, unarchived projects whose name equals name
+// case-insensitively or is trigram-similar to it, exact matches first and
+// then by similarity.
+func (r *ProjectRepository) Lookup(ctx context.Context, workspaceID uuid.UUID, name string, limit int) ([]models.ProjectMatch, error) {
+	query := `
+		SELECT ` + projectColumns + `,
+			similarity(name, $2) AS score, lower(name) = lower($2) AS exact
+		FROM projects
+		WHERE workspace_id = $1 AND deleted_at IS NULL AND archived_at IS NULL
+			AND (lower(name) = lower($2) OR name % $2)
+		ORDER BY exact DESC, score DESC, name
+		LIMIT $3
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, workspaceID, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.ProjectMatch{}
+	for rows.Next() {
+		var m models.ProjectMatch
+		p := &m.Project
+		if err := rows.Scan(append(projectFields(p), &m.Score, &m.Exact)...); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
+
+// LockName holds a lock on name within the workspace until the transaction
+// of ctx ends, so that concurrent transactions looking a project up by name
+// before creating it do not both create it.
+func (r *ProjectRepository) LockName(ctx context.Context, workspaceID uuid.UUID, name string) error {
+	lockKey := workspaceID.String() + "/" + strings.ToLower(name)
+	_, err := r.db.Querier(ctx).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, lockKey)
+	return err
+}
+
+// GetByName returns the oldest live project named name, compared
+// case-insensitively.
+func (r *ProjectRepository) GetByName(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Project, error) {
+	query := `
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE workspace_id = $1 AND deleted_at IS NULL AND lower(name) = lower($2)
+		ORDER BY created_at
+		LIMIT 1
+	`
+
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, workspaceID, name).Scan(projectFields(project)...)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return projects, totalCount, nil
+	return project, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
-func (r *ProjectRepository) Update(ctx context.Context, id uuid.UUID, name string) (*models.Project, error) {
+func (r *ProjectRepository) Update(ctx context.Context, workspaceID, id uuid.UUID, name string) (*models.Project, error) {
 	query := `
 		UPDATE projects
 		SET name = $1, updated_at = $2
-		WHERE id = $3 AND deleted_at IS NULL
-		RETURNING id, name, created_at, updated_at
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+		RETURNING ` + projectColumns + `
 	`
 
 	project := &models.Project{}
-	err := r.db.Pool.QueryRow(ctx, query, name, time.Now(), id).Scan(
-		&project.ID, &project.Name, &project.CreatedAt, &project.UpdatedAt,
-	)
+	err := r.db.Querier(ctx).QueryRow(ctx, query, name, time.Now(), id, workspaceID).Scan(projectFields(project)...)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -127,14 +272,110 @@ func (r *ProjectRepository) Update(ctx context.Context, id uuid.UUID, name strin
 	return project, nil
 }
 
-func (r *ProjectRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+func (r *ProjectRepository) Transfer(ctx context.Context, fromWorkspaceID, id, toWorkspaceID uuid.UUID) (*models.Project, error) {
+	query := `
+		UPDATE projects
+		SET workspace_id = $1, updated_at = $2
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+		RETURNING ` + projectColumns + `
+	`
+
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, toWorkspaceID, time.Now(), id, fromWorkspaceID).Scan(projectFields(project)...)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// SetArchived archives or unarchives a live project. Archiving an archived
+// project keeps its original archive time.
+func (r *ProjectRepository) SetArchived(ctx context.Context, workspaceID, id uuid.UUID, archived bool) (*models.Project, error) {
+	return r.setState(ctx, workspaceID, id, "archived_at", archived)
+}
+
+// SetPinned pins or unpins a live project.
+func (r *ProjectRepository) SetPinned(ctx context.Context, workspaceID, id uuid.UUID, pinned bool) (*models.Project, error) {
+	return r.setState(ctx, workspaceID, id, "pinned_at", pinned)
+}
+
+// setState sets or clears one of the timestamp columns marking a project
+// state. column is never user input.
+func (r *ProjectRepository) setState(ctx context.Context, workspaceID, id uuid.UUID, column string, on bool) (*models.Project, error) {
+	query := `
+		UPDATE projects
+		SET ` + column + ` = CASE WHEN $1 THEN COALESCE(` + column + `, $2) END
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+		RETURNING ` + projectColumns + `
+	`
+
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, on, time.Now(), id, workspaceID).Scan(projectFields(project)...)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// AddFavourite marks a project as a favourite of a user. It is a no-op if it
+// already is one.
+func (r *ProjectRepository) AddFavourite(ctx context.Context, userID, projectID uuid.UUID) error {
+	query := `
+		INSERT INTO project_favourites (user_id, project_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, project_id) DO NOTHING
+	`
+
+	_, err := r.db.Querier(ctx).Exec(ctx, query, userID, projectID, time.Now())
+	return err
+}
+
+func (r *ProjectRepository) RemoveFavourite(ctx context.Context, userID, projectID uuid.UUID) error {
+	query := `DELETE FROM project_favourites WHERE user_id = $1 AND project_id = $2`
+
+	_, err := r.db.Querier(ctx).Exec(ctx, query, userID, projectID)
+	return err
+}
+
+func (r *ProjectRepository) SoftDelete(ctx context.Context, workspaceID, id uuid.UUID) error {
 	query := `
 		UPDATE projects
 		SET deleted_at = $1, updated_at = $1
-		WHERE id = $2 AND deleted_at IS NULL
+		WHERE id = $2 AND workspace_id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Querier(ctx).Exec(ctx, query, time.Now(), id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// MarkMerged soft-deletes a project whose content has moved into another
+// one, leaving a redirect to it.
+func (r *ProjectRepository) MarkMerged(ctx context.Context, workspaceID, id, into uuid.UUID) error {
+	query := `
+		UPDATE projects
+		SET deleted_at = $1, updated_at = $1, redirect_to = $2
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.Pool.Exec(ctx, query, time.Now(), id)
+	result, err := r.db.Querier(ctx).Exec(ctx, query, time.Now(), into, id, workspaceID)
 	if err != nil {
 		return err
 	}
@@ -145,3 +386,112 @@ func (r *ProjectRepository) SoftDelete(ctx context.Context, id uuid.UUID) error
 
 	return nil
 }
+
+// GetRedirect returns the project a deleted project was merged into, or nil.
+func (r *ProjectRepository) GetRedirect(ctx context.Context, workspaceID, id uuid.UUID) (*uuid.UUID, error) {
+	query := `
+		SELECT redirect_to
+		FROM projects
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NOT NULL AND redirect_to IS NOT NULL
+	`
+
+	var to uuid.UUID
+	err := r.db.Querier(ctx).QueryRow(ctx, query, id, workspaceID).Scan(&to)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &to, nil
+}
+
+func (r *ProjectRepository) ListDeleted(ctx context.Context, workspaceID uuid.UUID, page, pageSize int) ([]models.TrashedProject, int, error) {
+	offset := (page - 1) * pageSize
+
+	countQuery := `SELECT COUNT(*) FROM projects WHERE workspace_id = $1 AND deleted_at IS NOT NULL`
+	var totalCount int
+	if err := r.db.Querier(ctx).QueryRow(ctx, countQuery, workspaceID).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, workspace_id, name, created_at, updated_at, deleted_at
+		FROM projects
+		WHERE workspace_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, workspaceID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	projects := []models.TrashedProject{}
+	for rows.Next() {
+		var p models.TrashedProject
+		if err := rows.Scan(&p.ID, &p.WorkspaceID, &p.Name, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt); err != nil {
+			return nil, 0, err
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, totalCount, rows.Err()
+}
+
+func (r *ProjectRepository) Restore(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, error) {
+	query := `
+		UPDATE projects
+		SET deleted_at = NULL, redirect_to = NULL, updated_at = $1
+		WHERE id = $2 AND workspace_id = $3 AND deleted_at IS NOT NULL
+		RETURNING ` + projectColumns + `
+	`
+
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, time.Now(), id, workspaceID).Scan(projectFields(project)...)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// HardDelete permanently removes a soft-deleted project. Its document,
+// revisions and share links go with it through ON DELETE CASCADE.
+func (r *ProjectRepository) HardDelete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `
+		DELETE FROM projects
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NOT NULL
+	`
+
+	result, err := r.db.Querier(ctx).Exec(ctx, query, id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore permanently removes every project soft-deleted before
+// cutoff, across all workspaces, and returns how many were removed.
+func (r *ProjectRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `DELETE FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.db.Querier(ctx).Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
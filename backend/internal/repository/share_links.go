@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type ShareLinkRepository struct {
+	db *database.Postgres
+}
+
+func NewShareLinkRepository(db *database.Postgres) *ShareLinkRepository {
+	return &ShareLinkRepository{db: db}
+}
+
+const shareLinkColumns = `id, project_id, token, password_hash, pinned_version, expires_at, revoked_at, created_at`
+
+func scanShareLink(row pgx.Row) (*models.ShareLink, error) {
+	link := &models.ShareLink{}
+	err := row.Scan(
+		&link.ID, &link.ProjectID, &link.Token, &link.PasswordHash,
+		&link.PinnedVersion, &link.ExpiresAt, &link.RevokedAt, &link.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	link.HasPassword = link.PasswordHash != nil
+	return link, nil
+}
+
+func (r *ShareLinkRepository) Create(ctx context.Context, link *models.ShareLink) (*models.ShareLink, error) {
+	query := `
+		INSERT INTO share_links (id, project_id, token, password_hash, pinned_version, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + shareLinkColumns
+
+	return scanShareLink(r.db.Querier(ctx).QueryRow(ctx, query,
+		uuid.New(), link.ProjectID, link.Token, link.PasswordHash, link.PinnedVersion, link.ExpiresAt, time.Now(),
+	))
+}
+
+func (r *ShareLinkRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.ShareLink, error) {
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE project_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+// GetActiveByToken returns the link for token if it has not been revoked and
+// its project still exists. Expiry is left to the caller.
+func (r *ShareLinkRepository) GetActiveByToken(ctx context.Context, token string) (*models.ShareLink, error) {
+	query := `
+		SELECT s.id, s.project_id, s.token, s.password_hash, s.pinned_version, s.expires_at, s.revoked_at, s.created_at
+		FROM share_links s
+		INNER JOIN projects p ON s.project_id = p.id
+		WHERE s.token = $1 AND s.revoked_at IS NULL AND p.deleted_at IS NULL
+	`
+
+	link, err := scanShareLink(r.db.Querier(ctx).QueryRow(ctx, query, token))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (r *ShareLinkRepository) Revoke(ctx context.Context, projectID, id uuid.UUID) error {
+	query := `
+		UPDATE share_links
+		SET revoked_at = $1
+		WHERE id = $2 AND project_id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Querier(ctx).Exec(ctx, query, time.Now(), id, projectID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
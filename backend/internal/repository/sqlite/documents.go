@@ -0,0 +1,255 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type DocumentRepository struct {
+	db *database.SQLite
+}
+
+func NewDocumentRepository(db *database.SQLite) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+const documentColumns = `id, project_id, content_md, version, created_at, updated_at`
+
+func documentFields(d *models.Document) []interface{} {
+	return []interface{}{&d.ID, &d.ProjectID, &d.ContentMD, &d.Version, &d.CreatedAt, &d.UpdatedAt}
+}
+
+func (r *DocumentRepository) Create(ctx context.Context, projectID uuid.UUID, contentMD string) (*models.Document, error) {
+	var doc *models.Document
+	err := r.db.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		doc, err = createDocument(ctx, r.db.Querier(ctx), projectID, contentMD, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// createDocument inserts a document and its first revision. q must be a
+// transaction.
+func createDocument(ctx context.Context, q database.SQLQuerier, projectID uuid.UUID, contentMD string, now time.Time) (*models.Document, error) {
+	query := `
+		INSERT INTO documents (id, project_id, content_md, version, created_at, updated_at)
+		VALUES ($1, $2, $3, 1, $4, $4)
+		RETURNING ` + documentColumns
+
+	doc := &models.Document{}
+	if err := q.QueryRowContext(ctx, query, uuid.New(), projectID, contentMD, now).Scan(documentFields(doc)...); err != nil {
+		return nil, err
+	}
+	if err := insertRevision(ctx, q, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func insertRevision(ctx context.Context, q database.SQLQuerier, doc *models.Document) error {
+	query := `
+		INSERT INTO document_revisions (document_id, version, content_md, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := q.ExecContext(ctx, query, doc.ID, doc.Version, doc.ContentMD, doc.UpdatedAt)
+	return err
+}
+
+func (r *DocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM documents WHERE id = $1`
+
+	return r.getDocument(ctx, query, id)
+}
+
+func (r *DocumentRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) (*models.Document, error) {
+	query := `
+		SELECT d.id, d.project_id, d.content_md, d.version, d.created_at, d.updated_at
+		FROM documents d
+		INNER JOIN projects p ON d.project_id = p.id
+		WHERE d.project_id = $1 AND p.deleted_at IS NULL
+	`
+
+	return r.getDocument(ctx, query, projectID)
+}
+
+// LockByProjectID needs no lock: the single connection already serializes
+// transactions.
+func (r *DocumentRepository) LockByProjectID(ctx context.Context, projectID uuid.UUID) (*models.Document, error) {
+	return r.GetByProjectID(ctx, projectID)
+}
+
+func (r *DocumentRepository) getDocument(ctx context.Context, query string, args ...interface{}) (*models.Document, error) {
+	doc := &models.Document{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, args...).Scan(documentFields(doc)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (r *DocumentRepository) Update(ctx context.Context, id uuid.UUID, contentMD string, expectedVersion int) (*models.Document, error) {
+	query := `
+		UPDATE documents
+		SET content_md = $1, version = version + 1, updated_at = $2
+		WHERE id = $3 AND version = $4
+		RETURNING ` + documentColumns
+
+	var doc *models.Document
+	err := r.db.InTx(ctx, func(ctx context.Context) error {
+		q := r.db.Querier(ctx)
+
+		updated := &models.Document{}
+		err := q.QueryRowContext(ctx, query, contentMD, time.Now(), id, expectedVersion).Scan(documentFields(updated)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		doc = updated
+		return insertRevision(ctx, q, doc)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (r *DocumentRepository) GetRevision(ctx context.Context, documentID uuid.UUID, version int) (*models.DocumentRevision, error) {
+	query := `
+		SELECT document_id, version, content_md, created_at
+		FROM document_revisions
+		WHERE document_id = $1 AND version = $2
+	`
+
+	rev := &models.DocumentRevision{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, documentID, version).Scan(
+		&rev.DocumentID, &rev.Version, &rev.ContentMD, &rev.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+// ListSummaries returns the documents of the given projects keyed by project.
+// Unless fullContent is set only the start of each document is loaded.
+func (r *DocumentRepository) ListSummaries(ctx context.Context, projectIDs []uuid.UUID, fullContent bool) (map[uuid.UUID]models.DocumentSummary, error) {
+	summaries := make(map[uuid.UUID]models.DocumentSummary, len(projectIDs))
+	if len(projectIDs) == 0 {
+		return summaries, nil
+	}
+
+	content := "substr(d.content_md, 1, 4096)"
+	if fullContent {
+		content = "d.content_md"
+	}
+
+	args := []interface{}{models.AuditActionDocumentUpdate}
+	placeholders := make([]string, len(projectIDs))
+	for i, id := range projectIDs {
+		args = append(args, id)
+		placeholders[i] = placeholder(len(args))
+	}
+
+	query := `
+		SELECT d.project_id, ` + content + `, d.version, d.updated_at, (
+			SELECT actor_name FROM audit_events a
+			WHERE a.project_id = d.project_id AND a.action = $1
+			ORDER BY a.id DESC
+			LIMIT 1
+		)
+		FROM documents d
+		WHERE d.project_id IN (` + strings.Join(placeholders, ", ") + `)
+	`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.DocumentSummary
+		if err := rows.Scan(&s.ProjectID, &s.ContentMD, &s.Version, &s.UpdatedAt, &s.LastEditor); err != nil {
+			return nil, err
+		}
+		summaries[s.ProjectID] = s
+	}
+
+	return summaries, rows.Err()
+}
+
+func (r *DocumentRepository) EnqueueCommit(ctx context.Context, documentID uuid.UUID, version int, author string) error {
+	query := `
+		INSERT INTO document_commits (document_id, version, author, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.Querier(ctx).ExecContext(ctx, query, documentID, version, author, time.Now())
+	return err
+}
+
+// ListPendingCommits returns the queued versions with the content they were
+// saved with and the current name of their project.
+func (r *DocumentRepository) ListPendingCommits(ctx context.Context, limit int) ([]models.PendingDocumentCommit, error) {
+	query := `
+		SELECT c.id, p.id, p.workspace_id, p.name, d.id, d.project_id, rev.content_md, rev.version, d.created_at, rev.created_at, c.author
+		FROM document_commits c
+		INNER JOIN documents d ON d.id = c.document_id
+		INNER JOIN projects p ON p.id = d.project_id
+		INNER JOIN document_revisions rev ON rev.document_id = c.document_id AND rev.version = c.version
+		ORDER BY c.id
+		LIMIT $1
+	`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commits []models.PendingDocumentCommit
+	for rows.Next() {
+		var c models.PendingDocumentCommit
+		if err := rows.Scan(
+			&c.ID, &c.Project.ID, &c.Project.WorkspaceID, &c.Project.Name,
+			&c.Document.ID, &c.Document.ProjectID, &c.Document.ContentMD, &c.Document.Version,
+			&c.Document.CreatedAt, &c.Document.UpdatedAt, &c.Author,
+		); err != nil {
+			return nil, err
+		}
+		commits = append(commits, c)
+	}
+
+	return commits, rows.Err()
+}
+
+func (r *DocumentRepository) DeleteCommit(ctx context.Context, id int64) error {
+	_, err := r.db.Querier(ctx).ExecContext(ctx, `DELETE FROM document_commits WHERE id = $1`, id)
+	return err
+}
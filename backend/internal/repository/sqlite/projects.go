@@ -0,0 +1,391 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type ProjectRepository struct {
+	db *database.SQLite
+}
+
+func NewProjectRepository(db *database.SQLite) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+const projectColumns = `id, workspace_id, name, created_at, updated_at, archived_at, pinned_at`
+
+func projectFields(p *models.Project) []interface{} {
+	return []interface{}{&p.ID, &p.WorkspaceID, &p.Name, &p.CreatedAt, &p.UpdatedAt, &p.ArchivedAt, &p.PinnedAt}
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Project, error) {
+	query := `
+		INSERT INTO projects (id, workspace_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING ` + projectColumns
+
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, uuid.New(), workspaceID, name, time.Now()).Scan(projectFields(project)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	query := `SELECT ` + projectColumns + `, deleted_at FROM projects WHERE id = $1 AND deleted_at IS NULL`
+
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, id).Scan(append(projectFields(project), &project.DeletedAt)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+var projectSortColumns = map[string]string{
+	models.ProjectSortCreatedAt: "created_at",
+	models.ProjectSortUpdatedAt: "updated_at",
+	models.ProjectSortName:      "name",
+}
+
+// List mirrors the Postgres repository, with the similarity function
+// registered by the database package standing in for pg_trgm.
+func (r *ProjectRepository) List(ctx context.Context, q models.ProjectListQuery, limit int) ([]models.Project, int, error) {
+	column, ok := projectSortColumns[q.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown sort %q", q.Sort)
+	}
+
+	conditions := []string{"workspace_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{q.WorkspaceID}
+
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return placeholder(len(args))
+	}
+
+	if q.Search != "" {
+		pattern := addArg("%" + escapeLike(q.Search) + "%")
+		threshold := strconv.FormatFloat(database.SQLiteSimilarityThreshold, 'f', -1, 64)
+		conditions = append(conditions, "(name LIKE "+pattern+` ESCAPE '\' OR similarity(name, `+addArg(q.Search)+") >= "+threshold+")")
+	}
+	if q.UpdatedSince != nil {
+		conditions = append(conditions, "updated_at >= "+addArg(*q.UpdatedSince))
+	}
+	switch q.Archived {
+	case models.ProjectArchivedOnly:
+		conditions = append(conditions, "archived_at IS NOT NULL")
+	case models.ProjectArchivedInclude:
+	default:
+		conditions = append(conditions, "archived_at IS NULL")
+	}
+	if q.PinnedOnly {
+		conditions = append(conditions, "pinned_at IS NOT NULL")
+	}
+
+	favourite := "FALSE"
+	if q.UserID != nil {
+		favourite = "EXISTS (SELECT 1 FROM project_favourites f WHERE f.project_id = projects.id AND f.user_id = " + addArg(*q.UserID) + ")"
+		if q.FavouritesOnly {
+			conditions = append(conditions, favourite)
+		}
+	}
+
+	countQuery := `SELECT COUNT(*) FROM projects WHERE ` + strings.Join(conditions, " AND ")
+	var totalCount int
+	if err := r.db.Querier(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	direction, comparison, pinned := "ASC", ">", "(pinned_at IS NULL)"
+	if q.Descending {
+		direction, comparison, pinned = "DESC", "<", "(pinned_at IS NOT NULL)"
+	}
+
+	offset := 0
+	if q.After != nil {
+		var value interface{} = q.After.Value
+		if column != "name" {
+			t, err := time.Parse(time.RFC3339Nano, q.After.Value)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid cursor value: %w", err)
+			}
+			value = t
+		}
+		afterPinned := q.After.Pinned == q.Descending
+		conditions = append(conditions, "("+pinned+", "+column+", id) "+comparison+" ("+addArg(afterPinned)+", "+addArg(value)+", "+addArg(q.After.ID)+")")
+	} else {
+		offset = (q.Page - 1) * q.PageSize
+	}
+
+	query := `
+		SELECT ` + projectColumns + `, ` + favourite + `
+		FROM projects
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + pinned + ` ` + direction + `, ` + column + ` ` + direction + `, id ` + direction + `
+		LIMIT ` + addArg(limit) + ` OFFSET ` + addArg(offset)
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		var p models.Project
+		if err := rows.Scan(append(projectFields(&p), &p.Favourite)...); err != nil {
+			return nil, 0, err
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, totalCount, rows.Err()
+}
+
+func (r *ProjectRepository) Lookup(ctx context.Context, workspaceID uuid.UUID, name string, limit int) ([]models.ProjectMatch, error) {
+	query := `
+		SELECT ` + projectColumns + `, score, exact
+		FROM (
+			SELECT *, similarity(name, $2) AS score, lower(name) = lower($2) AS exact
+			FROM projects
+			WHERE workspace_id = $1 AND deleted_at IS NULL AND archived_at IS NULL
+		)
+		WHERE exact OR score >= $4
+		ORDER BY exact DESC, score DESC, name
+		LIMIT $3
+	`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, workspaceID, name, limit, database.SQLiteSimilarityThreshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.ProjectMatch{}
+	for rows.Next() {
+		var m models.ProjectMatch
+		if err := rows.Scan(append(projectFields(&m.Project), &m.Score, &m.Exact)...); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
+
+// LockName does nothing: the single connection already serializes
+// transactions.
+func (r *ProjectRepository) LockName(ctx context.Context, workspaceID uuid.UUID, name string) error {
+	return nil
+}
+
+func (r *ProjectRepository) GetByName(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Project, error) {
+	query := `
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE workspace_id = $1 AND deleted_at IS NULL AND lower(name) = lower($2)
+		ORDER BY created_at
+		LIMIT 1
+	`
+
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, workspaceID, name).Scan(projectFields(project)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, workspaceID, id uuid.UUID, name string) (*models.Project, error) {
+	query := `
+		UPDATE projects
+		SET name = $1, updated_at = $2
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+		RETURNING ` + projectColumns
+
+	return r.updateProject(ctx, query, name, time.Now(), id, workspaceID)
+}
+
+func (r *ProjectRepository) Transfer(ctx context.Context, fromWorkspaceID, id, toWorkspaceID uuid.UUID) (*models.Project, error) {
+	query := `
+		UPDATE projects
+		SET workspace_id = $1, updated_at = $2
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+		RETURNING ` + projectColumns
+
+	return r.updateProject(ctx, query, toWorkspaceID, time.Now(), id, fromWorkspaceID)
+}
+
+func (r *ProjectRepository) SetArchived(ctx context.Context, workspaceID, id uuid.UUID, archived bool) (*models.Project, error) {
+	return r.setState(ctx, workspaceID, id, "archived_at", archived)
+}
+
+func (r *ProjectRepository) SetPinned(ctx context.Context, workspaceID, id uuid.UUID, pinned bool) (*models.Project, error) {
+	return r.setState(ctx, workspaceID, id, "pinned_at", pinned)
+}
+
+func (r *ProjectRepository) setState(ctx context.Context, workspaceID, id uuid.UUID, column string, on bool) (*models.Project, error) {
+	query := `
+		UPDATE projects
+		SET ` + column + ` = CASE WHEN $1 THEN COALESCE(` + column + `, $2) END
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+		RETURNING ` + projectColumns
+
+	return r.updateProject(ctx, query, on, time.Now(), id, workspaceID)
+}
+
+func (r *ProjectRepository) Restore(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, error) {
+	query := `
+		UPDATE projects
+		SET deleted_at = NULL, redirect_to = NULL, updated_at = $1
+		WHERE id = $2 AND workspace_id = $3 AND deleted_at IS NOT NULL
+		RETURNING ` + projectColumns
+
+	return r.updateProject(ctx, query, time.Now(), id, workspaceID)
+}
+
+// updateProject runs an UPDATE returning projectColumns and returns nil if
+// it matched no project.
+func (r *ProjectRepository) updateProject(ctx context.Context, query string, args ...interface{}) (*models.Project, error) {
+	project := &models.Project{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, args...).Scan(projectFields(project)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (r *ProjectRepository) AddFavourite(ctx context.Context, userID, projectID uuid.UUID) error {
+	query := `
+		INSERT INTO project_favourites (user_id, project_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, project_id) DO NOTHING
+	`
+
+	_, err := r.db.Querier(ctx).ExecContext(ctx, query, userID, projectID, time.Now())
+	return err
+}
+
+func (r *ProjectRepository) RemoveFavourite(ctx context.Context, userID, projectID uuid.UUID) error {
+	_, err := r.db.Querier(ctx).ExecContext(ctx, `DELETE FROM project_favourites WHERE user_id = $1 AND project_id = $2`, userID, projectID)
+	return err
+}
+
+func (r *ProjectRepository) SoftDelete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `
+		UPDATE projects
+		SET deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND workspace_id = $3 AND deleted_at IS NULL
+	`
+
+	return execOne(ctx, r.db, query, time.Now(), id, workspaceID)
+}
+
+func (r *ProjectRepository) MarkMerged(ctx context.Context, workspaceID, id, into uuid.UUID) error {
+	query := `
+		UPDATE projects
+		SET deleted_at = $1, updated_at = $1, redirect_to = $2
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+	`
+
+	return execOne(ctx, r.db, query, time.Now(), into, id, workspaceID)
+}
+
+func (r *ProjectRepository) GetRedirect(ctx context.Context, workspaceID, id uuid.UUID) (*uuid.UUID, error) {
+	query := `
+		SELECT redirect_to
+		FROM projects
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NOT NULL AND redirect_to IS NOT NULL
+	`
+
+	var to uuid.UUID
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, id, workspaceID).Scan(&to)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &to, nil
+}
+
+func (r *ProjectRepository) ListDeleted(ctx context.Context, workspaceID uuid.UUID, page, pageSize int) ([]models.TrashedProject, int, error) {
+	offset := (page - 1) * pageSize
+
+	countQuery := `SELECT COUNT(*) FROM projects WHERE workspace_id = $1 AND deleted_at IS NOT NULL`
+	var totalCount int
+	if err := r.db.Querier(ctx).QueryRowContext(ctx, countQuery, workspaceID).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, workspace_id, name, created_at, updated_at, deleted_at
+		FROM projects
+		WHERE workspace_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, workspaceID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	projects := []models.TrashedProject{}
+	for rows.Next() {
+		var p models.TrashedProject
+		if err := rows.Scan(&p.ID, &p.WorkspaceID, &p.Name, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt); err != nil {
+			return nil, 0, err
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, totalCount, rows.Err()
+}
+
+func (r *ProjectRepository) HardDelete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `DELETE FROM projects WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NOT NULL`
+
+	return execOne(ctx, r.db, query, id, workspaceID)
+}
+
+func (r *ProjectRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.Querier(ctx).ExecContext(ctx, `DELETE FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,79 @@
+// Package sqlite implements the repository stores on the embedded SQLite
+// backend. Queries follow the Postgres repositories; where SQLite lacks a
+// feature, such as data-modifying CTEs, the statements run separately in a
+// transaction instead.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+// NewStores returns the SQLite implementation of every store.
+func NewStores(db *database.SQLite) *repository.Stores {
+	return &repository.Stores{
+		Tx:          db,
+		Projects:    NewProjectRepository(db),
+		Documents:   NewDocumentRepository(db),
+		ShareLinks:  NewShareLinkRepository(db),
+		Users:       NewUserRepository(db),
+		Workspaces:  NewWorkspaceRepository(db),
+		Audit:       NewAuditRepository(db),
+		Webhooks:    NewWebhookRepository(db),
+		Idempotency: NewIdempotencyRepository(db),
+		Templates:   NewTemplateRepository(db),
+	}
+}
+
+// execOne runs a statement that must affect a row and returns sql.ErrNoRows
+// if it affected none.
+func execOne(ctx context.Context, db *database.SQLite, query string, args ...interface{}) error {
+	result, err := db.Querier(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// jsonColumn scans a JSON text column into v.
+type jsonColumn struct {
+	v interface{}
+}
+
+func (j jsonColumn) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(s), j.v)
+	case []byte:
+		return json.Unmarshal(s, j.v)
+	default:
+		return fmt.Errorf("cannot scan %T into a JSON column", src)
+	}
+}
+
+// marshalJSON encodes v for a JSON text column.
+func marshalJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
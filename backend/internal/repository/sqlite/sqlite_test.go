@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+func newTestDB(t *testing.T) *database.SQLite {
+	t.Helper()
+
+	url := database.SQLiteScheme + filepath.Join(t.TempDir(), "test.db")
+	if err := database.MigrateUp(url); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	db, err := database.NewSQLite(url)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+func TestProjectDocumentRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	stores := NewStores(newTestDB(t))
+
+	project, err := stores.Projects.Create(ctx, models.DefaultWorkspaceID, "Notes")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := stores.Documents.Create(ctx, project.ID, ""); err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+
+	doc, err := stores.Documents.GetByProjectID(ctx, project.ID)
+	if err != nil || doc == nil {
+		t.Fatalf("Expected document, got %v, %v", doc, err)
+	}
+
+	updated, err := stores.Documents.Update(ctx, doc.ID, "# Notes", doc.Version)
+	if err != nil || updated == nil || updated.Version != 2 {
+		t.Fatalf("Expected version 2, got %+v, %v", updated, err)
+	}
+
+	stale, err := stores.Documents.Update(ctx, doc.ID, "stale", doc.Version)
+	if err != nil || stale != nil {
+		t.Errorf("Expected stale update to match nothing, got %+v, %v", stale, err)
+	}
+
+	rev, err := stores.Documents.GetRevision(ctx, doc.ID, 1)
+	if err != nil || rev == nil || rev.ContentMD != "" {
+		t.Errorf("Expected empty first revision, got %+v, %v", rev, err)
+	}
+
+	again, err := stores.Projects.GetByName(ctx, models.DefaultWorkspaceID, "notes")
+	if err != nil || again == nil || again.ID != project.ID {
+		t.Errorf("Expected existing project, got %+v, %v", again, err)
+	}
+}
+
+func TestProjectListSearch(t *testing.T) {
+	ctx := context.Background()
+	stores := NewStores(newTestDB(t))
+
+	for _, name := range []string{"Meeting notes", "Roadmap", "100% done"} {
+		if _, err := stores.Projects.Create(ctx, models.DefaultWorkspaceID, name); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   int
+	}{
+		{"", 3},
+		{"meeting", 1},
+		{"roadmpa", 1},
+		{"%", 1},
+	}
+
+	for _, tt := range tests {
+		q := models.ProjectListQuery{
+			WorkspaceID: models.DefaultWorkspaceID,
+			Search:      tt.search,
+			Sort:        models.ProjectSortName,
+			Page:        1,
+			PageSize:    10,
+		}
+		projects, total, err := stores.Projects.List(ctx, q, 10)
+		if err != nil {
+			t.Fatalf("List(%q) failed: %v", tt.search, err)
+		}
+		if total != tt.want || len(projects) != tt.want {
+			t.Errorf("List(%q) returned %d of %d projects, want %d", tt.search, len(projects), total, tt.want)
+		}
+	}
+}
+
+func TestUniqueProjectNames(t *testing.T) {
+	ctx := context.Background()
+	stores := NewStores(newTestDB(t))
+
+	if _, err := stores.Workspaces.Update(ctx, models.DefaultWorkspaceID, "Default", true); err != nil {
+		t.Fatalf("Failed to update workspace: %v", err)
+	}
+	if _, err := stores.Projects.Create(ctx, models.DefaultWorkspaceID, "Notes"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	_, err := stores.Projects.Create(ctx, models.DefaultWorkspaceID, "NOTES")
+	if !repository.IsUniqueViolation(err) {
+		t.Errorf("Expected unique violation, got %v", err)
+	}
+}
+
+func TestInTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	stores := NewStores(newTestDB(t))
+
+	var project *models.Project
+	failed := errors.New("document insert failed")
+	err := stores.Tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		project, err = stores.Projects.Create(ctx, models.DefaultWorkspaceID, "Notes")
+		if err != nil {
+			return err
+		}
+		return failed
+	})
+	if err != failed {
+		t.Fatalf("Expected the error of fn, got %v", err)
+	}
+
+	got, err := stores.Projects.GetByID(ctx, project.ID)
+	if err != nil || got != nil {
+		t.Errorf("Expected the project to be rolled back, got %+v, %v", got, err)
+	}
+}
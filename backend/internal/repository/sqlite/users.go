@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type UserRepository struct {
+	db *database.SQLite
+}
+
+func NewUserRepository(db *database.SQLite) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Create(ctx context.Context, name, tokenHash string) (*models.User, error) {
+	query := `
+		INSERT INTO users (id, name, token_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, name, created_at
+	`
+
+	user := &models.User{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, uuid.New(), name, tokenHash, time.Now()).Scan(
+		&user.ID, &user.Name, &user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.User, error) {
+	return r.getUser(ctx, `SELECT id, name, created_at FROM users WHERE token_hash = $1`, tokenHash)
+}
+
+func (r *UserRepository) GetByName(ctx context.Context, name string) (*models.User, error) {
+	return r.getUser(ctx, `SELECT id, name, created_at FROM users WHERE name = $1`, name)
+}
+
+func (r *UserRepository) getUser(ctx context.Context, query string, arg interface{}) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, arg).Scan(&user.ID, &user.Name, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
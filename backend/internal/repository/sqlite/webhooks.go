@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type WebhookRepository struct {
+	db *database.SQLite
+}
+
+func NewWebhookRepository(db *database.SQLite) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookColumns = `id, workspace_id, url, events, active, created_at, updated_at`
+
+func webhookFields(w *models.Webhook) []interface{} {
+	return []interface{}{&w.ID, &w.WorkspaceID, &w.URL, jsonColumn{&w.Events}, &w.Active, &w.CreatedAt, &w.UpdatedAt}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, workspaceID uuid.UUID, url string, events []string, secret string) (*models.Webhook, error) {
+	encoded, err := marshalEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO webhooks (id, workspace_id, url, events, secret, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, TRUE, $6, $6)
+		RETURNING ` + webhookColumns + `, secret`
+
+	w := &models.Webhook{}
+	err = r.db.Querier(ctx).QueryRowContext(ctx, query, uuid.New(), workspaceID, url, encoded, secret, time.Now()).Scan(
+		append(webhookFields(w), &w.Secret)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// marshalEvents encodes events as a JSON array, never null.
+func marshalEvents(events []string) (string, error) {
+	if events == nil {
+		events = []string{}
+	}
+	return marshalJSON(events)
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND workspace_id = $2`
+
+	w := &models.Webhook{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, id, workspaceID).Scan(webhookFields(w)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context, workspaceID uuid.UUID) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE workspace_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var w models.Webhook
+		if err := rows.Scan(webhookFields(&w)...); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, workspaceID, id uuid.UUID, url string, events []string, active bool) (*models.Webhook, error) {
+	encoded, err := marshalEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = $1, events = $2, active = $3, updated_at = $4
+		WHERE id = $5 AND workspace_id = $6
+		RETURNING ` + webhookColumns
+
+	w := &models.Webhook{}
+	err = r.db.Querier(ctx).QueryRowContext(ctx, query, url, encoded, active, time.Now(), id, workspaceID).Scan(webhookFields(w)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	return execOne(ctx, r.db, `DELETE FROM webhooks WHERE id = $1 AND workspace_id = $2`, id, workspaceID)
+}
+
+// Enqueue adds a pending delivery of payload for every active webhook of the
+// workspace subscribed to eventType.
+func (r *WebhookRepository) Enqueue(ctx context.Context, workspaceID uuid.UUID, eventType string, payload []byte) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload, status, next_attempt_at, created_at)
+		SELECT id, $2, $3, $4, $5, $5
+		FROM webhooks
+		WHERE workspace_id = $1 AND active
+			AND (json_array_length(events) = 0 OR EXISTS (SELECT 1 FROM json_each(webhooks.events) WHERE value = $2))
+	`
+
+	_, err := r.db.Querier(ctx).ExecContext(ctx, query, workspaceID, eventType, string(payload), models.DeliveryStatusPending, time.Now())
+	return err
+}
+
+// ClaimDue leases up to limit pending deliveries that are due. Their
+// next_attempt_at is pushed to leaseUntil so that they are not picked up
+// again while they are in flight.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]models.PendingWebhookDelivery, error) {
+	var deliveries []models.PendingWebhookDelivery
+
+	err := r.db.InTx(ctx, func(ctx context.Context) error {
+		q := r.db.Querier(ctx)
+
+		query := `
+			SELECT d.id, d.webhook_id, d.event_type, d.payload, d.attempts, w.url, w.secret
+			FROM webhook_deliveries d
+			INNER JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = $1 AND d.next_attempt_at <= $2 AND w.active
+			ORDER BY d.next_attempt_at
+			LIMIT $3
+		`
+
+		rows, err := q.QueryContext(ctx, query, models.DeliveryStatusPending, time.Now(), limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		args := []interface{}{leaseUntil}
+		var placeholders []string
+		for rows.Next() {
+			var d models.PendingWebhookDelivery
+			if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Attempts, &d.URL, &d.Secret); err != nil {
+				return err
+			}
+			d.Attempts++
+			deliveries = append(deliveries, d)
+			args = append(args, d.ID)
+			placeholders = append(placeholders, placeholder(len(args)))
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		leaseQuery := `
+			UPDATE webhook_deliveries
+			SET next_attempt_at = $1, attempts = attempts + 1
+			WHERE id IN (` + strings.Join(placeholders, ", ") + `)
+		`
+
+		_, err = q.ExecContext(ctx, leaseQuery, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, last_status_code = $2, last_error = NULL, delivered_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Querier(ctx).ExecContext(ctx, query, models.DeliveryStatusDelivered, statusCode, time.Now(), id)
+	return err
+}
+
+// MarkAttemptFailed records a failed attempt. The delivery is retried at
+// nextAttemptAt, or given up on if nextAttemptAt is nil.
+func (r *WebhookRepository) MarkAttemptFailed(ctx context.Context, id int64, statusCode *int, lastError string, nextAttemptAt *time.Time) error {
+	status := models.DeliveryStatusPending
+	next := time.Now()
+	if nextAttemptAt == nil {
+		status = models.DeliveryStatusFailed
+	} else {
+		next = *nextAttemptAt
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, last_status_code = $2, last_error = $3, next_attempt_at = $4
+		WHERE id = $5
+	`
+
+	_, err := r.db.Querier(ctx).ExecContext(ctx, query, status, statusCode, lastError, next, id)
+	return err
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT id, webhook_id, event_type, payload, status, attempts, next_attempt_at,
+			last_status_code, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(
+			&d.ID, &d.WebhookID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
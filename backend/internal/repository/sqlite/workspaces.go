@@ -0,0 +1,249 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type WorkspaceRepository struct {
+	db *database.SQLite
+}
+
+func NewWorkspaceRepository(db *database.SQLite) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+const workspaceColumns = `id, name, unique_project_names, created_at, updated_at`
+
+func workspaceFields(ws *models.Workspace) []interface{} {
+	return []interface{}{&ws.ID, &ws.Name, &ws.UniqueProjectNames, &ws.CreatedAt, &ws.UpdatedAt}
+}
+
+// Create inserts a workspace and makes ownerID its first owner.
+func (r *WorkspaceRepository) Create(ctx context.Context, name string, ownerID uuid.UUID) (*models.Workspace, error) {
+	ws := &models.Workspace{Role: models.WorkspaceRoleOwner}
+
+	err := r.db.InTx(ctx, func(ctx context.Context) error {
+		q := r.db.Querier(ctx)
+		now := time.Now()
+
+		query := `
+			INSERT INTO workspaces (id, name, created_at, updated_at)
+			VALUES ($1, $2, $3, $3)
+			RETURNING ` + workspaceColumns
+
+		if err := q.QueryRowContext(ctx, query, uuid.New(), name, now).Scan(workspaceFields(ws)...); err != nil {
+			return err
+		}
+
+		memberQuery := `
+			INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+			VALUES ($1, $2, $3, $4)
+		`
+
+		_, err := q.ExecContext(ctx, memberQuery, ws.ID, ownerID, models.WorkspaceRoleOwner, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+func (r *WorkspaceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Workspace, error) {
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces WHERE id = $1`
+
+	ws := &models.Workspace{}
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, id).Scan(workspaceFields(ws)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// Update renames the workspace and changes whether its project names must
+// be unique. Turning uniqueness on fails with a unique violation while the
+// workspace still holds projects with the same name.
+func (r *WorkspaceRepository) Update(ctx context.Context, id uuid.UUID, name string, uniqueProjectNames bool) (*models.Workspace, error) {
+	var ws *models.Workspace
+
+	err := r.db.InTx(ctx, func(ctx context.Context) error {
+		q := r.db.Querier(ctx)
+
+		query := `
+			UPDATE workspaces
+			SET name = $1, unique_project_names = $2, updated_at = $3
+			WHERE id = $4
+			RETURNING ` + workspaceColumns
+
+		updated := &models.Workspace{}
+		err := q.QueryRowContext(ctx, query, name, uniqueProjectNames, time.Now(), id).Scan(workspaceFields(updated)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		keysQuery := `
+			UPDATE projects
+			SET name_key = CASE WHEN $1 THEN lower(name) END
+			WHERE workspace_id = $2
+		`
+
+		if _, err := q.ExecContext(ctx, keysQuery, uniqueProjectNames, id); err != nil {
+			return err
+		}
+
+		ws = updated
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// ListForUser returns every workspace userID is a member of, with the
+// user's role filled in.
+func (r *WorkspaceRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]models.Workspace, error) {
+	query := `
+		SELECT w.id, w.name, w.unique_project_names, m.role, w.created_at, w.updated_at
+		FROM workspaces w
+		INNER JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.name
+	`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []models.Workspace{}
+	for rows.Next() {
+		var ws models.Workspace
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.UniqueProjectNames, &ws.Role, &ws.CreatedAt, &ws.UpdatedAt); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// GetMemberRole returns the role of userID in workspaceID, or an empty string
+// if the user is not a member.
+func (r *WorkspaceRepository) GetMemberRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error) {
+	query := `
+		SELECT role
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+	`
+
+	var role string
+	err := r.db.Querier(ctx).QueryRowContext(ctx, query, workspaceID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return role, nil
+}
+
+const memberQuery = `
+	SELECT m.workspace_id, m.user_id, u.name, m.role, m.created_at
+	FROM workspace_members m
+	INNER JOIN users u ON u.id = m.user_id
+`
+
+func memberFields(m *models.WorkspaceMember) []interface{} {
+	return []interface{}{&m.WorkspaceID, &m.UserID, &m.UserName, &m.Role, &m.CreatedAt}
+}
+
+func (r *WorkspaceRepository) ListMembers(ctx context.Context, workspaceID uuid.UUID) ([]models.WorkspaceMember, error) {
+	query := memberQuery + `WHERE m.workspace_id = $1 ORDER BY u.name`
+
+	rows, err := r.db.Querier(ctx).QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.WorkspaceMember{}
+	for rows.Next() {
+		var m models.WorkspaceMember
+		if err := rows.Scan(memberFields(&m)...); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// UpsertMember adds userID to workspaceID, or changes the role of an
+// existing member.
+func (r *WorkspaceRepository) UpsertMember(ctx context.Context, workspaceID, userID uuid.UUID, role string) (*models.WorkspaceMember, error) {
+	m := &models.WorkspaceMember{}
+
+	err := r.db.InTx(ctx, func(ctx context.Context) error {
+		q := r.db.Querier(ctx)
+
+		upsertQuery := `
+			INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = excluded.role
+		`
+
+		if _, err := q.ExecContext(ctx, upsertQuery, workspaceID, userID, role, time.Now()); err != nil {
+			return err
+		}
+
+		query := memberQuery + `WHERE m.workspace_id = $1 AND m.user_id = $2`
+		return q.QueryRowContext(ctx, query, workspaceID, userID).Scan(memberFields(m)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (r *WorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	return execOne(ctx, r.db, query, workspaceID, userID)
+}
+
+// LockMembers needs no lock: the single connection already serializes
+// transactions.
+func (r *WorkspaceRepository) LockMembers(ctx context.Context, workspaceID uuid.UUID) error {
+	return nil
+}
+
+func (r *WorkspaceRepository) CountOwners(ctx context.Context, workspaceID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = $2`
+
+	var count int
+	if err := r.db.Querier(ctx).QueryRowContext(ctx, query, workspaceID, models.WorkspaceRoleOwner).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
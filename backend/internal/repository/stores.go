@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+// The store interfaces are what services depend on. The Postgres
+// repositories in this package implement them, and so do the SQLite ones in
+// repository/sqlite. Lookups of missing rows return nil without an error;
+// mutations that match no row return an error.
+
+// Transactor runs fn in a transaction that stores called with the context
+// passed to fn take part in.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type ProjectStore interface {
+	Create(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Project, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error)
+	List(ctx context.Context, q models.ProjectListQuery, limit int) ([]models.Project, int, error)
+	Lookup(ctx context.Context, workspaceID uuid.UUID, name string, limit int) ([]models.ProjectMatch, error)
+	GetByName(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Project, error)
+	// LockName serializes the transactions that create a project named
+	// name, until the transaction of ctx ends.
+	LockName(ctx context.Context, workspaceID uuid.UUID, name string) error
+	Update(ctx context.Context, workspaceID, id uuid.UUID, name string) (*models.Project, error)
+	Transfer(ctx context.Context, fromWorkspaceID, id, toWorkspaceID uuid.UUID) (*models.Project, error)
+	SetArchived(ctx context.Context, workspaceID, id uuid.UUID, archived bool) (*models.Project, error)
+	SetPinned(ctx context.Context, workspaceID, id uuid.UUID, pinned bool) (*models.Project, error)
+	AddFavourite(ctx context.Context, userID, projectID uuid.UUID) error
+	RemoveFavourite(ctx context.Context, userID, projectID uuid.UUID) error
+	SoftDelete(ctx context.Context, workspaceID, id uuid.UUID) error
+	MarkMerged(ctx context.Context, workspaceID, id, into uuid.UUID) error
+	GetRedirect(ctx context.Context, workspaceID, id uuid.UUID) (*uuid.UUID, error)
+	ListDeleted(ctx context.Context, workspaceID uuid.UUID, page, pageSize int) ([]models.TrashedProject, int, error)
+	Restore(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, error)
+	HardDelete(ctx context.Context, workspaceID, id uuid.UUID) error
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type DocumentStore interface {
+	Create(ctx context.Context, projectID uuid.UUID, contentMD string) (*models.Document, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error)
+	GetByProjectID(ctx context.Context, projectID uuid.UUID) (*models.Document, error)
+	// LockByProjectID is GetByProjectID, but the document and its project
+	// cannot be changed by other transactions until the transaction of ctx
+	// ends.
+	LockByProjectID(ctx context.Context, projectID uuid.UUID) (*models.Document, error)
+	Update(ctx context.Context, id uuid.UUID, contentMD string, expectedVersion int) (*models.Document, error)
+	GetRevision(ctx context.Context, documentID uuid.UUID, version int) (*models.DocumentRevision, error)
+	ListSummaries(ctx context.Context, projectIDs []uuid.UUID, fullContent bool) (map[uuid.UUID]models.DocumentSummary, error)
+	// EnqueueCommit queues a saved version of a document to be committed
+	// to git storage.
+	EnqueueCommit(ctx context.Context, documentID uuid.UUID, version int, author string) error
+	// ListPendingCommits returns up to limit queued versions, in the order
+	// they were queued.
+	ListPendingCommits(ctx context.Context, limit int) ([]models.PendingDocumentCommit, error)
+	DeleteCommit(ctx context.Context, id int64) error
+}
+
+type ShareLinkStore interface {
+	Create(ctx context.Context, link *models.ShareLink) (*models.ShareLink, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.ShareLink, error)
+	GetActiveByToken(ctx context.Context, token string) (*models.ShareLink, error)
+	Revoke(ctx context.Context, projectID, id uuid.UUID) error
+}
+
+type UserStore interface {
+	Create(ctx context.Context, name, tokenHash string) (*models.User, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.User, error)
+	GetByName(ctx context.Context, name string) (*models.User, error)
+}
+
+type WorkspaceStore interface {
+	Create(ctx context.Context, name string, ownerID uuid.UUID) (*models.Workspace, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Workspace, error)
+	Update(ctx context.Context, id uuid.UUID, name string, uniqueProjectNames bool) (*models.Workspace, error)
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]models.Workspace, error)
+	GetMemberRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error)
+	ListMembers(ctx context.Context, workspaceID uuid.UUID) ([]models.WorkspaceMember, error)
+	UpsertMember(ctx context.Context, workspaceID, userID uuid.UUID, role string) (*models.WorkspaceMember, error)
+	RemoveMember(ctx context.Context, workspaceID, userID uuid.UUID) error
+	CountOwners(ctx context.Context, workspaceID uuid.UUID) (int, error)
+	// LockMembers keeps other transactions from changing the members of
+	// the workspace until the transaction of ctx ends.
+	LockMembers(ctx context.Context, workspaceID uuid.UUID) error
+}
+
+type AuditStore interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type WebhookStore interface {
+	Create(ctx context.Context, workspaceID uuid.UUID, url string, events []string, secret string) (*models.Webhook, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Webhook, error)
+	List(ctx context.Context, workspaceID uuid.UUID) ([]models.Webhook, error)
+	Update(ctx context.Context, workspaceID, id uuid.UUID, url string, events []string, active bool) (*models.Webhook, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
+	Enqueue(ctx context.Context, workspaceID uuid.UUID, eventType string, payload []byte) error
+	ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]models.PendingWebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	MarkAttemptFailed(ctx context.Context, id int64, statusCode *int, lastError string, nextAttemptAt *time.Time) error
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+}
+
+type IdempotencyStore interface {
+	Claim(ctx context.Context, scope, key, requestHash string, expiredBefore, staleBefore time.Time) (bool, error)
+	Get(ctx context.Context, scope, key string) (*models.IdempotentResponse, error)
+	Complete(ctx context.Context, scope, key string, resp models.IdempotentResponse) error
+	Delete(ctx context.Context, scope, key string) error
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type TemplateStore interface {
+	Create(ctx context.Context, workspaceID uuid.UUID, name, description, contentMD string, sourceProjectID *uuid.UUID) (*models.Template, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Template, error)
+	List(ctx context.Context, workspaceID uuid.UUID) ([]models.Template, error)
+	Update(ctx context.Context, workspaceID, id uuid.UUID, name, description, contentMD string) (*models.Template, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
+}
+
+// Stores bundles the stores of one storage backend.
+type Stores struct {
+	Tx          Transactor
+	Projects    ProjectStore
+	Documents   DocumentStore
+	ShareLinks  ShareLinkStore
+	Users       UserStore
+	Workspaces  WorkspaceStore
+	Audit       AuditStore
+	Webhooks    WebhookStore
+	Idempotency IdempotencyStore
+	Templates   TemplateStore
+}
+
+// NewPostgresStores returns the Postgres implementation of every store.
+func NewPostgresStores(db *database.Postgres) *Stores {
+	return &Stores{
+		Tx:          db,
+		Projects:    NewProjectRepository(db),
+		Documents:   NewDocumentRepository(db),
+		ShareLinks:  NewShareLinkRepository(db),
+		Users:       NewUserRepository(db),
+		Workspaces:  NewWorkspaceRepository(db),
+		Audit:       NewAuditRepository(db),
+		Webhooks:    NewWebhookRepository(db),
+		Idempotency: NewIdempotencyRepository(db),
+		Templates:   NewTemplateRepository(db),
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type TemplateRepository struct {
+	db *database.Postgres
+}
+
+func NewTemplateRepository(db *database.Postgres) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+const templateColumns = `id, workspace_id, name, description, content_md, source_project_id, created_at, updated_at`
+
+func scanTemplate(row pgx.Row) (*models.Template, error) {
+	t := &models.Template{}
+	err := row.Scan(&t.ID, &t.WorkspaceID, &t.Name, &t.Description, &t.ContentMD, &t.SourceProjectID, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, workspaceID uuid.UUID, name, description, contentMD string, sourceProjectID *uuid.UUID) (*models.Template, error) {
+	query := `
+		INSERT INTO templates (id, workspace_id, name, description, content_md, source_project_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING ` + templateColumns
+
+	return scanTemplate(r.db.Querier(ctx).QueryRow(ctx, query,
+		uuid.New(), workspaceID, name, description, contentMD, sourceProjectID, time.Now(),
+	))
+}
+
+func (r *TemplateRepository) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM templates WHERE id = $1 AND workspace_id = $2`
+
+	t, err := scanTemplate(r.db.Querier(ctx).QueryRow(ctx, query, id, workspaceID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *TemplateRepository) List(ctx context.Context, workspaceID uuid.UUID) ([]models.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM templates WHERE workspace_id = $1 ORDER BY name, created_at`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+
+	return templates, rows.Err()
+}
+
+func (r *TemplateRepository) Update(ctx context.Context, workspaceID, id uuid.UUID, name, description, contentMD string) (*models.Template, error) {
+	query := `
+		UPDATE templates
+		SET name = $1, description = $2, content_md = $3, updated_at = $4
+		WHERE id = $5 AND workspace_id = $6
+		RETURNING ` + templateColumns
+
+	t, err := scanTemplate(r.db.Querier(ctx).QueryRow(ctx, query, name, description, contentMD, time.Now(), id, workspaceID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *TemplateRepository) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	result, err := r.db.Querier(ctx).Exec(ctx, `DELETE FROM templates WHERE id = $1 AND workspace_id = $2`, id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
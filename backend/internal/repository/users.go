@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type UserRepository struct {
+	db *database.Postgres
+}
+
+func NewUserRepository(db *database.Postgres) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Create(ctx context.Context, name, tokenHash string) (*models.User, error) {
+	query := `
+		INSERT INTO users (id, name, token_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, name, created_at
+	`
+
+	user := &models.User{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, uuid.New(), name, tokenHash, time.Now()).Scan(
+		&user.ID, &user.Name, &user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.User, error) {
+	query := `
+		SELECT id, name, created_at
+		FROM users
+		WHERE token_hash = $1
+	`
+
+	user := &models.User{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, tokenHash).Scan(&user.ID, &user.Name, &user.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) GetByName(ctx context.Context, name string) (*models.User, error) {
+	query := `
+		SELECT id, name, created_at
+		FROM users
+		WHERE name = $1
+	`
+
+	user := &models.User{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, name).Scan(&user.ID, &user.Name, &user.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
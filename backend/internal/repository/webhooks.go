@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type WebhookRepository struct {
+	db *database.Postgres
+}
+
+func NewWebhookRepository(db *database.Postgres) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, workspaceID uuid.UUID, url string, events []string, secret string) (*models.Webhook, error) {
+	now := time.Now()
+	query := `
+		INSERT INTO webhooks (id, workspace_id, url, events, secret, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, TRUE, $6, $6)
+		RETURNING id, workspace_id, url, events, secret, active, created_at, updated_at
+	`
+
+	w := &models.Webhook{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, uuid.New(), workspaceID, url, events, secret, now).Scan(
+		&w.ID, &w.WorkspaceID, &w.URL, &w.Events, &w.Secret, &w.Active, &w.CreatedAt, &w.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Webhook, error) {
+	query := `
+		SELECT id, workspace_id, url, events, active, created_at, updated_at
+		FROM webhooks
+		WHERE id = $1 AND workspace_id = $2
+	`
+
+	w := &models.Webhook{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, id, workspaceID).Scan(
+		&w.ID, &w.WorkspaceID, &w.URL, &w.Events, &w.Active, &w.CreatedAt, &w.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context, workspaceID uuid.UUID) ([]models.Webhook, error) {
+	query := `
+		SELECT id, workspace_id, url, events, active, created_at, updated_at
+		FROM webhooks
+		WHERE workspace_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var w models.Webhook
+		if err := rows.Scan(&w.ID, &w.WorkspaceID, &w.URL, &w.Events, &w.Active, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, workspaceID, id uuid.UUID, url string, events []string, active bool) (*models.Webhook, error) {
+	query := `
+		UPDATE webhooks
+		SET url = $1, events = $2, active = $3, updated_at = $4
+		WHERE id = $5 AND workspace_id = $6
+		RETURNING id, workspace_id, url, events, active, created_at, updated_at
+	`
+
+	w := &models.Webhook{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, url, events, active, time.Now(), id, workspaceID).Scan(
+		&w.ID, &w.WorkspaceID, &w.URL, &w.Events, &w.Active, &w.CreatedAt, &w.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `DELETE FROM webhooks WHERE id = $1 AND workspace_id = $2`
+
+	result, err := r.db.Querier(ctx).Exec(ctx, query, id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Enqueue adds a pending delivery of payload for every active webhook of the
+// workspace subscribed to eventType.
+func (r *WebhookRepository) Enqueue(ctx context.Context, workspaceID uuid.UUID, eventType string, payload []byte) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload, status, next_attempt_at, created_at)
+		SELECT id, $2, $3, $4, $5, $5
+		FROM webhooks
+		WHERE workspace_id = $1 AND active AND (cardinality(events) = 0 OR $2 = ANY(events))
+	`
+
+	_, err := r.db.Querier(ctx).Exec(ctx, query, workspaceID, eventType, payload, models.DeliveryStatusPending, time.Now())
+	return err
+}
+
+// ClaimDue leases up to limit pending deliveries that are due. Their
+// next_attempt_at is pushed to leaseUntil so that concurrent dispatchers do
+// not pick them up again while they are in flight.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]models.PendingWebhookDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			INNER JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = $1 AND d.next_attempt_at <= $2 AND w.active
+			ORDER BY d.next_attempt_at
+			LIMIT $3
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $4, attempts = d.attempts + 1
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING d.id, d.webhook_id, d.event_type, d.payload, d.attempts, w.url, w.secret
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, models.DeliveryStatusPending, time.Now(), limit, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.PendingWebhookDelivery
+	for rows.Next() {
+		var d models.PendingWebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Attempts, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, last_status_code = $2, last_error = NULL, delivered_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Querier(ctx).Exec(ctx, query, models.DeliveryStatusDelivered, statusCode, time.Now(), id)
+	return err
+}
+
+// MarkAttemptFailed records a failed attempt. The delivery is retried at
+// nextAttemptAt, or given up on if nextAttemptAt is nil.
+func (r *WebhookRepository) MarkAttemptFailed(ctx context.Context, id int64, statusCode *int, lastError string, nextAttemptAt *time.Time) error {
+	status := models.DeliveryStatusPending
+	next := time.Now()
+	if nextAttemptAt == nil {
+		status = models.DeliveryStatusFailed
+	} else {
+		next = *nextAttemptAt
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, last_status_code = $2, last_error = $3, next_attempt_at = $4
+		WHERE id = $5
+	`
+
+	_, err := r.db.Querier(ctx).Exec(ctx, query, status, statusCode, lastError, next, id)
+	return err
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT id, webhook_id, event_type, payload, status, attempts, next_attempt_at,
+			last_status_code, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(
+			&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
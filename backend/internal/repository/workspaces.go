@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+type WorkspaceRepository struct {
+	db *database.Postgres
+}
+
+func NewWorkspaceRepository(db *database.Postgres) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+// Create inserts a workspace and makes ownerID its first owner.
+func (r *WorkspaceRepository) Create(ctx context.Context, name string, ownerID uuid.UUID) (*models.Workspace, error) {
+	now := time.Now()
+	query := `
+		WITH inserted AS (
+			INSERT INTO workspaces (id, name, created_at, updated_at)
+			VALUES ($1, $2, $3, $3)
+			RETURNING id, name, unique_project_names, created_at, updated_at
+		), member AS (
+			INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+			SELECT id, $4, $5, $3 FROM inserted
+		)
+		SELECT id, name, unique_project_names, created_at, updated_at FROM inserted
+	`
+
+	ws := &models.Workspace{Role: models.WorkspaceRoleOwner}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, uuid.New(), name, now, ownerID, models.WorkspaceRoleOwner).Scan(
+		&ws.ID, &ws.Name, &ws.UniqueProjectNames, &ws.CreatedAt, &ws.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+func (r *WorkspaceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Workspace, error) {
+	query := `
+		SELECT id, name, unique_project_names, created_at, updated_at
+		FROM workspaces
+		WHERE id = $1
+	`
+
+	ws := &models.Workspace{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, id).Scan(&ws.ID, &ws.Name, &ws.UniqueProjectNames, &ws.CreatedAt, &ws.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// Update renames the workspace and changes whether its project names must
+// be unique. Turning uniqueness on fails with a unique violation while the
+// workspace still holds projects with the same name.
+func (r *WorkspaceRepository) Update(ctx context.Context, id uuid.UUID, name string, uniqueProjectNames bool) (*models.Workspace, error) {
+	query := `
+		WITH updated AS (
+			UPDATE workspaces
+			SET name = $1, unique_project_names = $2, updated_at = $3
+			WHERE id = $4
+			RETURNING id, name, unique_project_names, created_at, updated_at
+		), keys AS (
+			UPDATE projects
+			SET name_key = CASE WHEN $2 THEN lower(name) END
+			WHERE workspace_id = (SELECT id FROM updated)
+		)
+		SELECT id, name, unique_project_names, created_at, updated_at FROM updated
+	`
+
+	ws := &models.Workspace{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, name, uniqueProjectNames, time.Now(), id).Scan(
+		&ws.ID, &ws.Name, &ws.UniqueProjectNames, &ws.CreatedAt, &ws.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// ListForUser returns every workspace userID is a member of, with the
+// user's role filled in.
+func (r *WorkspaceRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]models.Workspace, error) {
+	query := `
+		SELECT w.id, w.name, w.unique_project_names, m.role, w.created_at, w.updated_at
+		FROM workspaces w
+		INNER JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.name
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []models.Workspace{}
+	for rows.Next() {
+		var ws models.Workspace
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.UniqueProjectNames, &ws.Role, &ws.CreatedAt, &ws.UpdatedAt); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// GetMemberRole returns the role of userID in workspaceID, or an empty string
+// if the user is not a member.
+func (r *WorkspaceRepository) GetMemberRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error) {
+	query := `
+		SELECT role
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+	`
+
+	var role string
+	err := r.db.Querier(ctx).QueryRow(ctx, query, workspaceID, userID).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *WorkspaceRepository) ListMembers(ctx context.Context, workspaceID uuid.UUID) ([]models.WorkspaceMember, error) {
+	query := `
+		SELECT m.workspace_id, m.user_id, u.name, m.role, m.created_at
+		FROM workspace_members m
+		INNER JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY u.name
+	`
+
+	rows, err := r.db.Querier(ctx).Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.WorkspaceMember{}
+	for rows.Next() {
+		var m models.WorkspaceMember
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.UserName, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// UpsertMember adds userID to workspaceID, or changes the role of an
+// existing member.
+func (r *WorkspaceRepository) UpsertMember(ctx context.Context, workspaceID, userID uuid.UUID, role string) (*models.WorkspaceMember, error) {
+	query := `
+		WITH upserted AS (
+			INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING workspace_id, user_id, role, created_at
+		)
+		SELECT up.workspace_id, up.user_id, u.name, up.role, up.created_at
+		FROM upserted up
+		INNER JOIN users u ON u.id = up.user_id
+	`
+
+	m := &models.WorkspaceMember{}
+	err := r.db.Querier(ctx).QueryRow(ctx, query, workspaceID, userID, role, time.Now()).Scan(
+		&m.WorkspaceID, &m.UserID, &m.UserName, &m.Role, &m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (r *WorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	result, err := r.db.Querier(ctx).Exec(ctx, query, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// LockMembers locks the workspace row, which every change of membership
+// locks first.
+func (r *WorkspaceRepository) LockMembers(ctx context.Context, workspaceID uuid.UUID) error {
+	_, err := r.db.Querier(ctx).Exec(ctx, `SELECT 1 FROM workspaces WHERE id = $1 FOR UPDATE`, workspaceID)
+	return err
+}
+
+func (r *WorkspaceRepository) CountOwners(ctx context.Context, workspaceID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = $2`
+
+	var count int
+	if err := r.db.Querier(ctx).QueryRow(ctx, query, workspaceID, models.WorkspaceRoleOwner).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,227 @@
+// Package server assembles the HTTP API from the services: its middleware,
+// handlers and routes.
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/handlers"
+	"github.com/warriorguo/md-editor/backend/internal/mcp"
+	"github.com/warriorguo/md-editor/backend/internal/metrics"
+	"github.com/warriorguo/md-editor/backend/internal/middleware"
+	"github.com/warriorguo/md-editor/backend/internal/openapi"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+// Services are the services the API is served by.
+type Services struct {
+	Projects    *services.ProjectService
+	Documents   *services.DocumentService
+	ShareLinks  *services.ShareLinkService
+	Users       *services.UserService
+	Workspaces  *services.WorkspaceService
+	Audit       *services.AuditService
+	Templates   *services.TemplateService
+	Idempotency *services.IdempotencyService
+	Trash       *services.TrashService
+	Webhooks    *services.WebhookService
+}
+
+type Options struct {
+	// AllowedOrigins are the origins browsers may call the API from.
+	AllowedOrigins      []string
+	MaxRequestBodyBytes int64
+	RateLimit           middleware.RateLimitConfig
+	// ValidateResponses checks responses against the OpenAPI document and
+	// logs mismatches. It keeps a copy of every response body, so it is
+	// meant for development.
+	ValidateResponses bool
+}
+
+// NewRouter returns a router serving the API with svc.
+func NewRouter(svc Services, opts Options) (*gin.Engine, error) {
+	spec, err := openapi.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI document: %w", err)
+	}
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Logger(), metrics.HTTP(), middleware.Recovery())
+
+	// CORS configuration; without allowed origins only same-origin
+	// requests are served.
+	if len(opts.AllowedOrigins) > 0 {
+		router.Use(cors.New(cors.Config{
+			AllowOrigins:     opts.AllowedOrigins,
+			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Document-Version", middleware.IdempotencyKeyHeader, middleware.WorkspaceHeader, middleware.RequestIDHeader, "MCP-Protocol-Version"},
+			ExposeHeaders:    []string{"Content-Length", "X-Document-Version", "Retry-After", middleware.IdempotentReplayedHeader, middleware.RequestIDHeader},
+			AllowCredentials: true,
+			MaxAge:           12 * time.Hour,
+		}))
+	}
+
+	rateLimit := middleware.RateLimit(opts.RateLimit)
+
+	routes := &routes{
+		projects:   handlers.NewProjectHandler(svc.Projects),
+		documents:  handlers.NewDocumentHandler(svc.Documents),
+		shareLinks: handlers.NewShareLinkHandler(svc.ShareLinks),
+		workspaces: handlers.NewWorkspaceHandler(svc.Workspaces, svc.Projects),
+		audit:      handlers.NewAuditHandler(svc.Audit),
+		webhooks:   handlers.NewWebhookHandler(svc.Webhooks),
+		trash:      handlers.NewTrashHandler(svc.Trash),
+		templates:  handlers.NewTemplateHandler(svc.Templates),
+		mcp:        handlers.NewMCPHandler(mcp.New(svc.Projects, svc.Documents)),
+
+		spec:      spec,
+		rateLimit: rateLimit,
+		api: []gin.HandlerFunc{
+			middleware.BodyLimit(opts.MaxRequestBodyBytes),
+			middleware.Authenticate(svc.Users),
+			rateLimit,
+			middleware.Audit(svc.Audit),
+			openapi.Validate(spec, opts.ValidateResponses),
+		},
+		requireWorkspace: middleware.RequireWorkspace(svc.Workspaces),
+		idempotency:      middleware.Idempotency(svc.Idempotency),
+	}
+	if err := routes.register(router); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+// routes holds the handlers and middleware the HTTP API is served by.
+// Every route registered here must be described in the OpenAPI document.
+type routes struct {
+	projects   *handlers.ProjectHandler
+	documents  *handlers.DocumentHandler
+	shareLinks *handlers.ShareLinkHandler
+	workspaces *handlers.WorkspaceHandler
+	audit      *handlers.AuditHandler
+	webhooks   *handlers.WebhookHandler
+	trash      *handlers.TrashHandler
+	templates  *handlers.TemplateHandler
+	mcp        *handlers.MCPHandler
+
+	spec      *openapi3.T
+	rateLimit gin.HandlerFunc
+	// api runs before every handler under /api.
+	api              []gin.HandlerFunc
+	requireWorkspace gin.HandlerFunc
+	idempotency      gin.HandlerFunc
+}
+
+func (r *routes) register(router *gin.Engine) error {
+	specHandler, err := openapi.SpecHandler(r.spec)
+	if err != nil {
+		return err
+	}
+
+	// The API description is public, so it is served outside /api and its
+	// authentication.
+	router.GET("/api/openapi.json", r.rateLimit, specHandler)
+	router.GET("/api/docs", r.rateLimit, openapi.DocsHandler)
+
+	api := router.Group("/api")
+	api.Use(r.api...)
+	{
+		workspaces := api.Group("/workspaces")
+		{
+			workspaces.GET("", r.workspaces.List)
+			workspaces.POST("", r.workspaces.Create)
+			workspaces.PATCH("/:id", r.workspaces.Update)
+			workspaces.GET("/:id/members", r.workspaces.ListMembers)
+			workspaces.POST("/:id/members", r.workspaces.AddMember)
+			workspaces.DELETE("/:id/members/:userId", r.workspaces.RemoveMember)
+		}
+
+		projects := api.Group("/projects")
+		projects.Use(r.requireWorkspace, r.idempotency)
+		{
+			projects.POST("", r.projects.Create)
+			projects.GET("", r.projects.List)
+			projects.GET("/lookup", r.projects.Lookup)
+			projects.PUT("/by-name/:name", r.projects.GetOrCreate)
+			projects.POST("/merge", r.projects.Merge)
+			projects.GET("/:id", r.projects.Get)
+			projects.PATCH("/:id", r.projects.Update)
+			projects.DELETE("/:id", r.projects.Delete)
+			projects.POST("/:id/restore", r.trash.Restore)
+			projects.POST("/:id/duplicate", r.projects.Duplicate)
+			projects.POST("/:id/split", r.projects.Split)
+			projects.PUT("/:id/archive", r.projects.Archive)
+			projects.DELETE("/:id/archive", r.projects.Unarchive)
+			projects.PUT("/:id/pin", r.projects.Pin)
+			projects.DELETE("/:id/pin", r.projects.Unpin)
+			projects.PUT("/:id/favourite", r.projects.Favourite)
+			projects.DELETE("/:id/favourite", r.projects.Unfavourite)
+			projects.GET("/:id/document", r.projects.GetDocument)
+			projects.GET("/:id/history", r.documents.History)
+			projects.GET("/:id/diff", r.documents.Diff)
+			projects.POST("/:id/share-links", r.shareLinks.Create)
+			projects.GET("/:id/share-links", r.shareLinks.List)
+			projects.DELETE("/:id/share-links/:linkId", r.shareLinks.Revoke)
+			projects.POST("/:id/transfer", r.workspaces.TransferProject)
+			projects.POST("/:id/template", r.templates.SaveProject)
+		}
+
+		templates := api.Group("/templates")
+		templates.Use(r.requireWorkspace)
+		{
+			templates.POST("", r.templates.Create)
+			templates.GET("", r.templates.List)
+			templates.GET("/:id", r.templates.Get)
+			templates.PATCH("/:id", r.templates.Update)
+			templates.DELETE("/:id", r.templates.Delete)
+		}
+
+		trash := api.Group("/trash")
+		trash.Use(r.requireWorkspace)
+		{
+			trash.GET("", r.trash.List)
+			trash.DELETE("/:id", r.trash.Purge)
+		}
+
+		api.GET("/audit", r.requireWorkspace, r.audit.List)
+
+		webhooks := api.Group("/webhooks")
+		webhooks.Use(r.requireWorkspace)
+		{
+			webhooks.POST("", r.webhooks.Create)
+			webhooks.GET("", r.webhooks.List)
+			webhooks.GET("/:id", r.webhooks.Get)
+			webhooks.PATCH("/:id", r.webhooks.Update)
+			webhooks.DELETE("/:id", r.webhooks.Delete)
+			webhooks.GET("/:id/deliveries", r.webhooks.ListDeliveries)
+		}
+
+		documents := api.Group("/documents")
+		documents.Use(r.requireWorkspace, r.idempotency)
+		{
+			documents.PUT("/:id", r.documents.Update)
+		}
+
+		api.POST("/mcp", r.requireWorkspace, r.mcp.Serve)
+	}
+
+	// Public share links
+	router.GET("/s/:token", r.rateLimit, r.shareLinks.View)
+	router.POST("/s/:token", r.rateLimit, r.shareLinks.View)
+
+	// Health check
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	return nil
+}
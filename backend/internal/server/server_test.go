@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warriorguo/md-editor/backend/internal/openapi"
+)
+
+// TestRoutesMatchOpenAPI keeps the OpenAPI document and the router from
+// drifting apart: every route must be documented and every documented
+// operation must be served.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Failed to load OpenAPI document: %v", err)
+	}
+
+	router, err := NewRouter(Services{}, Options{})
+	if err != nil {
+		t.Fatalf("Failed to set up router: %v", err)
+	}
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + openapi.PathFromGin(route.Path)
+		registered[key] = true
+
+		item := spec.Paths.Value(openapi.PathFromGin(route.Path))
+		if item == nil || item.GetOperation(route.Method) == nil {
+			t.Errorf("%s is not in the OpenAPI document", key)
+		}
+	}
+
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			key := strings.ToUpper(method) + " " + path
+			if !registered[key] {
+				t.Errorf("%s is documented but not registered", key)
+			}
+		}
+	}
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router, err := NewRouter(Services{}, Options{AllowedOrigins: []string{"https://notes.example.com"}})
+	if err != nil {
+		t.Fatalf("Failed to set up router: %v", err)
+	}
+
+	for origin, allowed := range map[string]bool{
+		"https://notes.example.com": true,
+		"http://localhost:5173":     false,
+	} {
+		req := httptest.NewRequest(http.MethodOptions, "/api/projects", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin") == origin; got != allowed {
+			t.Errorf("Expected origin %s allowed to be %v, got status %d and headers %v", origin, allowed, w.Code, w.Header())
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+type AuditService struct {
+	auditRepo repository.AuditStore
+}
+
+func NewAuditService(auditRepo repository.AuditStore) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+func (s *AuditService) Record(ctx context.Context, event *models.AuditEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.ActorName == "" {
+		event.ActorName = models.AuditActorAnonymous
+	}
+
+	return s.auditRepo.Create(ctx, event)
+}
+
+func (s *AuditService) List(ctx context.Context, filter models.AuditFilter) (*models.AuditListResponse, error) {
+	if filter.Limit < 1 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	events, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AuditListResponse{Events: events}
+	if len(events) == filter.Limit {
+		response.NextBefore = events[len(events)-1].ID
+	}
+
+	return response, nil
+}
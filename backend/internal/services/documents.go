@@ -3,63 +3,247 @@ package services
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/metrics"
 	"github.com/warriorguo/md-editor/backend/internal/models"
 	"github.com/warriorguo/md-editor/backend/internal/repository"
 )
 
 var (
-	ErrDocumentNotFound    = errors.New("document not found")
-	ErrVersionConflict     = errors.New("version conflict")
+	ErrDocumentNotFound = apperror.New(http.StatusNotFound, "document_not_found", "document not found")
+	ErrVersionConflict  = apperror.New(http.StatusConflict, "version_conflict", "version conflict")
+	ErrDocumentTooLarge = apperror.New(http.StatusRequestEntityTooLarge, "document_too_large", "document too large")
+	ErrHistoryDisabled  = apperror.New(http.StatusNotImplemented, "history_disabled", "document history is not enabled")
 )
 
+// DocumentHistory keeps every saved document version, as the git storage
+// mode does. Versions are queued when they are saved and committed by the
+// HistoryCommitter once the save has been.
+type DocumentHistory interface {
+	// Commit commits a version of the document of project. Committing a
+	// version that is already committed does nothing.
+	Commit(ctx context.Context, project *models.Project, doc *models.Document, author string) error
+	// Push sends the commits on, if the history is replicated.
+	Push(ctx context.Context) error
+	// Log returns the commits of a project's document, newest first. A
+	// limit of zero returns all of them.
+	Log(ctx context.Context, projectID uuid.UUID, limit int) ([]models.DocumentCommit, error)
+	// Diff returns the unified diff between two commits; an empty from
+	// diffs against the empty document.
+	Diff(ctx context.Context, projectID uuid.UUID, from, to string) (string, error)
+}
+
 type DocumentService struct {
-	documentRepo *repository.DocumentRepository
+	tx              repository.Transactor
+	documentRepo    repository.DocumentStore
+	projectRepo     repository.ProjectStore
+	events          EventPublisher
+	history         DocumentHistory
+	maxContentBytes int
 }
 
-func NewDocumentService(documentRepo *repository.DocumentRepository) *DocumentService {
+// NewDocumentService creates the service. Documents larger than
+// maxContentBytes are rejected; zero means no limit. history may be nil,
+// which disables the history and diff endpoints.
+func NewDocumentService(tx repository.Transactor, documentRepo repository.DocumentStore, projectRepo repository.ProjectStore, events EventPublisher, history DocumentHistory, maxContentBytes int) *DocumentService {
 	return &DocumentService{
-		documentRepo: documentRepo,
+		tx:              tx,
+		documentRepo:    documentRepo,
+		projectRepo:     projectRepo,
+		events:          events,
+		history:         history,
+		maxContentBytes: maxContentBytes,
 	}
 }
 
-func (s *DocumentService) GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error) {
-	doc, err := s.documentRepo.GetByID(ctx, id)
+func (s *DocumentService) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Document, error) {
+	doc, _, err := s.getWorkspaceDocument(ctx, workspaceID, id)
+	return doc, err
+}
+
+// Update saves a new version of the document on behalf of author.
+func (s *DocumentService) Update(ctx context.Context, workspaceID, id uuid.UUID, contentMD string, expectedVersion int, author string) (*models.Document, error) {
+	if s.maxContentBytes > 0 && len(contentMD) > s.maxContentBytes {
+		return nil, ErrDocumentTooLarge
+	}
+
+	var doc *models.Document
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		// First check if document exists
+		existing, project, err := s.getWorkspaceDocument(ctx, workspaceID, id)
+		if err != nil {
+			return err
+		}
+		if project.ArchivedAt != nil {
+			return ErrProjectArchived
+		}
+
+		// Check version for conflict
+		if existing.Version != expectedVersion {
+			return versionConflict()
+		}
+
+		doc, err = s.documentRepo.Update(ctx, id, contentMD, expectedVersion)
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			// This can happen if version changed between check and update
+			return versionConflict()
+		}
+		if err := queueHistory(ctx, s.history, s.documentRepo, doc, author); err != nil {
+			return err
+		}
+		return publishDocumentUpdated(ctx, s.events, workspaceID, doc, expectedVersion)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if doc == nil {
-		return nil, ErrDocumentNotFound
-	}
+	documentSaved(doc)
 
 	return doc, nil
 }
 
-func (s *DocumentService) Update(ctx context.Context, id uuid.UUID, contentMD string, expectedVersion int) (*models.Document, error) {
-	// First check if document exists
-	existing, err := s.documentRepo.GetByID(ctx, id)
+// History returns up to limit commits of the project's document, newest
+// first.
+func (s *DocumentService) History(ctx context.Context, workspaceID, projectID uuid.UUID, limit int) ([]models.DocumentCommit, error) {
+	if s.history == nil {
+		return nil, ErrHistoryDisabled
+	}
+	if _, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.history.Log(ctx, projectID, limit)
+}
+
+// Diff returns the changes to the project's document between the committed
+// versions from and to. A to of zero selects the latest commit and a from of
+// zero the commit before to.
+func (s *DocumentService) Diff(ctx context.Context, workspaceID, projectID uuid.UUID, from, to int) (*models.DocumentDiff, error) {
+	if s.history == nil {
+		return nil, ErrHistoryDisabled
+	}
+	if _, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	commits, err := s.history.Log(ctx, projectID, 0)
 	if err != nil {
 		return nil, err
 	}
-	if existing == nil {
-		return nil, ErrDocumentNotFound
+
+	toCommit, fromCommit, err := selectDiffCommits(commits, from, to)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check version for conflict
-	if existing.Version != expectedVersion {
-		return nil, ErrVersionConflict
+	result := &models.DocumentDiff{ToVersion: toCommit.Version}
+	fromHash := ""
+	if fromCommit != nil {
+		result.FromVersion = fromCommit.Version
+		fromHash = fromCommit.Hash
 	}
 
-	// Update document
-	doc, err := s.documentRepo.Update(ctx, id, contentMD, expectedVersion)
+	result.Diff, err = s.history.Diff(ctx, projectID, fromHash, toCommit.Hash)
 	if err != nil {
 		return nil, err
 	}
+
+	return result, nil
+}
+
+// selectDiffCommits finds the commits of versions to and from in commits,
+// which are ordered newest first. A to of zero selects the newest commit and
+// a from of zero the one before to, which is nil for the first commit.
+func selectDiffCommits(commits []models.DocumentCommit, from, to int) (toCommit, fromCommit *models.DocumentCommit, err error) {
+	toIndex := -1
+	for i := range commits {
+		if to == 0 || commits[i].Version == to {
+			toIndex = i
+			break
+		}
+	}
+	if toIndex < 0 {
+		return nil, nil, ErrRevisionNotFound
+	}
+	toCommit = &commits[toIndex]
+
+	if from == 0 {
+		if toIndex+1 < len(commits) {
+			fromCommit = &commits[toIndex+1]
+		}
+		return toCommit, fromCommit, nil
+	}
+
+	for i := range commits {
+		if commits[i].Version == from {
+			return toCommit, &commits[i], nil
+		}
+	}
+
+	return nil, nil, ErrRevisionNotFound
+}
+
+// getWorkspaceDocument loads a document whose project is live and belongs to
+// workspaceID, along with the project.
+func (s *DocumentService) getWorkspaceDocument(ctx context.Context, workspaceID, id uuid.UUID) (*models.Document, *models.Project, error) {
+	doc, err := s.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
 	if doc == nil {
-		// This can happen if version changed between check and update
-		return nil, ErrVersionConflict
+		return nil, nil, ErrDocumentNotFound
 	}
 
-	return doc, nil
+	project, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, doc.ProjectID)
+	if err != nil {
+		if errors.Is(err, ErrProjectNotFound) {
+			return nil, nil, ErrDocumentNotFound
+		}
+		return nil, nil, err
+	}
+
+	return doc, project, nil
+}
+
+// queueHistory queues a saved version of doc to be committed to history, if
+// there is one. It is called inside the transaction that saves the version,
+// so that only saved versions are committed, and leaves the commit itself to
+// the HistoryCommitter so that git never holds up or fails a save.
+func queueHistory(ctx context.Context, history DocumentHistory, documentRepo repository.DocumentStore, doc *models.Document, author string) error {
+	if history == nil {
+		return nil
+	}
+	return documentRepo.EnqueueCommit(ctx, doc.ID, doc.Version, author)
+}
+
+// publishDocumentUpdated publishes the save of doc, which replaced
+// previousVersion.
+func publishDocumentUpdated(ctx context.Context, publisher EventPublisher, workspaceID uuid.UUID, doc *models.Document, previousVersion int) error {
+	return publishEvent(ctx, publisher, workspaceID, models.EventDocumentUpdated, map[string]interface{}{
+		"document": map[string]interface{}{
+			"id":        doc.ID,
+			"projectId": doc.ProjectID,
+			"version":   doc.Version,
+			"updatedAt": doc.UpdatedAt,
+		},
+		"previousVersion": previousVersion,
+	})
+}
+
+// documentSaved counts a newly saved version of doc.
+func documentSaved(doc *models.Document) {
+	metrics.DocumentsSaved.Inc()
+	metrics.DocumentBytesWritten.Add(float64(len(doc.ContentMD)))
+}
+
+// versionConflict counts a save rejected for being based on an outdated
+// version and returns ErrVersionConflict.
+func versionConflict() error {
+	metrics.VersionConflicts.Inc()
+	return ErrVersionConflict
 }
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
 )
 
 func TestErrDocumentNotFound(t *testing.T) {
@@ -30,8 +36,180 @@ func TestErrorComparison(t *testing.T) {
 }
 
 func TestNewDocumentService(t *testing.T) {
-	service := NewDocumentService(nil)
+	service := NewDocumentService(nil, nil, nil, nil, nil, 0)
 	if service == nil {
 		t.Error("Expected non-nil service")
 	}
 }
+
+func TestDocumentServiceUpdateTooLarge(t *testing.T) {
+	service := NewDocumentService(nil, nil, nil, nil, nil, 8)
+
+	_, err := service.Update(context.Background(), uuid.New(), uuid.New(), "more than eight bytes", 1, "alice")
+	if !errors.Is(err, ErrDocumentTooLarge) {
+		t.Errorf("Expected ErrDocumentTooLarge, got %v", err)
+	}
+}
+
+func TestDocumentServiceHistoryDisabled(t *testing.T) {
+	service := NewDocumentService(nil, nil, nil, nil, nil, 0)
+
+	if _, err := service.History(context.Background(), uuid.New(), uuid.New(), 10); !errors.Is(err, ErrHistoryDisabled) {
+		t.Errorf("Expected ErrHistoryDisabled, got %v", err)
+	}
+	if _, err := service.Diff(context.Background(), uuid.New(), uuid.New(), 0, 0); !errors.Is(err, ErrHistoryDisabled) {
+		t.Errorf("Expected ErrHistoryDisabled, got %v", err)
+	}
+}
+
+func TestSelectDiffCommits(t *testing.T) {
+	commits := []models.DocumentCommit{{Hash: "c", Version: 5}, {Hash: "b", Version: 3}, {Hash: "a", Version: 2}}
+
+	tests := []struct {
+		name     string
+		from, to int
+		wantFrom string
+		wantTo   string
+		wantErr  error
+	}{
+		{name: "latest", wantFrom: "b", wantTo: "c"},
+		{name: "previous of to", to: 3, wantFrom: "a", wantTo: "b"},
+		{name: "first commit", to: 2, wantTo: "a"},
+		{name: "explicit range", from: 2, to: 5, wantFrom: "a", wantTo: "c"},
+		{name: "unknown to", to: 4, wantErr: ErrRevisionNotFound},
+		{name: "unknown from", from: 1, wantErr: ErrRevisionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toCommit, fromCommit, err := selectDiffCommits(commits, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if toCommit.Hash != tt.wantTo {
+				t.Errorf("Expected to %q, got %q", tt.wantTo, toCommit.Hash)
+			}
+			gotFrom := ""
+			if fromCommit != nil {
+				gotFrom = fromCommit.Hash
+			}
+			if gotFrom != tt.wantFrom {
+				t.Errorf("Expected from %q, got %q", tt.wantFrom, gotFrom)
+			}
+		})
+	}
+}
+
+// recordingHistory records the versions committed to it, or fails.
+type recordingHistory struct {
+	commits  []string
+	fail     bool
+	failPush bool
+	pushed   int
+}
+
+func (h *recordingHistory) Commit(ctx context.Context, project *models.Project, doc *models.Document, author string) error {
+	if h.fail {
+		return errors.New("git unavailable")
+	}
+	h.commits = append(h.commits, fmt.Sprintf("%s@%d by %s", project.Name, doc.Version, author))
+	return nil
+}
+
+func (h *recordingHistory) Push(ctx context.Context) error {
+	if h.failPush {
+		return errors.New("remote unavailable")
+	}
+	h.pushed = len(h.commits)
+	return nil
+}
+
+func (h *recordingHistory) Log(ctx context.Context, projectID uuid.UUID, limit int) ([]models.DocumentCommit, error) {
+	return nil, nil
+}
+
+func (h *recordingHistory) Diff(ctx context.Context, projectID uuid.UUID, from, to string) (string, error) {
+	return "", nil
+}
+
+func TestDocumentWritesAreCommitted(t *testing.T) {
+	ctx := context.Background()
+	ws := models.DefaultWorkspaceID
+	stores := newSQLiteStores(t)
+	history := &recordingHistory{}
+	projects := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, history, PageSizes{})
+	documents := NewDocumentService(stores.Tx, stores.Documents, stores.Projects, nil, history, 0)
+
+	notes, err := projects.Create(ctx, ws, "Notes", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	doc, err := projects.GetDocument(ctx, ws, notes.ID)
+	if err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+	if _, err := documents.Update(ctx, ws, doc.ID, "# Notes\n\n# Later\n\nideas\n", doc.Version, "bob"); err != nil {
+		t.Fatalf("Failed to update document: %v", err)
+	}
+	if _, err := projects.Split(ctx, ws, notes.ID, models.SplitProjectRequest{Headings: []string{"Later"}}, "carol"); err != nil {
+		t.Fatalf("Failed to split project: %v", err)
+	}
+	other, _, err := projects.GetOrCreate(ctx, ws, "Other", "dave")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := projects.Merge(ctx, ws, models.MergeProjectsRequest{ProjectIDs: []uuid.UUID{notes.ID, other.ID}}, "erin"); err != nil {
+		t.Fatalf("Failed to merge projects: %v", err)
+	}
+
+	if len(history.commits) != 0 {
+		t.Errorf("Expected no commits before the committer runs, got %v", history.commits)
+	}
+
+	// Neither a failing commit nor a failing push fails a save.
+	history.fail = true
+	history.failPush = true
+	doc, err = projects.GetDocument(ctx, ws, notes.ID)
+	if err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+	if _, err := documents.Update(ctx, ws, doc.ID, "# Notes\n\nmore", doc.Version, "frank"); err != nil {
+		t.Fatalf("Expected the update to succeed while git fails, got %v", err)
+	}
+
+	committer := NewHistoryCommitter(stores.Documents, history)
+	if _, err := committer.CommitPending(ctx); err == nil {
+		t.Error("Expected the committer to report the failed commit")
+	}
+	history.fail = false
+	if _, err := committer.CommitPending(ctx); err != nil {
+		t.Fatalf("Expected the push failure not to be returned, got %v", err)
+	}
+
+	want := []string{
+		"Notes@1 by alice",
+		"Notes@2 by bob",
+		"Notes@3 by carol",
+		"Later@1 by carol",
+		"Other@1 by dave",
+		"Notes@4 by erin",
+		"Notes@5 by frank",
+	}
+	if strings.Join(history.commits, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected commits %v, got %v", want, history.commits)
+	}
+	if history.pushed != 0 {
+		t.Errorf("Expected the push to have failed, got %d commits pushed", history.pushed)
+	}
+
+	history.failPush = false
+	if committed, err := committer.CommitPending(ctx); err != nil || committed != 0 {
+		t.Errorf("Expected nothing left to commit, got %d, %v", committed, err)
+	}
+	if history.pushed != len(want) {
+		t.Errorf("Expected the failed push to be retried, got %d commits pushed", history.pushed)
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// EventPublisher is notified of domain events inside the transaction that
+// persists them, so that an event is recorded if and only if its change is.
+// An error rolls the change back.
+type EventPublisher interface {
+	Publish(ctx context.Context, workspaceID uuid.UUID, eventType string, data interface{}) error
+}
+
+func publishEvent(ctx context.Context, publisher EventPublisher, workspaceID uuid.UUID, eventType string, data interface{}) error {
+	if publisher == nil {
+		return nil
+	}
+	return publisher.Publish(ctx, workspaceID, eventType, data)
+}
+
+// Publishers publishes every event to each of its publishers in turn,
+// stopping at the first error.
+type Publishers []EventPublisher
+
+func (p Publishers) Publish(ctx context.Context, workspaceID uuid.UUID, eventType string, data interface{}) error {
+	for _, publisher := range p {
+		if err := publishEvent(ctx, publisher, workspaceID, eventType, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+const historyCommitBatchSize = 50
+
+// HistoryCommitter commits the document versions queued by saves to the
+// history, in the order they were saved, and pushes them.
+type HistoryCommitter struct {
+	documentRepo repository.DocumentStore
+	history      DocumentHistory
+	// unpushed is set while commits have been made that no push has sent.
+	unpushed bool
+}
+
+func NewHistoryCommitter(documentRepo repository.DocumentStore, history DocumentHistory) *HistoryCommitter {
+	return &HistoryCommitter{documentRepo: documentRepo, history: history}
+}
+
+// Run calls CommitPending every interval until ctx is cancelled, and right
+// away again while there is a backlog.
+func (c *HistoryCommitter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		committed, err := c.CommitPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to commit document history", "error", err)
+		}
+		if err == nil && committed == historyCommitBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CommitPending commits a batch of queued versions and returns how many it
+// committed. A version that fails to commit stops the batch and is retried by
+// the next call, so that the versions of a document are committed in order.
+// Failed pushes are only logged and retried by the next call, since a push
+// sends every commit made before it.
+func (c *HistoryCommitter) CommitPending(ctx context.Context) (int, error) {
+	pending, err := c.documentRepo.ListPendingCommits(ctx, historyCommitBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Whatever was committed is pushed, even if the batch stops early.
+	defer c.push(ctx)
+
+	committed := 0
+	for _, commit := range pending {
+		if err := c.history.Commit(ctx, &commit.Project, &commit.Document, commit.Author); err != nil {
+			return committed, fmt.Errorf("commit version %d of project %s: %w", commit.Document.Version, commit.Project.ID, err)
+		}
+		c.unpushed = true
+		if err := c.documentRepo.DeleteCommit(ctx, commit.ID); err != nil {
+			return committed, err
+		}
+		committed++
+	}
+
+	return committed, nil
+}
+
+// push pushes the commits not yet sent, logging a failure.
+func (c *HistoryCommitter) push(ctx context.Context) {
+	if !c.unpushed {
+		return
+	}
+	if err := c.history.Push(ctx); err != nil {
+		slog.WarnContext(ctx, "Failed to push document history", "error", err)
+		return
+	}
+	c.unpushed = false
+}
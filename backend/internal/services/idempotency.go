@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+var (
+	ErrIdempotencyKeyReused     = apperror.New(http.StatusUnprocessableEntity, "idempotency_key_reused", "idempotency key reused with a different request")
+	ErrIdempotencyKeyInProgress = apperror.New(http.StatusConflict, "idempotency_key_in_progress", "request with this idempotency key is still in progress")
+)
+
+// idempotencyStaleAfter is how long a claimed key may stay without a stored
+// response before another request may take it over.
+const idempotencyStaleAfter = time.Minute
+
+// IdempotencyService remembers the responses to requests made with an
+// idempotency key for the retention period so that retries can be answered
+// without repeating the mutation.
+type IdempotencyService struct {
+	repo      repository.IdempotencyStore
+	retention time.Duration
+}
+
+func NewIdempotencyService(repo repository.IdempotencyStore, retention time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, retention: retention}
+}
+
+// Begin claims key for a request. It returns nil if the caller should run the
+// request and record its outcome with Complete or Release, or the stored
+// response of an earlier identical request that should be replayed.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, requestHash string) (*models.IdempotentResponse, error) {
+	now := time.Now()
+	claimed, err := s.repo.Claim(ctx, scope, key, requestHash, now.Add(-s.retention), now.Add(-idempotencyStaleAfter))
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	stored, err := s.repo.Get(ctx, scope, key)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		// Purged between the two queries; treat it like a concurrent request.
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return checkStoredResponse(stored, requestHash)
+}
+
+// checkStoredResponse decides whether stored may answer a request with
+// requestHash.
+func checkStoredResponse(stored *models.IdempotentResponse, requestHash string) (*models.IdempotentResponse, error) {
+	if stored.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return stored, nil
+}
+
+// Complete stores the response of a request started with Begin.
+func (s *IdempotencyService) Complete(ctx context.Context, scope, key string, resp models.IdempotentResponse) error {
+	return s.repo.Complete(ctx, scope, key, resp)
+}
+
+// Release forgets key so that the request can be retried, for responses that
+// should not be replayed.
+func (s *IdempotencyService) Release(ctx context.Context, scope, key string) error {
+	return s.repo.Delete(ctx, scope, key)
+}
+
+// RunPurge deletes expired keys every interval until ctx is cancelled.
+func (s *IdempotencyService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.repo.DeleteBefore(ctx, time.Now().Add(-s.retention)); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to purge idempotency keys", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestCheckStoredResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  models.IdempotentResponse
+		wantErr error
+	}{
+		{name: "completed", stored: models.IdempotentResponse{RequestHash: "abc", StatusCode: 201}},
+		{name: "different request", stored: models.IdempotentResponse{RequestHash: "def", StatusCode: 201}, wantErr: ErrIdempotencyKeyReused},
+		{name: "in progress", stored: models.IdempotentResponse{RequestHash: "abc"}, wantErr: ErrIdempotencyKeyInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := checkStoredResponse(&tt.stored, "abc")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && resp == nil {
+				t.Error("Expected the stored response to be replayed")
+			}
+		})
+	}
+}
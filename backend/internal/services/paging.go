@@ -0,0 +1,22 @@
+package services
+
+// PageSizes bounds the page size of list results. A zero PageSizes uses
+// the defaults of 20 and at most 100.
+type PageSizes struct {
+	Default int
+	Max     int
+}
+
+// size returns the page size to use for a request asking for n.
+func (p PageSizes) size(n int) int {
+	if p.Max < 1 {
+		p.Max = 100
+	}
+	if p.Default < 1 || p.Default > p.Max {
+		p.Default = min(20, p.Max)
+	}
+	if n < 1 || n > p.Max {
+		return p.Default
+	}
+	return n
+}
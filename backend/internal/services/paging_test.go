@@ -0,0 +1,24 @@
+package services
+
+import "testing"
+
+func TestPageSizes(t *testing.T) {
+	tests := []struct {
+		sizes PageSizes
+		n     int
+		want  int
+	}{
+		{PageSizes{}, 0, 20},
+		{PageSizes{}, 100, 100},
+		{PageSizes{}, 101, 20},
+		{PageSizes{Default: 50, Max: 500}, 0, 50},
+		{PageSizes{Default: 50, Max: 500}, 400, 400},
+		{PageSizes{Default: 50, Max: 500}, 501, 50},
+		{PageSizes{Max: 10}, 0, 10},
+	}
+	for _, tt := range tests {
+		if got := tt.sizes.size(tt.n); got != tt.want {
+			t.Errorf("%+v.size(%d) = %d, want %d", tt.sizes, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+const previewExcerptRunes = 200
+
+// markdownLines calls fn for every line of content outside fenced code
+// blocks, with the heading text if the line is an ATX heading.
+func markdownLines(content string, fn func(line, heading string, isHeading bool)) {
+	inFence := false
+	fence := ""
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+				continue
+			}
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+				continue
+			}
+		}
+		if inFence {
+			continue
+		}
+
+		heading, isHeading := atxHeading(line)
+		fn(trimmed, heading, isHeading)
+	}
+}
+
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// atxHeading reports whether line is a "# Heading" style heading and returns
+// its text.
+func atxHeading(line string) (string, bool) {
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent > 3 {
+		return "", false
+	}
+	line = line[indent:]
+
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level < 1 || level > 6 {
+		return "", false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#")), true
+}
+
+// buildProjectPreview returns the first heading and the opening prose of a
+// document.
+func buildProjectPreview(content string) *models.ProjectPreview {
+	preview := &models.ProjectPreview{}
+	var excerpt strings.Builder
+	runes := 0
+
+	markdownLines(content, func(line, heading string, isHeading bool) {
+		if isHeading {
+			if preview.Title == "" {
+				preview.Title = heading
+			}
+			return
+		}
+		if line == "" || runes > previewExcerptRunes {
+			return
+		}
+
+		line = strings.TrimSpace(strings.TrimLeft(line, ">-*+ "))
+		if excerpt.Len() > 0 {
+			excerpt.WriteByte(' ')
+			runes++
+		}
+		excerpt.WriteString(line)
+		runes += len([]rune(line))
+	})
+
+	preview.Excerpt = truncateRunes(excerpt.String(), previewExcerptRunes)
+	return preview
+}
+
+// buildProjectStats counts the words and headings of a document.
+func buildProjectStats(summary models.DocumentSummary) *models.ProjectStats {
+	stats := &models.ProjectStats{
+		Version:    summary.Version,
+		EditedAt:   summary.UpdatedAt,
+		LastEditor: summary.LastEditor,
+	}
+
+	markdownLines(summary.ContentMD, func(line, heading string, isHeading bool) {
+		if isHeading {
+			stats.HeadingCount++
+			line = heading
+		}
+		for _, field := range strings.Fields(line) {
+			if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+				stats.WordCount++
+			}
+		}
+	})
+
+	return stats
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
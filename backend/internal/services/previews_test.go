@@ -0,0 +1,71 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+const previewDocument = `# Meeting notes
+
+Decisions from the **weekly** sync.
+
+- Ship the importer
+- Review #42
+
+` + "```go\n# not a heading\nfmt.Println(\"skipped\")\n```" + `
+
+## Follow-ups ##
+#hashtag is not a heading
+`
+
+func TestBuildProjectPreview(t *testing.T) {
+	preview := buildProjectPreview(previewDocument)
+
+	if preview.Title != "Meeting notes" {
+		t.Errorf("Expected title 'Meeting notes', got %q", preview.Title)
+	}
+
+	wantExcerpt := "Decisions from the **weekly** sync. Ship the importer Review #42 #hashtag is not a heading"
+	if preview.Excerpt != wantExcerpt {
+		t.Errorf("Expected excerpt %q, got %q", wantExcerpt, preview.Excerpt)
+	}
+}
+
+func TestBuildProjectPreviewTruncates(t *testing.T) {
+	preview := buildProjectPreview(strings.Repeat("word ", 100))
+
+	if preview.Title != "" {
+		t.Errorf("Expected no title, got %q", preview.Title)
+	}
+	if got := len([]rune(preview.Excerpt)); got > previewExcerptRunes+1 {
+		t.Errorf("Expected excerpt of at most %d runes, got %d", previewExcerptRunes+1, got)
+	}
+	if !strings.HasSuffix(preview.Excerpt, "…") {
+		t.Errorf("Expected truncated excerpt to end with an ellipsis, got %q", preview.Excerpt)
+	}
+}
+
+func TestBuildProjectStats(t *testing.T) {
+	editor := "alice"
+	stats := buildProjectStats(models.DocumentSummary{
+		ContentMD:  previewDocument,
+		Version:    7,
+		LastEditor: &editor,
+	})
+
+	if stats.HeadingCount != 2 {
+		t.Errorf("Expected 2 headings, got %d", stats.HeadingCount)
+	}
+	// Code blocks and bare markup do not count as words.
+	if stats.WordCount != 18 {
+		t.Errorf("Expected 18 words, got %d", stats.WordCount)
+	}
+	if stats.Version != 7 {
+		t.Errorf("Expected version 7, got %d", stats.Version)
+	}
+	if stats.LastEditor == nil || *stats.LastEditor != "alice" {
+		t.Errorf("Expected last editor alice, got %v", stats.LastEditor)
+	}
+}
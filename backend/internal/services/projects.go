@@ -2,67 +2,342 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/metrics"
 	"github.com/warriorguo/md-editor/backend/internal/models"
 	"github.com/warriorguo/md-editor/backend/internal/repository"
 )
 
 var (
-	ErrProjectNotFound = errors.New("project not found")
+	ErrProjectNotFound = apperror.New(http.StatusNotFound, "project_not_found", "project not found")
+	ErrInvalidCursor   = apperror.New(http.StatusBadRequest, "invalid_cursor", "invalid cursor")
+	// ErrProjectNameTaken is returned in workspaces that require unique
+	// project names.
+	ErrProjectNameTaken = apperror.New(http.StatusConflict, "project_name_taken", "project name already taken")
+	ErrInvalidMerge     = apperror.New(http.StatusBadRequest, "invalid_merge", "projects to merge must be distinct")
+	ErrSectionNotFound  = apperror.New(http.StatusBadRequest, "section_not_found", "section not found")
+	// ErrProjectArchived is returned when modifying an archived project.
+	ErrProjectArchived = apperror.New(http.StatusConflict, "project_archived", "project is archived")
 )
 
+// ProjectMovedError is returned for a project that was merged into another
+// one.
+type ProjectMovedError struct {
+	To uuid.UUID
+}
+
+func (e *ProjectMovedError) Error() string {
+	return fmt.Sprintf("project moved to %s", e.To)
+}
+
 type ProjectService struct {
-	projectRepo  *repository.ProjectRepository
-	documentRepo *repository.DocumentRepository
+	tx           repository.Transactor
+	projectRepo  repository.ProjectStore
+	documentRepo repository.DocumentStore
+	templateRepo repository.TemplateStore
+	events       EventPublisher
+	history      DocumentHistory
+	pageSizes    PageSizes
 }
 
-func NewProjectService(projectRepo *repository.ProjectRepository, documentRepo *repository.DocumentRepository) *ProjectService {
+// NewProjectService creates the service. history may be nil; otherwise every
+// document the service writes is queued to be committed to it.
+func NewProjectService(tx repository.Transactor, projectRepo repository.ProjectStore, documentRepo repository.DocumentStore, templateRepo repository.TemplateStore, events EventPublisher, history DocumentHistory, pageSizes PageSizes) *ProjectService {
 	return &ProjectService{
+		tx:           tx,
 		projectRepo:  projectRepo,
 		documentRepo: documentRepo,
+		templateRepo: templateRepo,
+		events:       events,
+		history:      history,
+		pageSizes:    pageSizes,
 	}
 }
 
-func (s *ProjectService) Create(ctx context.Context, name string) (*models.Project, error) {
-	project, err := s.projectRepo.Create(ctx, name)
+// Create creates a project with an empty document on behalf of author.
+func (s *ProjectService) Create(ctx context.Context, workspaceID uuid.UUID, name, author string) (*models.Project, error) {
+	return s.create(ctx, workspaceID, name, "", author)
+}
+
+// CreateFromTemplate creates a project whose document is the template with
+// its placeholders filled in. The built-in title, date and user values can be
+// overridden by variables; the user is author.
+func (s *ProjectService) CreateFromTemplate(ctx context.Context, workspaceID uuid.UUID, name, author string, templateID uuid.UUID, variables map[string]string) (*models.Project, error) {
+	template, err := s.templateRepo.GetByID(ctx, workspaceID, templateID)
 	if err != nil {
 		return nil, err
 	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	values := map[string]string{
+		"title": name,
+		"date":  time.Now().Format("2006-01-02"),
+		"user":  author,
+	}
+	for k, v := range variables {
+		values[k] = v
+	}
+
+	return s.create(ctx, workspaceID, name, RenderTemplate(template.ContentMD, values), author)
+}
 
-	// Create empty document for the project
-	_, err = s.documentRepo.Create(ctx, project.ID)
+// create inserts the project and its document in one transaction, so a
+// project never exists without a document.
+func (s *ProjectService) create(ctx context.Context, workspaceID uuid.UUID, name, contentMD, author string) (*models.Project, error) {
+	var project *models.Project
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		project, err = s.insertProject(ctx, workspaceID, name, contentMD, author)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	metrics.ProjectsCreated.Inc()
 
 	return project, nil
 }
 
-func (s *ProjectService) List(ctx context.Context, page, pageSize int) (*models.ProjectListResponse, error) {
-	if page < 1 {
-		page = 1
+// insertProject creates a project with its document, queues the document
+// for the history and publishes the creation. It must be called in a
+// transaction.
+func (s *ProjectService) insertProject(ctx context.Context, workspaceID uuid.UUID, name, contentMD, author string) (*models.Project, error) {
+	project, err := s.projectRepo.Create(ctx, workspaceID, name)
+	if err != nil {
+		return nil, projectNameError(err)
+	}
+	doc, err := s.documentRepo.Create(ctx, project.ID, contentMD)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	if err := queueHistory(ctx, s.history, s.documentRepo, doc, author); err != nil {
+		return nil, err
 	}
+	if err := s.publishProjectCreated(ctx, workspaceID, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
 
-	projects, totalCount, err := s.projectRepo.List(ctx, page, pageSize)
+func (s *ProjectService) List(ctx context.Context, q models.ProjectListQuery) (*models.ProjectListResponse, error) {
+	if q.Sort == "" {
+		q.Sort = models.ProjectSortCreatedAt
+		q.Descending = true
+	}
+	if q.Archived == "" {
+		q.Archived = models.ProjectArchivedExclude
+	}
+	if q.After != nil {
+		// Cursor pages ignore the page number.
+		q.Page = 0
+	} else if q.Page < 1 {
+		q.Page = 1
+	}
+	q.PageSize = s.pageSizes.size(q.PageSize)
+
+	// Fetch one extra row to learn whether another page follows.
+	projects, totalCount, err := s.projectRepo.List(ctx, q, q.PageSize+1)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.ProjectListResponse{
+	response := &models.ProjectListResponse{
 		Projects:   projects,
 		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-	}, nil
+		Page:       q.Page,
+		PageSize:   q.PageSize,
+	}
+	if len(projects) > q.PageSize {
+		response.Projects = projects[:q.PageSize]
+		response.NextCursor = EncodeProjectCursor(q.Sort, q.Descending, response.Projects[q.PageSize-1])
+	}
+
+	if (q.IncludePreview || q.IncludeStats) && len(response.Projects) > 0 {
+		if err := s.describeProjects(ctx, response.Projects, q.IncludePreview, q.IncludeStats); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// describeProjects fills in the preview and statistics of each project from
+// its document.
+func (s *ProjectService) describeProjects(ctx context.Context, projects []models.Project, preview, stats bool) error {
+	ids := make([]uuid.UUID, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+
+	summaries, err := s.documentRepo.ListSummaries(ctx, ids, stats)
+	if err != nil {
+		return err
+	}
+
+	for i := range projects {
+		summary, ok := summaries[projects[i].ID]
+		if !ok {
+			continue
+		}
+		if preview {
+			projects[i].Preview = buildProjectPreview(summary.ContentMD)
+		}
+		if stats {
+			projects[i].Stats = buildProjectStats(summary)
+		}
+	}
+
+	return nil
+}
+
+// EncodeProjectCursor returns an opaque cursor that resumes a listing in the
+// given order right after last.
+func EncodeProjectCursor(sort string, descending bool, last models.Project) string {
+	cursor := models.ProjectCursor{Sort: sort, Descending: descending, ID: last.ID, Pinned: last.PinnedAt != nil}
+	switch sort {
+	case models.ProjectSortName:
+		cursor.Value = last.Name
+	case models.ProjectSortUpdatedAt:
+		cursor.Value = last.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		cursor.Value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeProjectCursor parses a cursor produced by EncodeProjectCursor.
+func DecodeProjectCursor(s string) (*models.ProjectCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor models.ProjectCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	switch cursor.Sort {
+	case models.ProjectSortName:
+	case models.ProjectSortCreatedAt, models.ProjectSortUpdatedAt:
+		if _, err := time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	default:
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// Lookup returns the projects whose names match or resemble name, best
+// matches first.
+func (s *ProjectService) Lookup(ctx context.Context, workspaceID uuid.UUID, name string, limit int) (*models.ProjectLookupResponse, error) {
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	matches, err := s.projectRepo.Lookup(ctx, workspaceID, name, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProjectLookupResponse{Name: name, Matches: matches}, nil
+}
+
+// GetOrCreate returns the oldest project named name, ignoring case, creating
+// it with an empty document on behalf of author if there is none. The boolean reports whether the
+// project was created. Concurrent calls for the same name are serialized by
+// locking the name, so only one of them creates the project.
+func (s *ProjectService) GetOrCreate(ctx context.Context, workspaceID uuid.UUID, name, author string) (*models.Project, bool, error) {
+	var project *models.Project
+	var created bool
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.projectRepo.LockName(ctx, workspaceID, name); err != nil {
+			return err
+		}
+
+		var err error
+		project, err = s.projectRepo.GetByName(ctx, workspaceID, name)
+		if err != nil || project != nil {
+			return err
+		}
+
+		project, err = s.insertProject(ctx, workspaceID, name, "", author)
+		created = err == nil
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		metrics.ProjectsCreated.Inc()
+	}
+
+	return project, created, nil
+}
+
+func (s *ProjectService) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, error) {
+	project, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, id)
+	if errors.Is(err, ErrProjectNotFound) {
+		return nil, s.redirectError(ctx, workspaceID, id)
+	}
+	return project, err
+}
+
+// redirectError reports where a missing project was merged into, if anywhere.
+func (s *ProjectService) redirectError(ctx context.Context, workspaceID, id uuid.UUID) error {
+	to, err := s.projectRepo.GetRedirect(ctx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+	if to == nil {
+		return ErrProjectNotFound
+	}
+	return &ProjectMovedError{To: *to}
+}
+
+func (s *ProjectService) Update(ctx context.Context, workspaceID, id uuid.UUID, name string) (*models.Project, error) {
+	var project *models.Project
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.checkWritable(ctx, workspaceID, id); err != nil {
+			return err
+		}
+		var err error
+		project, err = s.projectRepo.Update(ctx, workspaceID, id, name)
+		if err != nil {
+			return projectNameError(err)
+		}
+		if project == nil {
+			return ErrProjectNotFound
+		}
+		return publishEvent(ctx, s.events, workspaceID, models.EventProjectRenamed, map[string]interface{}{"project": project})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
 }
 
-func (s *ProjectService) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
-	project, err := s.projectRepo.GetByID(ctx, id)
+// Archive archives or unarchives a project. Archived projects are hidden from
+// listings and search and cannot be modified until they are unarchived.
+func (s *ProjectService) Archive(ctx context.Context, workspaceID, id uuid.UUID, archived bool) (*models.Project, error) {
+	project, err := s.projectRepo.SetArchived(ctx, workspaceID, id, archived)
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +348,9 @@ func (s *ProjectService) GetByID(ctx context.Context, id uuid.UUID) (*models.Pro
 	return project, nil
 }
 
-func (s *ProjectService) Update(ctx context.Context, id uuid.UUID, name string) (*models.Project, error) {
-	project, err := s.projectRepo.Update(ctx, id, name)
+// Pin pins or unpins a project. Pinned projects are listed first.
+func (s *ProjectService) Pin(ctx context.Context, workspaceID, id uuid.UUID, pinned bool) (*models.Project, error) {
+	project, err := s.projectRepo.SetPinned(ctx, workspaceID, id, pinned)
 	if err != nil {
 		return nil, err
 	}
@@ -85,28 +361,331 @@ func (s *ProjectService) Update(ctx context.Context, id uuid.UUID, name string)
 	return project, nil
 }
 
-func (s *ProjectService) Delete(ctx context.Context, id uuid.UUID) error {
-	err := s.projectRepo.SoftDelete(ctx, id)
-	if err != nil {
-		return ErrProjectNotFound
+// SetFavourite adds a project to or removes it from the favourites of a user.
+func (s *ProjectService) SetFavourite(ctx context.Context, workspaceID, userID, id uuid.UUID, favourite bool) error {
+	if _, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, id); err != nil {
+		return err
 	}
 
-	return nil
+	if favourite {
+		return s.projectRepo.AddFavourite(ctx, userID, id)
+	}
+	return s.projectRepo.RemoveFavourite(ctx, userID, id)
 }
 
-func (s *ProjectService) GetDocument(ctx context.Context, projectID uuid.UUID) (*models.Document, error) {
-	project, err := s.projectRepo.GetByID(ctx, projectID)
+// checkWritable reports ErrProjectArchived for archived projects.
+func (s *ProjectService) checkWritable(ctx context.Context, workspaceID, id uuid.UUID) error {
+	_, err := getWritableProject(ctx, s.projectRepo, workspaceID, id)
+	return err
+}
+
+// Transfer moves a project to another workspace. The caller is responsible
+// for checking that the client may access both workspaces.
+func (s *ProjectService) Transfer(ctx context.Context, fromWorkspaceID, id, toWorkspaceID uuid.UUID) (*models.Project, error) {
+	var project *models.Project
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.checkWritable(ctx, fromWorkspaceID, id); err != nil {
+			return err
+		}
+		var err error
+		project, err = s.projectRepo.Transfer(ctx, fromWorkspaceID, id, toWorkspaceID)
+		if err != nil {
+			return projectNameError(err)
+		}
+		if project == nil {
+			return ErrProjectNotFound
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if project == nil {
-		return nil, ErrProjectNotFound
+
+	return project, nil
+}
+
+// Delete moves a project to the trash. Archived projects have to be
+// unarchived first.
+func (s *ProjectService) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.checkWritable(ctx, workspaceID, id); err != nil {
+			return err
+		}
+		if err := s.projectRepo.SoftDelete(ctx, workspaceID, id); err != nil {
+			return notFoundError(err, ErrProjectNotFound)
+		}
+		return publishEvent(ctx, s.events, workspaceID, models.EventProjectDeleted, map[string]interface{}{"projectId": id})
+	})
+}
+
+func (s *ProjectService) GetDocument(ctx context.Context, workspaceID, projectID uuid.UUID) (*models.Document, error) {
+	if _, err := s.GetByID(ctx, workspaceID, projectID); err != nil {
+		return nil, err
 	}
 
 	doc, err := s.documentRepo.GetByProjectID(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
+	if doc == nil {
+		return nil, ErrDocumentNotFound
+	}
 
 	return doc, nil
 }
+
+// Duplicate creates a copy of a project and its document on behalf of
+// author. The copy is named "<name> (copy)" unless a name is given.
+func (s *ProjectService) Duplicate(ctx context.Context, workspaceID, id uuid.UUID, name, author string) (*models.Project, error) {
+	var project *models.Project
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		source, doc, err := s.getProjectDocument(ctx, workspaceID, id)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = source.Name + " (copy)"
+		}
+
+		project, err = s.insertProject(ctx, workspaceID, name, doc.ContentMD, author)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.ProjectsCreated.Inc()
+
+	return project, nil
+}
+
+// Merge combines the documents of the listed projects into the first one on
+// behalf of author. The other projects are soft-deleted and redirect to it.
+func (s *ProjectService) Merge(ctx context.Context, workspaceID uuid.UUID, req models.MergeProjectsRequest, author string) (*models.ProjectDocumentResponse, error) {
+	seen := make(map[uuid.UUID]bool, len(req.ProjectIDs))
+	for _, id := range req.ProjectIDs {
+		if seen[id] {
+			return nil, ErrInvalidMerge
+		}
+		seen[id] = true
+	}
+
+	targetID, sourceIDs := req.ProjectIDs[0], req.ProjectIDs[1:]
+	var target *models.Project
+	var merged *models.Document
+	var previousVersion int
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		// The documents are locked so that none of them changes between
+		// being read and its project being marked as merged.
+		var contents []string
+		var targetDoc *models.Document
+		for _, id := range req.ProjectIDs {
+			project, doc, err := s.lockProjectDocument(ctx, workspaceID, id)
+			if err != nil {
+				return err
+			}
+			if project.ArchivedAt != nil {
+				return ErrProjectArchived
+			}
+			if id == targetID {
+				target, targetDoc = project, doc
+			}
+			contents = append(contents, doc.ContentMD)
+		}
+
+		content := concatenateDocuments(contents)
+		if req.Mode == models.MergeModeInterleave {
+			content = interleaveDocuments(contents)
+		}
+
+		var err error
+		previousVersion = targetDoc.Version
+		merged, err = s.documentRepo.Update(ctx, targetDoc.ID, content, targetDoc.Version)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			return versionConflict()
+		}
+
+		if req.Name != nil {
+			renamed, err := s.projectRepo.Update(ctx, workspaceID, targetID, *req.Name)
+			if err != nil {
+				return projectNameError(err)
+			}
+			if renamed == nil {
+				return ErrProjectNotFound
+			}
+			target = renamed
+		}
+
+		for _, id := range sourceIDs {
+			if err := s.projectRepo.MarkMerged(ctx, workspaceID, id, targetID); err != nil {
+				return notFoundError(err, ErrProjectNotFound)
+			}
+		}
+		if err := queueHistory(ctx, s.history, s.documentRepo, merged, author); err != nil {
+			return err
+		}
+
+		if req.Name != nil {
+			if err := publishEvent(ctx, s.events, workspaceID, models.EventProjectRenamed, map[string]interface{}{"project": target}); err != nil {
+				return err
+			}
+		}
+		if err := publishDocumentUpdated(ctx, s.events, workspaceID, merged, previousVersion); err != nil {
+			return err
+		}
+		for _, id := range sourceIDs {
+			if err := publishEvent(ctx, s.events, workspaceID, models.EventProjectDeleted, map[string]interface{}{"projectId": id, "mergedInto": targetID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	documentSaved(merged)
+
+	return &models.ProjectDocumentResponse{Project: *target, Document: *merged}, nil
+}
+
+// Split moves the top-level sections with the given headings out of a
+// project's document into new projects named after them, on behalf of
+// author.
+func (s *ProjectService) Split(ctx context.Context, workspaceID, id uuid.UUID, req models.SplitProjectRequest, author string) (*models.SplitProjectResponse, error) {
+	var source *models.Project
+	var remaining *models.Document
+	var previousVersion int
+	var created []models.Project
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		project, doc, err := s.getProjectDocument(ctx, workspaceID, id)
+		if err != nil {
+			return err
+		}
+		if project.ArchivedAt != nil {
+			return ErrProjectArchived
+		}
+		source = project
+
+		content, sections, missing := extractSections(doc.ContentMD, req.Headings)
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: %s", ErrSectionNotFound, missing[0])
+		}
+
+		previousVersion = doc.Version
+		remaining, err = s.documentRepo.Update(ctx, doc.ID, content, doc.Version)
+		if err != nil {
+			return err
+		}
+		if remaining == nil {
+			return versionConflict()
+		}
+		if err := queueHistory(ctx, s.history, s.documentRepo, remaining, author); err != nil {
+			return err
+		}
+		if err := publishDocumentUpdated(ctx, s.events, workspaceID, remaining, previousVersion); err != nil {
+			return err
+		}
+
+		created = make([]models.Project, 0, len(sections))
+		for _, section := range sections {
+			content := joinBlocks([]string{strings.Join(section.Lines, "\n")})
+			// Long headings are cut short, leaving room for the ellipsis
+			// truncateRunes appends.
+			project, err := s.insertProject(ctx, workspaceID, truncateRunes(section.Title, models.MaxProjectNameLength-1), content, author)
+			if err != nil {
+				return err
+			}
+			created = append(created, *project)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	documentSaved(remaining)
+	metrics.ProjectsCreated.Add(float64(len(created)))
+
+	return &models.SplitProjectResponse{Project: *source, Document: *remaining, Created: created}, nil
+}
+
+// getProjectDocument loads a live project of the workspace with its document.
+func (s *ProjectService) getProjectDocument(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, *models.Document, error) {
+	return s.loadProjectDocument(ctx, workspaceID, id, s.documentRepo.GetByProjectID)
+}
+
+// lockProjectDocument is getProjectDocument, but locks the document and
+// project until the transaction of ctx ends.
+func (s *ProjectService) lockProjectDocument(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, *models.Document, error) {
+	return s.loadProjectDocument(ctx, workspaceID, id, s.documentRepo.LockByProjectID)
+}
+
+func (s *ProjectService) loadProjectDocument(ctx context.Context, workspaceID, id uuid.UUID, getDocument func(context.Context, uuid.UUID) (*models.Document, error)) (*models.Project, *models.Document, error) {
+	// The document is loaded first so that a lock taken on it covers the
+	// project before it is read.
+	doc, err := getDocument(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc == nil {
+		return nil, nil, ErrProjectNotFound
+	}
+
+	project, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return project, doc, nil
+}
+
+func (s *ProjectService) publishProjectCreated(ctx context.Context, workspaceID uuid.UUID, project *models.Project) error {
+	return publishEvent(ctx, s.events, workspaceID, models.EventProjectCreated, map[string]interface{}{"project": project})
+}
+
+// projectNameError maps a unique name violation to ErrProjectNameTaken.
+func projectNameError(err error) error {
+	if repository.IsUniqueViolation(err) {
+		return ErrProjectNameTaken
+	}
+	return err
+}
+
+// notFoundError maps a mutation that matched no row to notFound and passes
+// other errors through.
+func notFoundError(err, notFound error) error {
+	if repository.IsNoRows(err) {
+		return notFound
+	}
+	return err
+}
+
+// getWorkspaceProject loads a live project and reports ErrProjectNotFound if
+// it belongs to a different workspace, so that projects of other tenants are
+// indistinguishable from missing ones.
+func getWorkspaceProject(ctx context.Context, projectRepo repository.ProjectStore, workspaceID, id uuid.UUID) (*models.Project, error) {
+	project, err := projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil || project.WorkspaceID != workspaceID {
+		return nil, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+// getWritableProject is getWorkspaceProject, but reports ErrProjectArchived
+// for archived projects.
+func getWritableProject(ctx context.Context, projectRepo repository.ProjectStore, workspaceID, id uuid.UUID) (*models.Project, error) {
+	project, err := getWorkspaceProject(ctx, projectRepo, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if project.ArchivedAt != nil {
+		return nil, ErrProjectArchived
+	}
+
+	return project, nil
+}
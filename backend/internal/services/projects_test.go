@@ -1,7 +1,19 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/warriorguo/md-editor/backend/internal/models"
 )
 
 func TestErrProjectNotFound(t *testing.T) {
@@ -10,9 +22,263 @@ func TestErrProjectNotFound(t *testing.T) {
 	}
 }
 
+func TestErrProjectNameTaken(t *testing.T) {
+	if ErrProjectNameTaken.Error() != "project name already taken" {
+		t.Errorf("Expected error message 'project name already taken', got '%s'", ErrProjectNameTaken.Error())
+	}
+}
+
+func TestProjectNameError(t *testing.T) {
+	if err := projectNameError(&pgconn.PgError{Code: "23505"}); !errors.Is(err, ErrProjectNameTaken) {
+		t.Errorf("Expected ErrProjectNameTaken for a unique violation, got %v", err)
+	}
+
+	other := errors.New("connection refused")
+	if err := projectNameError(other); err != other {
+		t.Errorf("Expected other errors to pass through, got %v", err)
+	}
+}
+
 func TestNewProjectService(t *testing.T) {
-	service := NewProjectService(nil, nil)
+	service := NewProjectService(nil, nil, nil, nil, nil, nil, PageSizes{})
 	if service == nil {
 		t.Error("Expected non-nil service")
 	}
 }
+
+func TestProjectCursorRoundTrip(t *testing.T) {
+	project := models.Project{
+		ID:        uuid.New(),
+		Name:      "Roadmap",
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 123456000, time.UTC),
+		UpdatedAt: time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		sort       string
+		descending bool
+		wantValue  string
+	}{
+		{sort: models.ProjectSortCreatedAt, descending: true, wantValue: "2024-01-15T10:30:00.123456Z"},
+		{sort: models.ProjectSortUpdatedAt, descending: false, wantValue: "2024-02-01T08:00:00Z"},
+		{sort: models.ProjectSortName, descending: false, wantValue: "Roadmap"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			cursor, err := DecodeProjectCursor(EncodeProjectCursor(tt.sort, tt.descending, project))
+			if err != nil {
+				t.Fatalf("Expected cursor to decode, got %v", err)
+			}
+			if cursor.Sort != tt.sort || cursor.Descending != tt.descending {
+				t.Errorf("Expected ordering %s/%v, got %s/%v", tt.sort, tt.descending, cursor.Sort, cursor.Descending)
+			}
+			if cursor.Value != tt.wantValue {
+				t.Errorf("Expected value %q, got %q", tt.wantValue, cursor.Value)
+			}
+			if cursor.ID != project.ID {
+				t.Errorf("Expected ID %s, got %s", project.ID, cursor.ID)
+			}
+		})
+	}
+}
+
+func TestProjectCursorKeepsPinned(t *testing.T) {
+	pinnedAt := time.Now()
+	project := models.Project{ID: uuid.New(), Name: "Roadmap", PinnedAt: &pinnedAt}
+
+	cursor, err := DecodeProjectCursor(EncodeProjectCursor(models.ProjectSortName, false, project))
+	if err != nil {
+		t.Fatalf("Expected cursor to decode, got %v", err)
+	}
+	if !cursor.Pinned {
+		t.Error("Expected cursor of a pinned project to be pinned")
+	}
+
+	project.PinnedAt = nil
+	cursor, _ = DecodeProjectCursor(EncodeProjectCursor(models.ProjectSortName, false, project))
+	if cursor.Pinned {
+		t.Error("Expected cursor of an unpinned project not to be pinned")
+	}
+}
+
+func TestDecodeProjectCursorInvalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "***"},
+		{name: "not JSON", cursor: encode("nope")},
+		{name: "missing ID", cursor: encode(`{"s":"name","v":"a"}`)},
+		{name: "unknown sort", cursor: encode(`{"s":"size","v":"1","i":"` + uuid.NewString() + `"}`)},
+		{name: "bad timestamp", cursor: encode(`{"s":"createdAt","v":"yesterday","i":"` + uuid.NewString() + `"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeProjectCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMergeRejectsDuplicateProjects(t *testing.T) {
+	service := NewProjectService(nil, nil, nil, nil, nil, nil, PageSizes{})
+	id := uuid.New()
+
+	_, err := service.Merge(context.Background(), uuid.New(), models.MergeProjectsRequest{ProjectIDs: []uuid.UUID{id, uuid.New(), id}}, "alice")
+	if !errors.Is(err, ErrInvalidMerge) {
+		t.Errorf("Expected ErrInvalidMerge, got %v", err)
+	}
+}
+
+func TestProjectMovedError(t *testing.T) {
+	to := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	err := error(&ProjectMovedError{To: to})
+
+	if err.Error() != "project moved to 00000000-0000-0000-0000-000000000001" {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+	var moved *ProjectMovedError
+	if !errors.As(err, &moved) || moved.To != to {
+		t.Error("Expected errors.As to find the redirect target")
+	}
+}
+
+func TestErrProjectArchived(t *testing.T) {
+	if ErrProjectArchived.Error() != "project is archived" {
+		t.Errorf("Expected error message 'project is archived', got '%s'", ErrProjectArchived.Error())
+	}
+}
+
+func TestNotFoundError(t *testing.T) {
+	if err := notFoundError(pgx.ErrNoRows, ErrProjectNotFound); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound for pgx.ErrNoRows, got %v", err)
+	}
+	if err := notFoundError(sql.ErrNoRows, ErrProjectNotFound); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound for sql.ErrNoRows, got %v", err)
+	}
+
+	other := errors.New("connection refused")
+	if err := notFoundError(other, ErrProjectNotFound); err != other {
+		t.Errorf("Expected other errors to pass through, got %v", err)
+	}
+}
+
+func TestGetOrCreate(t *testing.T) {
+	ctx := context.Background()
+	ws := models.DefaultWorkspaceID
+	stores := newSQLiteStores(t)
+	service := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, PageSizes{})
+
+	project, created, err := service.GetOrCreate(ctx, ws, "Notes", "alice")
+	if err != nil || !created {
+		t.Fatalf("Expected project to be created, got %v, %v", created, err)
+	}
+	doc, err := service.GetDocument(ctx, ws, project.ID)
+	if err != nil || doc.Version != 1 || doc.ContentMD != "" {
+		t.Errorf("Expected an empty first version of the document, got %+v, %v", doc, err)
+	}
+
+	again, created, err := service.GetOrCreate(ctx, ws, "notes", "alice")
+	if err != nil || created || again.ID != project.ID {
+		t.Errorf("Expected the existing project, got %+v, %v, %v", again, created, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+	ws := models.DefaultWorkspaceID
+	stores := newSQLiteStores(t)
+	service := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, PageSizes{})
+
+	var ids []uuid.UUID
+	for _, content := range []string{"# Target", "# Source"} {
+		project, err := service.create(ctx, ws, content[2:], content, "alice")
+		if err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		ids = append(ids, project.ID)
+	}
+
+	merged, err := service.Merge(ctx, ws, models.MergeProjectsRequest{ProjectIDs: ids}, "alice")
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if merged.Document.Version != 2 || merged.Document.ContentMD != "# Target\n\n# Source\n" {
+		t.Errorf("Expected the concatenated documents in version 2, got %d %q", merged.Document.Version, merged.Document.ContentMD)
+	}
+
+	var moved *ProjectMovedError
+	if _, err := service.GetByID(ctx, ws, ids[1]); !errors.As(err, &moved) || moved.To != ids[0] {
+		t.Errorf("Expected the source to redirect to the target, got %v", err)
+	}
+}
+
+func TestSplitTruncatesLongHeadings(t *testing.T) {
+	ctx := context.Background()
+	ws := models.DefaultWorkspaceID
+	stores := newSQLiteStores(t)
+	service := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, PageSizes{})
+
+	heading := strings.Repeat("é", 300)
+	project, err := service.create(ctx, ws, "Long", "Intro\n\n# "+heading+"\n\nBody", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	split, err := service.Split(ctx, ws, project.ID, models.SplitProjectRequest{Headings: []string{heading}}, "alice")
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	if len(split.Created) != 1 {
+		t.Fatalf("Expected one new project, got %d", len(split.Created))
+	}
+	if n := utf8.RuneCountInString(split.Created[0].Name); n != models.MaxProjectNameLength {
+		t.Errorf("Expected a name of %d characters, got %d", models.MaxProjectNameLength, n)
+	}
+}
+
+func TestArchivedProjectIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	ws := models.DefaultWorkspaceID
+	stores := newSQLiteStores(t)
+	projects := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, PageSizes{})
+	shareLinks := NewShareLinkService(stores.ShareLinks, stores.Projects, stores.Documents)
+
+	project, err := projects.Create(ctx, ws, "Done", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := projects.Archive(ctx, ws, project.ID, true); err != nil {
+		t.Fatalf("Failed to archive project: %v", err)
+	}
+
+	if _, err := projects.Update(ctx, ws, project.ID, "Renamed"); !errors.Is(err, ErrProjectArchived) {
+		t.Errorf("Expected rename to fail with ErrProjectArchived, got %v", err)
+	}
+	if _, err := projects.Transfer(ctx, ws, project.ID, uuid.New()); !errors.Is(err, ErrProjectArchived) {
+		t.Errorf("Expected transfer to fail with ErrProjectArchived, got %v", err)
+	}
+	if err := projects.Delete(ctx, ws, project.ID); !errors.Is(err, ErrProjectArchived) {
+		t.Errorf("Expected delete to fail with ErrProjectArchived, got %v", err)
+	}
+	if _, err := shareLinks.Create(ctx, ws, project.ID, models.CreateShareLinkRequest{}); !errors.Is(err, ErrProjectArchived) {
+		t.Errorf("Expected sharing to fail with ErrProjectArchived, got %v", err)
+	}
+
+	if _, err := projects.Archive(ctx, ws, project.ID, false); err != nil {
+		t.Fatalf("Failed to unarchive project: %v", err)
+	}
+	if _, err := shareLinks.Create(ctx, ws, project.ID, models.CreateShareLinkRequest{}); err != nil {
+		t.Errorf("Expected sharing an unarchived project to work, got %v", err)
+	}
+	if err := projects.Delete(ctx, ws, project.ID); err != nil {
+		t.Errorf("Expected deleting an unarchived project to work, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"strings"
+)
+
+// markdownSection is a top-level heading of a document together with
+// everything up to the next top-level heading.
+type markdownSection struct {
+	Title string
+	Lines []string
+}
+
+// splitSections cuts content at its top-level headings, the ATX headings of
+// the lowest level used outside code blocks. The preamble is whatever comes
+// before the first of them.
+func splitSections(content string) ([]string, []markdownSection) {
+	lines := strings.Split(content, "\n")
+
+	type heading struct {
+		line  int
+		level int
+		title string
+	}
+	var headings []heading
+	topLevel := 7
+	inFence, fence := false, ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+				continue
+			}
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+				continue
+			}
+		}
+		if inFence {
+			continue
+		}
+		if title, ok := atxHeading(line); ok {
+			marks := strings.TrimLeft(line, " ")
+			level := len(marks) - len(strings.TrimLeft(marks, "#"))
+			headings = append(headings, heading{line: i, level: level, title: title})
+			if level < topLevel {
+				topLevel = level
+			}
+		}
+	}
+
+	var starts []heading
+	for _, h := range headings {
+		if h.level == topLevel {
+			starts = append(starts, h)
+		}
+	}
+	if len(starts) == 0 {
+		return lines, nil
+	}
+
+	sections := make([]markdownSection, len(starts))
+	for i, h := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1].line
+		}
+		sections[i] = markdownSection{Title: h.title, Lines: lines[h.line:end]}
+	}
+
+	return lines[:starts[0].line], sections
+}
+
+// joinBlocks joins non-blank markdown blocks with a blank line between them.
+func joinBlocks(blocks []string) string {
+	var kept []string
+	for _, b := range blocks {
+		if b = strings.TrimSpace(b); b != "" {
+			kept = append(kept, b)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, "\n\n") + "\n"
+}
+
+// concatenateDocuments appends the documents one after another.
+func concatenateDocuments(contents []string) string {
+	return joinBlocks(contents)
+}
+
+// interleaveDocuments merges documents section by section: preambles come
+// first, then each top-level heading in the order it first appears, followed
+// by the bodies of every document's section with that title.
+func interleaveDocuments(contents []string) string {
+	var preambles []string
+	var order []string
+	merged := map[string][]string{}
+
+	for _, content := range contents {
+		preamble, sections := splitSections(content)
+		preambles = append(preambles, strings.Join(preamble, "\n"))
+
+		for _, section := range sections {
+			key := strings.ToLower(section.Title)
+			if _, ok := merged[key]; !ok {
+				order = append(order, key)
+				merged[key] = []string{strings.Join(section.Lines, "\n")}
+				continue
+			}
+			merged[key] = append(merged[key], strings.Join(section.Lines[1:], "\n"))
+		}
+	}
+
+	blocks := []string{joinBlocks(preambles)}
+	for _, key := range order {
+		blocks = append(blocks, joinBlocks(merged[key]))
+	}
+
+	return joinBlocks(blocks)
+}
+
+// extractSections removes the sections whose titles are listed from content.
+// It returns the remaining document, the removed sections as standalone
+// documents with a level-one heading, and the titles that were not found.
+func extractSections(content string, titles []string) (string, []markdownSection, []string) {
+	preamble, sections := splitSections(content)
+
+	wanted := map[string]int{}
+	for i, title := range titles {
+		wanted[strings.ToLower(strings.TrimSpace(title))] = i
+	}
+
+	remaining := append([]string{}, preamble...)
+	extracted := make([]markdownSection, len(titles))
+	found := make([]bool, len(titles))
+	for _, section := range sections {
+		i, ok := wanted[strings.ToLower(section.Title)]
+		if !ok || found[i] {
+			remaining = append(remaining, section.Lines...)
+			continue
+		}
+		found[i] = true
+		lines := append([]string{"# " + section.Title}, section.Lines[1:]...)
+		extracted[i] = markdownSection{Title: section.Title, Lines: lines}
+	}
+
+	var missing []string
+	for i, ok := range found {
+		if !ok {
+			missing = append(missing, titles[i])
+		}
+	}
+
+	return joinBlocks([]string{strings.Join(remaining, "\n")}), extracted, missing
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSections(t *testing.T) {
+	content := "Intro\n\n# One\nfirst\n## Sub\n```\n# not a heading\n```\n# Two\nsecond"
+
+	preamble, sections := splitSections(content)
+
+	if want := []string{"Intro", ""}; !reflect.DeepEqual(preamble, want) {
+		t.Errorf("Expected preamble %q, got %q", want, preamble)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections))
+	}
+	if sections[0].Title != "One" || len(sections[0].Lines) != 6 {
+		t.Errorf("Unexpected first section %+v", sections[0])
+	}
+	if sections[1].Title != "Two" || len(sections[1].Lines) != 2 {
+		t.Errorf("Unexpected second section %+v", sections[1])
+	}
+}
+
+func TestSplitSectionsUsesLowestLevel(t *testing.T) {
+	_, sections := splitSections("## A\n### A.1\n## B")
+
+	if len(sections) != 2 || sections[0].Title != "A" || sections[1].Title != "B" {
+		t.Errorf("Expected sections A and B, got %+v", sections)
+	}
+}
+
+func TestConcatenateDocuments(t *testing.T) {
+	got := concatenateDocuments([]string{"# A\na\n", "  ", "# B\nb"})
+
+	if want := "# A\na\n\n# B\nb\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestInterleaveDocuments(t *testing.T) {
+	got := interleaveDocuments([]string{
+		"Intro one\n# Goals\ng1\n# Notes\nn1",
+		"Intro two\n# notes\nn2\n# Risks\nr2",
+	})
+
+	want := "Intro one\n\nIntro two\n\n# Goals\ng1\n\n# Notes\nn1\n\nn2\n\n# Risks\nr2\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestExtractSections(t *testing.T) {
+	content := "Intro\n## Keep\nk\n## Move\nm\n"
+
+	remaining, extracted, missing := extractSections(content, []string{"move", "Absent"})
+
+	if want := "Intro\n## Keep\nk\n"; remaining != want {
+		t.Errorf("Expected remaining %q, got %q", want, remaining)
+	}
+	if extracted[0].Title != "Move" || !reflect.DeepEqual(extracted[0].Lines, []string{"# Move", "m", ""}) {
+		t.Errorf("Unexpected extracted section %+v", extracted[0])
+	}
+	if !reflect.DeepEqual(missing, []string{"Absent"}) {
+		t.Errorf("Expected missing [Absent], got %v", missing)
+	}
+}
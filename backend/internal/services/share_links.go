@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrShareLinkNotFound      = apperror.New(http.StatusNotFound, "share_link_not_found", "share link not found")
+	ErrSharePasswordRequired  = apperror.New(http.StatusUnauthorized, "share_password_required", "share link password required")
+	ErrSharePasswordIncorrect = apperror.New(http.StatusUnauthorized, "share_password_incorrect", "share link password incorrect")
+	ErrRevisionNotFound       = apperror.New(http.StatusNotFound, "revision_not_found", "revision not found")
+	ErrInvalidExpiry          = apperror.New(http.StatusBadRequest, "invalid_expiry", "expiry must be in the future")
+)
+
+type ShareLinkService struct {
+	shareLinkRepo repository.ShareLinkStore
+	projectRepo   repository.ProjectStore
+	documentRepo  repository.DocumentStore
+}
+
+func NewShareLinkService(shareLinkRepo repository.ShareLinkStore, projectRepo repository.ProjectStore, documentRepo repository.DocumentStore) *ShareLinkService {
+	return &ShareLinkService{
+		shareLinkRepo: shareLinkRepo,
+		projectRepo:   projectRepo,
+		documentRepo:  documentRepo,
+	}
+}
+
+// Create shares the document of a project. Archived projects cannot be
+// shared until they are unarchived.
+func (s *ShareLinkService) Create(ctx context.Context, workspaceID, projectID uuid.UUID, req models.CreateShareLinkRequest) (*models.ShareLink, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	if _, err := getWritableProject(ctx, s.projectRepo, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	if req.PinnedVersion != nil {
+		doc, err := s.documentRepo.GetByProjectID(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			return nil, ErrDocumentNotFound
+		}
+		rev, err := s.documentRepo.GetRevision(ctx, doc.ID, *req.PinnedVersion)
+		if err != nil {
+			return nil, err
+		}
+		if rev == nil {
+			return nil, ErrRevisionNotFound
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.ShareLink{
+		ProjectID:     projectID,
+		Token:         token,
+		PinnedVersion: req.PinnedVersion,
+		ExpiresAt:     req.ExpiresAt,
+	}
+
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash := string(hash)
+		link.PasswordHash = &passwordHash
+	}
+
+	link, err = s.shareLinkRepo.Create(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	return withShareURL(link), nil
+}
+
+func (s *ShareLinkService) List(ctx context.Context, workspaceID, projectID uuid.UUID) (*models.ShareLinkListResponse, error) {
+	if _, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.shareLinkRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range links {
+		withShareURL(&links[i])
+	}
+
+	return &models.ShareLinkListResponse{ShareLinks: links}, nil
+}
+
+func (s *ShareLinkService) Revoke(ctx context.Context, workspaceID, projectID, linkID uuid.UUID) error {
+	if _, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, projectID); err != nil {
+		return err
+	}
+
+	if err := s.shareLinkRepo.Revoke(ctx, projectID, linkID); err != nil {
+		return notFoundError(err, ErrShareLinkNotFound)
+	}
+
+	return nil
+}
+
+// Resolve returns the document content a share token grants access to. Expired,
+// revoked and unknown tokens are all reported as ErrShareLinkNotFound so that
+// callers cannot probe for the existence of a link.
+func (s *ShareLinkService) Resolve(ctx context.Context, token, password string) (*models.SharedDocument, error) {
+	link, err := s.shareLinkRepo.GetActiveByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrShareLinkNotFound
+	}
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return nil, ErrShareLinkNotFound
+	}
+
+	if link.PasswordHash != nil {
+		if password == "" {
+			return nil, ErrSharePasswordRequired
+		}
+		if bcrypt.CompareHashAndPassword([]byte(*link.PasswordHash), []byte(password)) != nil {
+			return nil, ErrSharePasswordIncorrect
+		}
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, link.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrShareLinkNotFound
+	}
+
+	doc, err := s.documentRepo.GetByProjectID(ctx, link.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrShareLinkNotFound
+	}
+
+	shared := &models.SharedDocument{
+		ProjectName: project.Name,
+		ContentMD:   doc.ContentMD,
+		Version:     doc.Version,
+		UpdatedAt:   doc.UpdatedAt,
+	}
+
+	if link.PinnedVersion != nil {
+		rev, err := s.documentRepo.GetRevision(ctx, doc.ID, *link.PinnedVersion)
+		if err != nil {
+			return nil, err
+		}
+		if rev == nil {
+			return nil, ErrShareLinkNotFound
+		}
+		shared.ContentMD = rev.ContentMD
+		shared.Version = rev.Version
+		shared.UpdatedAt = rev.CreatedAt
+	}
+
+	return shared, nil
+}
+
+func withShareURL(link *models.ShareLink) *models.ShareLink {
+	link.URL = "/s/" + link.Token
+	return link
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestWithShareURL(t *testing.T) {
+	link := withShareURL(&models.ShareLink{Token: "abc"})
+	if link.URL != "/s/abc" {
+		t.Errorf("Expected URL '/s/abc', got '%s'", link.URL)
+	}
+}
+
+func TestShareLinkResolve(t *testing.T) {
+	ctx := context.Background()
+	ws := models.DefaultWorkspaceID
+	stores := newSQLiteStores(t)
+
+	projects := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, nil, nil, PageSizes{})
+	project, err := projects.Create(ctx, ws, "Shared", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	service := NewShareLinkService(stores.ShareLinks, stores.Projects, stores.Documents)
+	past := time.Now().Add(-time.Hour)
+	if _, err := service.Create(ctx, ws, project.ID, models.CreateShareLinkRequest{ExpiresAt: &past}); !errors.Is(err, ErrInvalidExpiry) {
+		t.Errorf("Expected ErrInvalidExpiry, got %v", err)
+	}
+
+	link, err := service.Create(ctx, ws, project.ID, models.CreateShareLinkRequest{Password: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to create share link: %v", err)
+	}
+	if link.Token == "" || link.URL != "/s/"+link.Token {
+		t.Errorf("Expected a token and matching URL, got %q and %q", link.Token, link.URL)
+	}
+
+	if _, err := service.Resolve(ctx, link.Token, ""); !errors.Is(err, ErrSharePasswordRequired) {
+		t.Errorf("Expected ErrSharePasswordRequired, got %v", err)
+	}
+	if _, err := service.Resolve(ctx, link.Token, "wrong"); !errors.Is(err, ErrSharePasswordIncorrect) {
+		t.Errorf("Expected ErrSharePasswordIncorrect, got %v", err)
+	}
+	shared, err := service.Resolve(ctx, link.Token, "secret")
+	if err != nil {
+		t.Fatalf("Failed to resolve share link: %v", err)
+	}
+	if shared.ProjectName != "Shared" {
+		t.Errorf("Expected project name 'Shared', got %q", shared.ProjectName)
+	}
+
+	if err := service.Revoke(ctx, ws, project.ID, link.ID); err != nil {
+		t.Fatalf("Failed to revoke share link: %v", err)
+	}
+	if _, err := service.Resolve(ctx, link.Token, "secret"); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("Expected ErrShareLinkNotFound for a revoked link, got %v", err)
+	}
+}
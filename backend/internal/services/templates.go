@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+var (
+	ErrTemplateNotFound = apperror.New(http.StatusNotFound, "template_not_found", "template not found")
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// RenderTemplate replaces every {{name}} in content with values[name].
+// Placeholders without a value are left as they are.
+func RenderTemplate(content string, values map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+type TemplateService struct {
+	templateRepo    repository.TemplateStore
+	projectRepo     repository.ProjectStore
+	documentRepo    repository.DocumentStore
+	maxContentBytes int
+}
+
+func NewTemplateService(templateRepo repository.TemplateStore, projectRepo repository.ProjectStore, documentRepo repository.DocumentStore, maxContentBytes int) *TemplateService {
+	return &TemplateService{
+		templateRepo:    templateRepo,
+		projectRepo:     projectRepo,
+		documentRepo:    documentRepo,
+		maxContentBytes: maxContentBytes,
+	}
+}
+
+func (s *TemplateService) Create(ctx context.Context, workspaceID uuid.UUID, req models.CreateTemplateRequest) (*models.Template, error) {
+	if s.maxContentBytes > 0 && len(req.ContentMD) > s.maxContentBytes {
+		return nil, ErrDocumentTooLarge
+	}
+
+	return s.templateRepo.Create(ctx, workspaceID, req.Name, req.Description, req.ContentMD, nil)
+}
+
+// SaveProject creates a template from the current document of a project.
+func (s *TemplateService) SaveProject(ctx context.Context, workspaceID, projectID uuid.UUID, req models.SaveAsTemplateRequest) (*models.Template, error) {
+	project, err := getWorkspaceProject(ctx, s.projectRepo, workspaceID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.documentRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	name := req.Name
+	if name == "" {
+		name = project.Name
+	}
+
+	return s.templateRepo.Create(ctx, workspaceID, name, req.Description, doc.ContentMD, &project.ID)
+}
+
+func (s *TemplateService) List(ctx context.Context, workspaceID uuid.UUID) (*models.TemplateListResponse, error) {
+	templates, err := s.templateRepo.List(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TemplateListResponse{Templates: templates}, nil
+}
+
+func (s *TemplateService) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Template, error) {
+	template, err := s.templateRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	return template, nil
+}
+
+func (s *TemplateService) Update(ctx context.Context, workspaceID, id uuid.UUID, req models.UpdateTemplateRequest) (*models.Template, error) {
+	template, err := s.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		template.Name = *req.Name
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+	if req.ContentMD != nil {
+		if s.maxContentBytes > 0 && len(*req.ContentMD) > s.maxContentBytes {
+			return nil, ErrDocumentTooLarge
+		}
+		template.ContentMD = *req.ContentMD
+	}
+
+	template, err = s.templateRepo.Update(ctx, workspaceID, id, template.Name, template.Description, template.ContentMD)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	return template, nil
+}
+
+func (s *TemplateService) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	if err := s.templateRepo.Delete(ctx, workspaceID, id); err != nil {
+		return notFoundError(err, ErrTemplateNotFound)
+	}
+
+	return nil
+}
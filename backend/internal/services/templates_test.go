@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]string{
+		"title":    "Outage 42",
+		"date":     "2024-03-01",
+		"user":     "alice",
+		"severity": "SEV-2",
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "built-ins", content: "# {{title}}\n\n{{date}} by {{user}}", want: "# Outage 42\n\n2024-03-01 by alice"},
+		{name: "spaces inside braces", content: "Severity: {{ severity }}", want: "Severity: SEV-2"},
+		{name: "unknown placeholder kept", content: "Owner: {{owner}}", want: "Owner: {{owner}}"},
+		{name: "repeated placeholder", content: "{{user}}/{{user}}", want: "alice/alice"},
+		{name: "no placeholders", content: "plain", want: "plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderTemplate(tt.content, values); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestErrTemplateNotFound(t *testing.T) {
+	if ErrTemplateNotFound.Error() != "template not found" {
+		t.Errorf("Expected error message 'template not found', got '%s'", ErrTemplateNotFound.Error())
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestGenerateToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := generateToken()
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		if len(token) != 43 {
+			t.Errorf("Expected token length 43, got %d", len(token))
+		}
+		if seen[token] {
+			t.Fatalf("Generated duplicate token %s", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	hash := hashToken("secret")
+	if len(hash) != 64 {
+		t.Errorf("Expected hash length 64, got %d", len(hash))
+	}
+	if hash != hashToken("secret") {
+		t.Error("Expected hashing to be deterministic")
+	}
+	if hash == hashToken("other") {
+		t.Error("Expected different tokens to hash differently")
+	}
+}
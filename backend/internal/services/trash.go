@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+var (
+	ErrTrashedProjectNotFound = apperror.New(http.StatusNotFound, "trashed_project_not_found", "project not found in trash")
+)
+
+// TrashService lists, restores and permanently removes soft-deleted
+// projects. Projects stay in the trash for the retention period; zero keeps
+// them until they are purged by hand.
+type TrashService struct {
+	tx          repository.Transactor
+	projectRepo repository.ProjectStore
+	retention   time.Duration
+	pageSizes   PageSizes
+}
+
+func NewTrashService(tx repository.Transactor, projectRepo repository.ProjectStore, retention time.Duration, pageSizes PageSizes) *TrashService {
+	return &TrashService{
+		tx:          tx,
+		projectRepo: projectRepo,
+		retention:   retention,
+		pageSizes:   pageSizes,
+	}
+}
+
+func (s *TrashService) List(ctx context.Context, workspaceID uuid.UUID, page, pageSize int) (*models.TrashListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	pageSize = s.pageSizes.size(pageSize)
+
+	projects, totalCount, err := s.projectRepo.ListDeleted(ctx, workspaceID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.retention > 0 {
+		for i := range projects {
+			purgeAt := projects[i].DeletedAt.Add(s.retention)
+			projects[i].PurgeAt = &purgeAt
+		}
+	}
+
+	return &models.TrashListResponse{
+		Projects:   projects,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *TrashService) Restore(ctx context.Context, workspaceID, id uuid.UUID) (*models.Project, error) {
+	var project *models.Project
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		project, err = s.projectRepo.Restore(ctx, workspaceID, id)
+		if err != nil {
+			return projectNameError(err)
+		}
+		if project == nil {
+			return ErrTrashedProjectNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// Purge permanently removes a project that is in the trash.
+func (s *TrashService) Purge(ctx context.Context, workspaceID, id uuid.UUID) error {
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.projectRepo.HardDelete(ctx, workspaceID, id); err != nil {
+			return notFoundError(err, ErrTrashedProjectNotFound)
+		}
+		return nil
+	})
+}
+
+// PurgeExpired removes every project that has been in the trash longer than
+// the retention period.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	return s.projectRepo.PurgeDeletedBefore(ctx, time.Now().Add(-s.retention))
+}
+
+// RunPurge calls PurgeExpired every interval until ctx is cancelled.
+func (s *TrashService) RunPurge(ctx context.Context, interval time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to purge trash", "error", err)
+		}
+		if purged > 0 {
+			slog.InfoContext(ctx, "Purged projects from the trash", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
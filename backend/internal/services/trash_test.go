@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestErrTrashedProjectNotFound(t *testing.T) {
+	if ErrTrashedProjectNotFound.Error() != "project not found in trash" {
+		t.Errorf("Expected error message 'project not found in trash', got '%s'", ErrTrashedProjectNotFound.Error())
+	}
+}
+
+func TestPurgeExpiredWithoutRetention(t *testing.T) {
+	service := NewTrashService(nil, nil, 0, PageSizes{})
+
+	purged, err := service.PurgeExpired(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if purged != 0 {
+		t.Errorf("Expected nothing purged, got %d", purged)
+	}
+}
+
+func TestRunPurgeWithoutRetentionReturns(t *testing.T) {
+	service := NewTrashService(nil, nil, 0, PageSizes{})
+
+	done := make(chan struct{})
+	go func() {
+		service.RunPurge(context.Background(), time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected RunPurge to return when retention is disabled")
+	}
+}
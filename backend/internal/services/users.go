@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+var (
+	ErrInvalidToken = apperror.New(http.StatusUnauthorized, "invalid_token", "invalid token")
+	ErrUserNotFound = apperror.New(http.StatusNotFound, "user_not_found", "user not found")
+)
+
+type UserService struct {
+	tx            repository.Transactor
+	userRepo      repository.UserStore
+	workspaceRepo repository.WorkspaceStore
+}
+
+func NewUserService(tx repository.Transactor, userRepo repository.UserStore, workspaceRepo repository.WorkspaceStore) *UserService {
+	return &UserService{
+		tx:            tx,
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+	}
+}
+
+// Create registers a user as a member of the default workspace and returns
+// the user's API token. The token is only stored hashed and cannot be
+// retrieved again. The first user becomes owner of the default workspace.
+func (s *UserService) Create(ctx context.Context, name string) (*models.User, string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var user *models.User
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Membership is locked so that two first users cannot both see no
+		// owner and become one.
+		if err := s.workspaceRepo.LockMembers(ctx, models.DefaultWorkspaceID); err != nil {
+			return err
+		}
+
+		var err error
+		user, err = s.userRepo.Create(ctx, name, hashToken(token))
+		if err != nil {
+			return err
+		}
+
+		owners, err := s.workspaceRepo.CountOwners(ctx, models.DefaultWorkspaceID)
+		if err != nil {
+			return err
+		}
+
+		role := models.WorkspaceRoleMember
+		if owners == 0 {
+			role = models.WorkspaceRoleOwner
+		}
+
+		_, err = s.workspaceRepo.UpsertMember(ctx, models.DefaultWorkspaceID, user.ID, role)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, token, nil
+}
+
+func (s *UserService) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return user, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestUserCreateMakesFirstUserOwner(t *testing.T) {
+	ctx := context.Background()
+	stores := newSQLiteStores(t)
+	service := NewUserService(stores.Tx, stores.Users, stores.Workspaces)
+
+	wantRoles := []string{models.WorkspaceRoleOwner, models.WorkspaceRoleMember}
+	for i, name := range []string{"alice", "bob"} {
+		user, token, err := service.Create(ctx, name)
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		if token == "" {
+			t.Error("Expected a token")
+		}
+		role, err := stores.Workspaces.GetMemberRole(ctx, models.DefaultWorkspaceID, user.ID)
+		if err != nil || role != wantRoles[i] {
+			t.Errorf("Expected %s to be %q, got %q, %v", name, wantRoles[i], role, err)
+		}
+	}
+
+	if _, _, err := service.Create(ctx, "alice"); err == nil {
+		t.Error("Expected a duplicate name to fail")
+	}
+	members, err := stores.Workspaces.ListMembers(ctx, models.DefaultWorkspaceID)
+	if err != nil || len(members) != 2 {
+		t.Errorf("Expected 2 members, got %v, %v", members, err)
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+var (
+	ErrWebhookNotFound = apperror.New(http.StatusNotFound, "webhook_not_found", "webhook not found")
+)
+
+const (
+	WebhookSignatureHeader = "X-MdEditor-Signature"
+	WebhookTimestampHeader = "X-MdEditor-Timestamp"
+	WebhookEventHeader     = "X-MdEditor-Event"
+	WebhookDeliveryHeader  = "X-MdEditor-Delivery"
+
+	recentDeliveriesLimit = 50
+)
+
+type WebhookService struct {
+	webhookRepo repository.WebhookStore
+}
+
+func NewWebhookService(webhookRepo repository.WebhookStore) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo}
+}
+
+// Create registers a webhook. A secret is generated when none is given; it is
+// only ever returned from this call.
+func (s *WebhookService) Create(ctx context.Context, workspaceID uuid.UUID, req models.CreateWebhookRequest) (*models.Webhook, error) {
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		secret, err = generateToken()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.webhookRepo.Create(ctx, workspaceID, req.URL, normalizeEvents(req.Events), secret)
+}
+
+func (s *WebhookService) List(ctx context.Context, workspaceID uuid.UUID) (*models.WebhookListResponse, error) {
+	webhooks, err := s.webhookRepo.List(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookListResponse{Webhooks: webhooks}, nil
+}
+
+func (s *WebhookService) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) Update(ctx context.Context, workspaceID, id uuid.UUID, req models.UpdateWebhookRequest) (*models.Webhook, error) {
+	webhook, err := s.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = *req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	webhook, err = s.webhookRepo.Update(ctx, workspaceID, id, webhook.URL, normalizeEvents(webhook.Events), webhook.Active)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	if err := s.webhookRepo.Delete(ctx, workspaceID, id); err != nil {
+		return notFoundError(err, ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, workspaceID, id uuid.UUID) (*models.WebhookDeliveryListResponse, error) {
+	if _, err := s.GetByID(ctx, workspaceID, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, id, recentDeliveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookDeliveryListResponse{Deliveries: deliveries}, nil
+}
+
+// Publish writes a delivery of the event to the outbox of every subscribed
+// webhook. It is called inside the transaction of the change that caused the
+// event, so the deliveries are written together with the change or not at
+// all.
+func (s *WebhookService) Publish(ctx context.Context, workspaceID uuid.UUID, eventType string, data interface{}) error {
+	payload, err := json.Marshal(models.WebhookPayload{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		OccurredAt:  time.Now().UTC(),
+		WorkspaceID: workspaceID,
+		Data:        data,
+	})
+	if err != nil {
+		return fmt.Errorf("encode %s webhook event: %w", eventType, err)
+	}
+
+	if err := s.webhookRepo.Enqueue(ctx, workspaceID, eventType, payload); err != nil {
+		return fmt.Errorf("enqueue %s webhook event: %w", eventType, err)
+	}
+	return nil
+}
+
+func normalizeEvents(events []string) []string {
+	if events == nil {
+		return []string{}
+	}
+	return events
+}
+
+// SignWebhookPayload returns the signature sent in the X-MdEditor-Signature
+// header: the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook
+// secret, prefixed with "sha256=". Receivers should recompute it and reject
+// stale timestamps to prevent replays.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDispatcherConfig tunes delivery of the webhook outbox.
+type WebhookDispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxAttempts  int
+	// BaseBackoff is the delay after the first failed attempt; it doubles
+	// with every further failure up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func DefaultWebhookDispatcherConfig() WebhookDispatcherConfig {
+	return WebhookDispatcherConfig{
+		PollInterval: 5 * time.Second,
+		BatchSize:    20,
+		Timeout:      10 * time.Second,
+		MaxAttempts:  10,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+	}
+}
+
+// WebhookDispatcher delivers pending webhook deliveries from the outbox and
+// reschedules failed ones with exponential backoff.
+type WebhookDispatcher struct {
+	webhookRepo repository.WebhookStore
+	client      *http.Client
+	cfg         WebhookDispatcherConfig
+}
+
+func NewWebhookDispatcher(webhookRepo repository.WebhookStore, cfg WebhookDispatcherConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		cfg:         cfg,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to dispatch webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends one batch of due deliveries.
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context) error {
+	// The lease must outlast the request timeout so that a slow receiver is
+	// not sent the same delivery twice concurrently.
+	leaseUntil := time.Now().Add(2 * d.cfg.Timeout)
+
+	deliveries, err := d.webhookRepo.ClaimDue(ctx, d.cfg.BatchSize, leaseUntil)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		statusCode, err := d.send(ctx, delivery)
+		if err == nil {
+			if err := d.webhookRepo.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var code *int
+		if statusCode != 0 {
+			code = &statusCode
+		}
+
+		var next *time.Time
+		if delivery.Attempts < d.cfg.MaxAttempts {
+			at := time.Now().Add(d.backoff(delivery.Attempts))
+			next = &at
+		}
+
+		if err := d.webhookRepo.MarkAttemptFailed(ctx, delivery.ID, code, err.Error(), next); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send posts a delivery and returns the receiver's status code. Any non-2xx
+// response is an error.
+func (d *WebhookDispatcher) send(ctx context.Context, delivery models.PendingWebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "md-editor-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt after the given number of
+// failed attempts.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
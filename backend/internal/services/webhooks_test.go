@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+	"github.com/warriorguo/md-editor/backend/internal/repository/sqlite"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	sig := SignWebhookPayload("secret", "1700000000", []byte(`{"a":1}`))
+	if sig != SignWebhookPayload("secret", "1700000000", []byte(`{"a":1}`)) {
+		t.Error("Expected signing to be deterministic")
+	}
+	if sig == SignWebhookPayload("other", "1700000000", []byte(`{"a":1}`)) {
+		t.Error("Expected signature to depend on the secret")
+	}
+	if sig == SignWebhookPayload("secret", "1700000001", []byte(`{"a":1}`)) {
+		t.Error("Expected signature to depend on the timestamp")
+	}
+	if len(sig) != len("sha256=")+64 {
+		t.Errorf("Unexpected signature format %s", sig)
+	}
+}
+
+func TestWebhookDispatcherSend(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusOK
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	dispatcher := NewWebhookDispatcher(nil, DefaultWebhookDispatcherConfig())
+	delivery := models.PendingWebhookDelivery{
+		ID:        7,
+		WebhookID: uuid.New(),
+		EventType: models.EventProjectCreated,
+		Payload:   []byte(`{"type":"project.created"}`),
+		URL:       receiver.URL,
+		Secret:    "s3cret",
+	}
+
+	code, err := dispatcher.send(context.Background(), delivery)
+	if err != nil {
+		t.Fatalf("Expected delivery to succeed, got %v", err)
+	}
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+
+	if string(body) != string(delivery.Payload) {
+		t.Errorf("Expected payload %s, got %s", delivery.Payload, body)
+	}
+	if received.Header.Get(WebhookEventHeader) != models.EventProjectCreated {
+		t.Errorf("Expected event header, got %q", received.Header.Get(WebhookEventHeader))
+	}
+	if received.Header.Get(WebhookDeliveryHeader) != "7" {
+		t.Errorf("Expected delivery header 7, got %q", received.Header.Get(WebhookDeliveryHeader))
+	}
+
+	timestamp := received.Header.Get(WebhookTimestampHeader)
+	want := SignWebhookPayload("s3cret", timestamp, body)
+	if received.Header.Get(WebhookSignatureHeader) != want {
+		t.Errorf("Expected signature %s, got %s", want, received.Header.Get(WebhookSignatureHeader))
+	}
+
+	status = http.StatusInternalServerError
+	code, err = dispatcher.send(context.Background(), delivery)
+	if err == nil {
+		t.Error("Expected non-2xx response to be an error")
+	}
+	if code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", code)
+	}
+}
+
+func TestWebhookDispatcherBackoff(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, WebhookDispatcherConfig{
+		BaseBackoff: time.Second,
+		MaxBackoff:  10 * time.Second,
+	})
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 50, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := dispatcher.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeEvents(t *testing.T) {
+	if events := normalizeEvents(nil); events == nil || len(events) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %#v", events)
+	}
+}
+
+// failingWebhookStore fails to write to the outbox.
+type failingWebhookStore struct {
+	repository.WebhookStore
+}
+
+func (failingWebhookStore) Enqueue(ctx context.Context, workspaceID uuid.UUID, eventType string, payload []byte) error {
+	return errors.New("outbox unavailable")
+}
+
+func newSQLiteStores(t *testing.T) *repository.Stores {
+	t.Helper()
+	url := database.SQLiteScheme + filepath.Join(t.TempDir(), "test.db")
+	if err := database.MigrateUp(url); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db, err := database.NewSQLite(url)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(db.Close)
+	return sqlite.NewStores(db)
+}
+
+func TestWebhookEnqueueFailureRollsBackChange(t *testing.T) {
+	ctx := context.Background()
+	ws := models.DefaultWorkspaceID
+	stores := newSQLiteStores(t)
+
+	webhooks := NewWebhookService(stores.Webhooks)
+	webhook, err := webhooks.Create(ctx, ws, models.CreateWebhookRequest{URL: "http://127.0.0.1/hook"})
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	projects := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, webhooks, nil, PageSizes{})
+	project, err := projects.Create(ctx, ws, "Notes", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	doc, err := projects.GetDocument(ctx, ws, project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+
+	failing := NewWebhookService(failingWebhookStore{stores.Webhooks})
+	failingProjects := NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, failing, nil, PageSizes{})
+	failingDocuments := NewDocumentService(stores.Tx, stores.Documents, stores.Projects, failing, nil, 0)
+
+	if _, err := failingProjects.Create(ctx, ws, "Lost", "alice"); err == nil {
+		t.Error("Expected create to fail")
+	}
+	if _, err := failingProjects.Update(ctx, ws, project.ID, "Renamed"); err == nil {
+		t.Error("Expected rename to fail")
+	}
+	if _, err := failingDocuments.Update(ctx, ws, doc.ID, "# Changed", doc.Version, "agent"); err == nil {
+		t.Error("Expected document update to fail")
+	}
+	if err := failingProjects.Delete(ctx, ws, project.ID); err == nil {
+		t.Error("Expected delete to fail")
+	}
+
+	list, err := projects.List(ctx, models.ProjectListQuery{WorkspaceID: ws})
+	if err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	if len(list.Projects) != 1 || list.Projects[0].Name != "Notes" {
+		t.Errorf("Expected only the unchanged project, got %+v", list.Projects)
+	}
+	current, err := projects.GetDocument(ctx, ws, project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+	if current.Version != doc.Version || current.ContentMD != doc.ContentMD {
+		t.Errorf("Expected the document to be unchanged, got version %d %q", current.Version, current.ContentMD)
+	}
+
+	deliveries, err := webhooks.ListDeliveries(ctx, ws, webhook.ID)
+	if err != nil {
+		t.Fatalf("Failed to list deliveries: %v", err)
+	}
+	if len(deliveries.Deliveries) != 1 || deliveries.Deliveries[0].EventType != models.EventProjectCreated {
+		t.Errorf("Expected only the delivery of the first create, got %+v", deliveries.Deliveries)
+	}
+}
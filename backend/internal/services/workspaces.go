@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/apperror"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+	"github.com/warriorguo/md-editor/backend/internal/repository"
+)
+
+var (
+	ErrUnauthenticated    = apperror.New(http.StatusUnauthorized, "unauthenticated", "authentication required")
+	ErrWorkspaceNotFound  = apperror.New(http.StatusNotFound, "workspace_not_found", "workspace not found")
+	ErrWorkspaceForbidden = apperror.New(http.StatusForbidden, "workspace_forbidden", "workspace access forbidden")
+	ErrLastWorkspaceOwner = apperror.New(http.StatusConflict, "last_workspace_owner", "workspace must keep at least one owner")
+	ErrMemberNotFound     = apperror.New(http.StatusNotFound, "member_not_found", "workspace member not found")
+	// ErrDuplicateProjectNames is returned when unique project names are
+	// required in a workspace that already has duplicates.
+	ErrDuplicateProjectNames = apperror.New(http.StatusConflict, "duplicate_project_names", "workspace has projects with duplicate names")
+)
+
+type WorkspaceService struct {
+	tx             repository.Transactor
+	workspaceRepo  repository.WorkspaceStore
+	userRepo       repository.UserStore
+	allowAnonymous bool
+}
+
+// NewWorkspaceService creates the service. When allowAnonymous is set,
+// clients without a token may use the default workspace.
+func NewWorkspaceService(tx repository.Transactor, workspaceRepo repository.WorkspaceStore, userRepo repository.UserStore, allowAnonymous bool) *WorkspaceService {
+	return &WorkspaceService{
+		tx:             tx,
+		workspaceRepo:  workspaceRepo,
+		userRepo:       userRepo,
+		allowAnonymous: allowAnonymous,
+	}
+}
+
+// Authorize checks that user may act inside workspaceID. A nil user is an
+// anonymous client.
+func (s *WorkspaceService) Authorize(ctx context.Context, user *models.User, workspaceID uuid.UUID) error {
+	if workspaceID == models.DefaultWorkspaceID && s.allowAnonymous {
+		return nil
+	}
+	if user == nil {
+		return ErrUnauthenticated
+	}
+
+	_, err := s.requireRole(ctx, user, workspaceID, "")
+	return err
+}
+
+func (s *WorkspaceService) List(ctx context.Context, user *models.User) (*models.WorkspaceListResponse, error) {
+	workspaces := []models.Workspace{}
+
+	if user != nil {
+		var err error
+		workspaces, err = s.workspaceRepo.ListForUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.allowAnonymous && !containsWorkspace(workspaces, models.DefaultWorkspaceID) {
+		ws, err := s.workspaceRepo.GetByID(ctx, models.DefaultWorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if ws != nil {
+			workspaces = append([]models.Workspace{*ws}, workspaces...)
+		}
+	}
+
+	return &models.WorkspaceListResponse{Workspaces: workspaces}, nil
+}
+
+func (s *WorkspaceService) Create(ctx context.Context, user *models.User, name string) (*models.Workspace, error) {
+	if user == nil {
+		return nil, ErrUnauthenticated
+	}
+
+	return s.workspaceRepo.Create(ctx, name, user.ID)
+}
+
+// Update changes the workspace settings present in req. Only owners may
+// change them.
+func (s *WorkspaceService) Update(ctx context.Context, user *models.User, workspaceID uuid.UUID, req models.UpdateWorkspaceRequest) (*models.Workspace, error) {
+	var updated *models.Workspace
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.workspaceRepo.LockMembers(ctx, workspaceID); err != nil {
+			return err
+		}
+		if _, err := s.requireRole(ctx, user, workspaceID, models.WorkspaceRoleOwner); err != nil {
+			return err
+		}
+
+		ws, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+		if err != nil {
+			return err
+		}
+		if ws == nil {
+			return ErrWorkspaceNotFound
+		}
+
+		if req.Name != nil {
+			ws.Name = *req.Name
+		}
+		if req.UniqueProjectNames != nil {
+			ws.UniqueProjectNames = *req.UniqueProjectNames
+		}
+
+		updated, err = s.workspaceRepo.Update(ctx, workspaceID, ws.Name, ws.UniqueProjectNames)
+		if err != nil {
+			if repository.IsUniqueViolation(err) {
+				return ErrDuplicateProjectNames
+			}
+			return err
+		}
+		if updated == nil {
+			return ErrWorkspaceNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	updated.Role = models.WorkspaceRoleOwner
+
+	return updated, nil
+}
+
+func (s *WorkspaceService) ListMembers(ctx context.Context, user *models.User, workspaceID uuid.UUID) (*models.WorkspaceMemberListResponse, error) {
+	if err := s.Authorize(ctx, user, workspaceID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.workspaceRepo.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WorkspaceMemberListResponse{Members: members}, nil
+}
+
+// AddMember adds a user to the workspace or changes their role. Only owners
+// may manage membership.
+func (s *WorkspaceService) AddMember(ctx context.Context, user *models.User, workspaceID uuid.UUID, req models.AddWorkspaceMemberRequest) (*models.WorkspaceMember, error) {
+	var added *models.WorkspaceMember
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Membership is locked so that two owners demoting each other
+		// cannot both pass the check for another owner.
+		if err := s.workspaceRepo.LockMembers(ctx, workspaceID); err != nil {
+			return err
+		}
+		if _, err := s.requireRole(ctx, user, workspaceID, models.WorkspaceRoleOwner); err != nil {
+			return err
+		}
+
+		member, err := s.userRepo.GetByName(ctx, req.UserName)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return ErrUserNotFound
+		}
+
+		role := req.Role
+		if role == "" {
+			role = models.WorkspaceRoleMember
+		}
+
+		if role != models.WorkspaceRoleOwner {
+			if err := s.ensureOtherOwner(ctx, workspaceID, member.ID); err != nil {
+				return err
+			}
+		}
+
+		added, err = s.workspaceRepo.UpsertMember(ctx, workspaceID, member.ID, role)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+// RemoveMember removes userID from the workspace. Owners may remove anyone;
+// members may only remove themselves.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, user *models.User, workspaceID, userID uuid.UUID) error {
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.workspaceRepo.LockMembers(ctx, workspaceID); err != nil {
+			return err
+		}
+		role, err := s.requireRole(ctx, user, workspaceID, "")
+		if err != nil {
+			return err
+		}
+		if role != models.WorkspaceRoleOwner && user.ID != userID {
+			return ErrWorkspaceForbidden
+		}
+
+		if err := s.ensureOtherOwner(ctx, workspaceID, userID); err != nil {
+			return err
+		}
+
+		if err := s.workspaceRepo.RemoveMember(ctx, workspaceID, userID); err != nil {
+			return notFoundError(err, ErrMemberNotFound)
+		}
+		return nil
+	})
+}
+
+// requireRole returns the role of user in workspaceID. If role is non-empty
+// the user must hold exactly that role.
+func (s *WorkspaceService) requireRole(ctx context.Context, user *models.User, workspaceID uuid.UUID, role string) (string, error) {
+	if user == nil {
+		return "", ErrUnauthenticated
+	}
+
+	ws, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	if ws == nil {
+		return "", ErrWorkspaceNotFound
+	}
+
+	actual, err := s.workspaceRepo.GetMemberRole(ctx, workspaceID, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if actual == "" || (role != "" && actual != role) {
+		return "", ErrWorkspaceForbidden
+	}
+
+	return actual, nil
+}
+
+// ensureOtherOwner fails if userID is the only owner of the workspace, so
+// that demoting or removing them would leave it unmanageable.
+func (s *WorkspaceService) ensureOtherOwner(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	role, err := s.workspaceRepo.GetMemberRole(ctx, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if role != models.WorkspaceRoleOwner {
+		return nil
+	}
+
+	owners, err := s.workspaceRepo.CountOwners(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastWorkspaceOwner
+	}
+
+	return nil
+}
+
+func containsWorkspace(workspaces []models.Workspace, id uuid.UUID) bool {
+	for _, ws := range workspaces {
+		if ws.ID == id {
+			return true
+		}
+	}
+	return false
+}
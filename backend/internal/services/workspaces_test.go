@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func TestWorkspaceAuthorizeAnonymous(t *testing.T) {
+	ctx := context.Background()
+
+	service := NewWorkspaceService(nil, nil, nil, true)
+	if err := service.Authorize(ctx, nil, models.DefaultWorkspaceID); err != nil {
+		t.Errorf("Expected anonymous access to default workspace, got %v", err)
+	}
+	if err := service.Authorize(ctx, nil, uuid.New()); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated for other workspace, got %v", err)
+	}
+
+	service = NewWorkspaceService(nil, nil, nil, false)
+	if err := service.Authorize(ctx, nil, models.DefaultWorkspaceID); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated when anonymous access is disabled, got %v", err)
+	}
+}
+
+func TestContainsWorkspace(t *testing.T) {
+	id := uuid.New()
+	workspaces := []models.Workspace{{ID: uuid.New()}, {ID: id}}
+
+	if !containsWorkspace(workspaces, id) {
+		t.Error("Expected workspace to be found")
+	}
+	if containsWorkspace(workspaces, uuid.New()) {
+		t.Error("Expected unknown workspace not to be found")
+	}
+}
+
+func TestRemoveLastOwner(t *testing.T) {
+	ctx := context.Background()
+	stores := newSQLiteStores(t)
+	service := NewWorkspaceService(stores.Tx, stores.Workspaces, stores.Users, false)
+
+	owner, err := stores.Users.Create(ctx, "owner", "owner-token")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	member, err := stores.Users.Create(ctx, "member", "member-token")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	ws, err := service.Create(ctx, owner, "team")
+	if err != nil {
+		t.Fatalf("Failed to create workspace: %v", err)
+	}
+	if _, err := service.AddMember(ctx, owner, ws.ID, models.AddWorkspaceMemberRequest{UserName: member.Name}); err != nil {
+		t.Fatalf("Failed to add member: %v", err)
+	}
+
+	if err := service.RemoveMember(ctx, owner, ws.ID, owner.ID); !errors.Is(err, ErrLastWorkspaceOwner) {
+		t.Errorf("Expected ErrLastWorkspaceOwner, got %v", err)
+	}
+	if err := service.RemoveMember(ctx, member, ws.ID, member.ID); err != nil {
+		t.Errorf("Expected member to leave, got %v", err)
+	}
+	role, err := stores.Workspaces.GetMemberRole(ctx, ws.ID, owner.ID)
+	if err != nil || role != models.WorkspaceRoleOwner {
+		t.Errorf("Expected owner to remain, got %q, %v", role, err)
+	}
+}
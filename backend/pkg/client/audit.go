@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditQuery filters ListAuditEvents. Zero values match everything.
+type AuditQuery struct {
+	ProjectID *uuid.UUID
+	Actor     string
+	Action    string
+	Since     time.Time
+	// Before is the NextBefore of the previous page.
+	Before int64
+	Limit  int
+}
+
+// ListAuditEvents lists the audit events of the workspace, newest first.
+func (c *Client) ListAuditEvents(ctx context.Context, query AuditQuery) (*AuditListResponse, error) {
+	q := url.Values{}
+	if query.ProjectID != nil {
+		q.Set("projectId", query.ProjectID.String())
+	}
+	if query.Actor != "" {
+		q.Set("actor", query.Actor)
+	}
+	if query.Action != "" {
+		q.Set("action", query.Action)
+	}
+	if !query.Since.IsZero() {
+		q.Set("since", query.Since.Format(time.RFC3339))
+	}
+	if query.Before > 0 {
+		q.Set("before", strconv.FormatInt(query.Before, 10))
+	}
+	if query.Limit > 0 {
+		q.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	var response AuditListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/audit", query: q}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
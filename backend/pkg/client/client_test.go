@@ -0,0 +1,375 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/database"
+	"github.com/warriorguo/md-editor/backend/internal/repository/sqlite"
+	"github.com/warriorguo/md-editor/backend/internal/server"
+	"github.com/warriorguo/md-editor/backend/internal/services"
+)
+
+// syncBuffer collects the server log, which handlers write concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+type testServer struct {
+	URL   string
+	users *services.UserService
+}
+
+// newTestServer serves the real router over an SQLite database. Responses
+// are checked against the OpenAPI document, and the test fails if any of
+// them does not match.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+
+	url := database.SQLiteScheme + filepath.Join(t.TempDir(), "test.db")
+	if err := database.MigrateUp(url); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db, err := database.NewSQLite(url)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	stores := sqlite.NewStores(db)
+	webhooks := services.NewWebhookService(stores.Webhooks)
+	events := services.Publishers{webhooks}
+	projects := services.NewProjectService(stores.Tx, stores.Projects, stores.Documents, stores.Templates, events, nil, services.PageSizes{})
+	users := services.NewUserService(stores.Tx, stores.Users, stores.Workspaces)
+
+	router, err := server.NewRouter(server.Services{
+		Projects:    projects,
+		Documents:   services.NewDocumentService(stores.Tx, stores.Documents, stores.Projects, events, nil, 0),
+		ShareLinks:  services.NewShareLinkService(stores.ShareLinks, stores.Projects, stores.Documents),
+		Users:       users,
+		Workspaces:  services.NewWorkspaceService(stores.Tx, stores.Workspaces, stores.Users, true),
+		Audit:       services.NewAuditService(stores.Audit),
+		Templates:   services.NewTemplateService(stores.Templates, stores.Projects, stores.Documents, 0),
+		Idempotency: services.NewIdempotencyService(stores.Idempotency, time.Hour),
+		Trash:       services.NewTrashService(stores.Tx, stores.Projects, 0, services.PageSizes{}),
+		Webhooks:    webhooks,
+	}, server.Options{ValidateResponses: true})
+	if err != nil {
+		t.Fatalf("Failed to set up router: %v", err)
+	}
+
+	logs := &syncBuffer{}
+	log.SetOutput(logs)
+	srv := httptest.NewServer(router)
+	t.Cleanup(func() {
+		srv.Close()
+		log.SetOutput(os.Stderr)
+		if strings.Contains(logs.String(), "does not match the OpenAPI document") {
+			t.Errorf("Responses did not match the OpenAPI document:\n%s", logs.String())
+		}
+	})
+
+	return &testServer{URL: srv.URL, users: users}
+}
+
+func TestProjectLifecycle(t *testing.T) {
+	ctx := context.Background()
+	c := New(newTestServer(t).URL)
+
+	project, err := c.CreateProject(ctx, CreateProjectRequest{Name: "Release notes"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	got, err := c.GetProject(ctx, project.ID)
+	if err != nil || got.Name != "Release notes" {
+		t.Fatalf("Expected the project, got %+v, %v", got, err)
+	}
+
+	renamed, err := c.RenameProject(ctx, project.ID, "Changelog")
+	if err != nil || renamed.Name != "Changelog" {
+		t.Fatalf("Expected the project to be renamed, got %+v, %v", renamed, err)
+	}
+
+	existing, created, err := c.GetOrCreateProject(ctx, "changelog")
+	if err != nil || created || existing.ID != project.ID {
+		t.Errorf("Expected the existing project, got %+v, %v, %v", existing, created, err)
+	}
+	other, created, err := c.GetOrCreateProject(ctx, "Ideas & later")
+	if err != nil || !created || other.Name != "Ideas & later" {
+		t.Errorf("Expected a new project, got %+v, %v, %v", other, created, err)
+	}
+
+	list, err := c.ListProjects(ctx, ListProjectsOptions{Sort: "name", Include: []string{"stats"}})
+	if err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	if list.TotalCount != 2 || list.Projects[0].Name != "Changelog" || list.Projects[0].Stats == nil {
+		t.Errorf("Expected both projects by name with stats, got %+v", list)
+	}
+
+	matches, err := c.LookupProjects(ctx, "changelog", 0)
+	if err != nil || len(matches.Matches) != 1 || !matches.Matches[0].Exact {
+		t.Errorf("Expected an exact match, got %+v, %v", matches, err)
+	}
+
+	if err := c.DeleteProject(ctx, project.ID); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	if _, err := c.GetProject(ctx, project.ID); !HasCode(err, CodeProjectNotFound) {
+		t.Errorf("Expected %s after delete, got %v", CodeProjectNotFound, err)
+	}
+
+	trash, err := c.ListTrash(ctx, 0, 0)
+	if err != nil || trash.TotalCount != 1 {
+		t.Fatalf("Expected the project in the trash, got %+v, %v", trash, err)
+	}
+	if _, err := c.RestoreProject(ctx, project.ID); err != nil {
+		t.Errorf("Failed to restore project: %v", err)
+	}
+}
+
+func TestUpdateDocument(t *testing.T) {
+	ctx := context.Background()
+	c := New(newTestServer(t).URL)
+
+	project, err := c.CreateProject(ctx, CreateProjectRequest{Name: "Notes"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	doc, err := c.GetDocument(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+
+	saved, err := c.UpdateDocument(ctx, doc, "# Notes\n", nil)
+	if err != nil || saved.Version != doc.Version+1 || saved.ContentMD != "# Notes\n" {
+		t.Fatalf("Expected the next version, got %+v, %v", saved, err)
+	}
+
+	// doc is now stale: without a callback the conflict is returned.
+	_, err = c.UpdateDocument(ctx, doc, "# Stale\n", nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeVersionConflict || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected a version conflict, got %v", err)
+	}
+
+	// With a callback the latest document is merged and saved instead.
+	var seen *DocumentResponse
+	merged, err := c.UpdateDocument(ctx, doc, "- stale item\n", &UpdateDocumentOptions{
+		OnConflict: func(_ context.Context, latest *DocumentResponse, content string) (string, error) {
+			seen = latest
+			return latest.ContentMD + content, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to resolve conflict: %v", err)
+	}
+	if seen == nil || seen.Version != saved.Version {
+		t.Errorf("Expected the callback to get version %d, got %+v", saved.Version, seen)
+	}
+	if merged.ContentMD != "# Notes\n- stale item\n" || merged.Version != saved.Version+1 {
+		t.Errorf("Expected the merged content as the next version, got %+v", merged)
+	}
+
+	// A callback can give up.
+	giveUp := errors.New("give up")
+	_, err = c.UpdateDocument(ctx, doc, "x", &UpdateDocumentOptions{
+		OnConflict: func(context.Context, *DocumentResponse, string) (string, error) { return "", giveUp },
+	})
+	if !errors.Is(err, giveUp) {
+		t.Errorf("Expected the callback's error, got %v", err)
+	}
+}
+
+func TestEditDocumentRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	c := New(srv.URL)
+	other := New(srv.URL)
+
+	project, err := c.CreateProject(ctx, CreateProjectRequest{Name: "Log"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	calls := 0
+	doc, err := c.EditDocument(ctx, project.ID, func(content string) (string, error) {
+		calls++
+		if calls == 1 {
+			// Someone else saves between our read and our save.
+			if _, err := other.EditDocument(ctx, project.ID, func(content string) (string, error) {
+				return content + "- theirs\n", nil
+			}); err != nil {
+				return "", err
+			}
+		}
+		return content + "- ours\n", nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to edit document: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the edit to be applied twice, got %d", calls)
+	}
+	if doc.ContentMD != "- theirs\n- ours\n" {
+		t.Errorf("Expected both edits, got %q", doc.ContentMD)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	user, token, err := srv.users.Create(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	c := New(srv.URL, WithAuth(BearerToken(token)))
+	workspace, err := c.CreateWorkspace(ctx, "Team")
+	if err != nil {
+		t.Fatalf("Failed to create workspace: %v", err)
+	}
+
+	team := c.InWorkspace(workspace.ID)
+	project, err := team.CreateProject(ctx, CreateProjectRequest{Name: "Roadmap"})
+	if err != nil || project.WorkspaceID != workspace.ID {
+		t.Fatalf("Expected the project in the team workspace, got %+v, %v", project, err)
+	}
+	if _, err := c.GetProject(ctx, project.ID); !HasCode(err, CodeProjectNotFound) {
+		t.Errorf("Expected the project to be missing from the default workspace, got %v", err)
+	}
+
+	members, err := team.ListWorkspaceMembers(ctx, workspace.ID)
+	if err != nil || len(members) != 1 || members[0].UserID != user.ID {
+		t.Errorf("Expected alice as the only member, got %+v, %v", members, err)
+	}
+
+	// Anonymous clients cannot use the workspace.
+	if _, err := New(srv.URL, WithWorkspace(workspace.ID)).ListProjects(ctx, ListProjectsOptions{}); !HasCode(err, "unauthenticated") {
+		t.Errorf("Expected unauthenticated, got %v", err)
+	}
+
+	if err := New(srv.URL, WithAuth(BearerToken("nope"))).Health(ctx); err != nil {
+		t.Errorf("Expected health to be public, got %v", err)
+	}
+	if _, err := New(srv.URL, WithAuth(BearerToken("nope"))).ListWorkspaces(ctx); !HasCode(err, "invalid_token") {
+		t.Errorf("Expected invalid_token, got %v", err)
+	}
+
+	failing := errors.New("no credentials")
+	broken := New(srv.URL, WithAuth(AuthenticatorFunc(func(*http.Request) error { return failing })))
+	if _, err := broken.ListWorkspaces(ctx); !errors.Is(err, failing) {
+		t.Errorf("Expected the authenticator's error, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	c := New(newTestServer(t).URL)
+
+	_, err := c.CreateProject(ctx, CreateProjectRequest{Name: ""})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeValidationFailed || len(apiErr.Errors) != 1 || apiErr.Errors[0].Field != "name" {
+		t.Fatalf("Expected a validation error on name, got %#v", err)
+	}
+
+	first, _ := c.CreateProject(ctx, CreateProjectRequest{Name: "First"})
+	second, _ := c.CreateProject(ctx, CreateProjectRequest{Name: "Second"})
+	if _, err := c.MergeProjects(ctx, MergeProjectsRequest{ProjectIDs: []uuid.UUID{first.ID, second.ID}}); err != nil {
+		t.Fatalf("Failed to merge projects: %v", err)
+	}
+
+	// Reads follow a merged project to its target; changes do not.
+	got, err := c.GetProject(ctx, second.ID)
+	if err != nil || got.ID != first.ID {
+		t.Errorf("Expected the merge target, got %+v, %v", got, err)
+	}
+	if _, err := c.RenameProject(ctx, second.ID, "Renamed"); !HasCode(err, CodeProjectNotFound) {
+		t.Errorf("Expected %s, got %v", CodeProjectNotFound, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.GetProject(ctx, first.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled context to stop the request, got %v", err)
+	}
+}
+
+func TestWorkspaceResources(t *testing.T) {
+	ctx := context.Background()
+	c := New(newTestServer(t).URL)
+
+	project, err := c.CreateProject(ctx, CreateProjectRequest{Name: "Incident"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	template, err := c.CreateTemplate(ctx, CreateTemplateRequest{Name: "Postmortem", ContentMD: "# {{title}}\n"})
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	if templates, err := c.ListTemplates(ctx); err != nil || len(templates) != 1 {
+		t.Errorf("Expected one template, got %+v, %v", templates, err)
+	}
+	fromTemplate, err := c.CreateProject(ctx, CreateProjectRequest{Name: "Outage", TemplateID: &template.ID, Variables: map[string]string{"title": "Outage"}})
+	if err != nil {
+		t.Fatalf("Failed to create project from template: %v", err)
+	}
+	if doc, err := c.GetDocument(ctx, fromTemplate.ID); err != nil || doc.ContentMD != "# Outage\n" {
+		t.Errorf("Expected the filled-in template, got %+v, %v", doc, err)
+	}
+
+	link, err := c.CreateShareLink(ctx, project.ID, CreateShareLinkRequest{})
+	if err != nil {
+		t.Fatalf("Failed to create share link: %v", err)
+	}
+	if err := c.RevokeShareLink(ctx, project.ID, link.ID); err != nil {
+		t.Errorf("Failed to revoke share link: %v", err)
+	}
+
+	webhook, err := c.CreateWebhook(ctx, CreateWebhookRequest{URL: "https://example.com/hook", Events: []string{"document.updated"}})
+	if err != nil || webhook.Secret == "" {
+		t.Fatalf("Expected a webhook with its secret, got %+v, %v", webhook, err)
+	}
+	if _, err := c.EditDocument(ctx, project.ID, func(string) (string, error) { return "# Incident\n", nil }); err != nil {
+		t.Fatalf("Failed to edit document: %v", err)
+	}
+	if deliveries, err := c.ListWebhookDeliveries(ctx, webhook.ID); err != nil || len(deliveries) != 1 {
+		t.Errorf("Expected one pending delivery, got %+v, %v", deliveries, err)
+	}
+
+	events, err := c.ListAuditEvents(ctx, AuditQuery{ProjectID: &project.ID})
+	if err != nil || len(events.Events) == 0 {
+		t.Errorf("Expected audit events for the project, got %+v, %v", events, err)
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+const defaultMaxAttempts = 5
+
+// ConflictFunc resolves a version conflict: someone else saved the document
+// after it was read. It gets the document as it is now and the content that
+// failed to save, and returns the content to save on top of latest instead.
+// Returning an error gives up.
+type ConflictFunc func(ctx context.Context, latest *DocumentResponse, content string) (string, error)
+
+type UpdateDocumentOptions struct {
+	// OnConflict is called when the save hits a version conflict. Without
+	// it, conflicts are returned as errors with CodeVersionConflict.
+	OnConflict ConflictFunc
+	// MaxAttempts bounds the number of saves, including the first; zero
+	// means five.
+	MaxAttempts int
+}
+
+// GetDocument returns the document of a project.
+func (c *Client) GetDocument(ctx context.Context, projectID uuid.UUID) (*DocumentResponse, error) {
+	var doc DocumentResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: projectPath(projectID, "/document")}, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// UpdateDocument saves content as the next version of doc, which is the
+// document as last read or saved. The version check is handled for the
+// caller: on a conflict the latest document is fetched and handed to
+// opts.OnConflict, and its result is saved in turn. opts may be nil.
+func (c *Client) UpdateDocument(ctx context.Context, doc *DocumentResponse, content string, opts *UpdateDocumentOptions) (*DocumentResponse, error) {
+	if opts == nil {
+		opts = &UpdateDocumentOptions{}
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	base := doc
+	for attempt := 1; ; attempt++ {
+		saved, err := c.saveDocument(ctx, base.ID, content, base.Version)
+		if err == nil || !HasCode(err, CodeVersionConflict) || opts.OnConflict == nil || attempt == maxAttempts {
+			return saved, err
+		}
+
+		base, err = c.GetDocument(ctx, base.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		content, err = opts.OnConflict(ctx, base, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// EditDocument reads a project's document, applies edit to its content and
+// saves the result. When someone else saves in between, edit is applied
+// again to their version.
+func (c *Client) EditDocument(ctx context.Context, projectID uuid.UUID, edit func(content string) (string, error)) (*DocumentResponse, error) {
+	doc, err := c.GetDocument(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := edit(doc.ContentMD)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.UpdateDocument(ctx, doc, content, &UpdateDocumentOptions{
+		OnConflict: func(_ context.Context, latest *DocumentResponse, _ string) (string, error) {
+			return edit(latest.ContentMD)
+		},
+	})
+}
+
+func (c *Client) saveDocument(ctx context.Context, id uuid.UUID, content string, version int) (*DocumentResponse, error) {
+	var doc DocumentResponse
+	req := request{
+		method: http.MethodPut,
+		path:   "/api/documents/" + id.String(),
+		header: http.Header{versionHeader: {strconv.Itoa(version)}},
+		body:   models.UpdateDocumentRequest{ContentMD: content},
+	}
+	if _, err := c.do(ctx, req, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// DocumentHistory lists the commits of a project's document in git
+// storage, newest first. A limit of zero uses the server default.
+func (c *Client) DocumentHistory(ctx context.Context, projectID uuid.UUID, limit int) ([]DocumentCommit, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+
+	var response models.DocumentHistoryResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: projectPath(projectID, "/history"), query: q}, &response); err != nil {
+		return nil, err
+	}
+	return response.Commits, nil
+}
+
+// DocumentDiff returns the unified diff of a project's document between two
+// committed versions. Zero versions use the server defaults: the latest
+// version and the one before it.
+func (c *Client) DocumentDiff(ctx context.Context, projectID uuid.UUID, from, to int) (*DocumentDiff, error) {
+	q := url.Values{}
+	if from > 0 {
+		q.Set("from", strconv.Itoa(from))
+	}
+	if to > 0 {
+		q.Set("to", strconv.Itoa(to))
+	}
+
+	var diff DocumentDiff
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: projectPath(projectID, "/diff"), query: q}, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
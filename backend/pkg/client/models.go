@@ -0,0 +1,51 @@
+package client
+
+import "github.com/warriorguo/md-editor/backend/internal/models"
+
+// The API types are those of the server, so that requests and responses
+// cannot drift from it.
+type (
+	Project                 = models.Project
+	ProjectPreview          = models.ProjectPreview
+	ProjectStats            = models.ProjectStats
+	ProjectMatch            = models.ProjectMatch
+	ProjectListResponse     = models.ProjectListResponse
+	ProjectLookupResponse   = models.ProjectLookupResponse
+	ProjectDocumentResponse = models.ProjectDocumentResponse
+	SplitProjectResponse    = models.SplitProjectResponse
+	CreateProjectRequest    = models.CreateProjectRequest
+	MergeProjectsRequest    = models.MergeProjectsRequest
+	SplitProjectRequest     = models.SplitProjectRequest
+	TrashedProject          = models.TrashedProject
+	TrashListResponse       = models.TrashListResponse
+
+	Document         = models.Document
+	DocumentResponse = models.DocumentResponse
+	DocumentCommit   = models.DocumentCommit
+	DocumentDiff     = models.DocumentDiff
+
+	Workspace                 = models.Workspace
+	WorkspaceMember           = models.WorkspaceMember
+	UpdateWorkspaceRequest    = models.UpdateWorkspaceRequest
+	AddWorkspaceMemberRequest = models.AddWorkspaceMemberRequest
+
+	ShareLink              = models.ShareLink
+	CreateShareLinkRequest = models.CreateShareLinkRequest
+
+	Template              = models.Template
+	CreateTemplateRequest = models.CreateTemplateRequest
+	UpdateTemplateRequest = models.UpdateTemplateRequest
+	SaveAsTemplateRequest = models.SaveAsTemplateRequest
+
+	AuditEvent        = models.AuditEvent
+	AuditListResponse = models.AuditListResponse
+
+	Webhook              = models.Webhook
+	WebhookDelivery      = models.WebhookDelivery
+	CreateWebhookRequest = models.CreateWebhookRequest
+	UpdateWebhookRequest = models.UpdateWebhookRequest
+)
+
+// DefaultWorkspaceID is the workspace requests are made in unless the
+// client is scoped to another one.
+var DefaultWorkspaceID = models.DefaultWorkspaceID
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+// ListProjectsOptions filters and pages ListProjects. Zero values leave the
+// server defaults in place.
+type ListProjectsOptions struct {
+	// Sort is createdAt, updatedAt or name; Order is asc or desc.
+	Sort  string
+	Order string
+	// Query matches project names by substring or similarity.
+	Query        string
+	UpdatedSince time.Time
+	// Archived is "true" to list only archived projects or "all" to list
+	// them with the others.
+	Archived   string
+	Pinned     bool
+	Favourites bool
+	// Include asks for "preview" and "stats" of each project.
+	Include []string
+	// Cursor is the NextCursor of the previous page; it replaces Page.
+	Cursor   string
+	Page     int
+	PageSize int
+}
+
+func (o ListProjectsOptions) values() url.Values {
+	q := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	set("sort", o.Sort)
+	set("order", o.Order)
+	set("q", o.Query)
+	if !o.UpdatedSince.IsZero() {
+		q.Set("updatedSince", o.UpdatedSince.Format(time.RFC3339))
+	}
+	set("archived", o.Archived)
+	if o.Pinned {
+		q.Set("pinned", "true")
+	}
+	if o.Favourites {
+		q.Set("favourites", "true")
+	}
+	set("include", strings.Join(o.Include, ","))
+	set("cursor", o.Cursor)
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PageSize > 0 {
+		q.Set("pageSize", strconv.Itoa(o.PageSize))
+	}
+	return q
+}
+
+func projectPath(id uuid.UUID, suffix string) string {
+	return "/api/projects/" + id.String() + suffix
+}
+
+// CreateProject creates a project with an empty document, or one started
+// from req.TemplateID.
+func (c *Client) CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error) {
+	var project Project
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/projects", body: req}, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (c *Client) ListProjects(ctx context.Context, opts ListProjectsOptions) (*ProjectListResponse, error) {
+	var response ProjectListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/projects", query: opts.values()}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// LookupProjects finds projects whose name equals or resembles name. A
+// limit of zero uses the server default.
+func (c *Client) LookupProjects(ctx context.Context, name string, limit int) (*ProjectLookupResponse, error) {
+	q := url.Values{"name": {name}}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+
+	var response ProjectLookupResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/projects/lookup", query: q}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetOrCreateProject returns the project with the given name, creating it
+// if there is none, and reports whether it was created.
+func (c *Client) GetOrCreateProject(ctx context.Context, name string) (*Project, bool, error) {
+	var project Project
+	resp, err := c.do(ctx, request{method: http.MethodPut, path: "/api/projects/by-name/" + url.PathEscape(name)}, &project)
+	if err != nil {
+		return nil, false, err
+	}
+	return &project, resp.StatusCode == http.StatusCreated, nil
+}
+
+// GetProject returns a project. Projects that were merged into another one
+// resolve to that one.
+func (c *Client) GetProject(ctx context.Context, id uuid.UUID) (*Project, error) {
+	var project Project
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: projectPath(id, "")}, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (c *Client) RenameProject(ctx context.Context, id uuid.UUID, name string) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodPatch, projectPath(id, ""), models.UpdateProjectRequest{Name: name})
+}
+
+// DeleteProject moves a project to the trash.
+func (c *Client) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: projectPath(id, "")}, nil)
+	return err
+}
+
+// DuplicateProject copies a project and its document. An empty name lets
+// the server pick one.
+func (c *Client) DuplicateProject(ctx context.Context, id uuid.UUID, name string) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodPost, projectPath(id, "/duplicate"), models.DuplicateProjectRequest{Name: name})
+}
+
+// MergeProjects merges the documents of all projects into the first one.
+func (c *Client) MergeProjects(ctx context.Context, req MergeProjectsRequest) (*ProjectDocumentResponse, error) {
+	var response ProjectDocumentResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/projects/merge", body: req}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// SplitProject moves the named top-level sections of a project's document
+// into new projects.
+func (c *Client) SplitProject(ctx context.Context, id uuid.UUID, headings []string) (*SplitProjectResponse, error) {
+	var response SplitProjectResponse
+	req := request{method: http.MethodPost, path: projectPath(id, "/split"), body: SplitProjectRequest{Headings: headings}}
+	if _, err := c.do(ctx, req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) ArchiveProject(ctx context.Context, id uuid.UUID) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodPut, projectPath(id, "/archive"), nil)
+}
+
+func (c *Client) UnarchiveProject(ctx context.Context, id uuid.UUID) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodDelete, projectPath(id, "/archive"), nil)
+}
+
+func (c *Client) PinProject(ctx context.Context, id uuid.UUID) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodPut, projectPath(id, "/pin"), nil)
+}
+
+func (c *Client) UnpinProject(ctx context.Context, id uuid.UUID) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodDelete, projectPath(id, "/pin"), nil)
+}
+
+// FavouriteProject marks a project as a favourite of the authenticated user.
+func (c *Client) FavouriteProject(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: projectPath(id, "/favourite")}, nil)
+	return err
+}
+
+func (c *Client) UnfavouriteProject(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: projectPath(id, "/favourite")}, nil)
+	return err
+}
+
+// TransferProject moves a project to another workspace.
+func (c *Client) TransferProject(ctx context.Context, id, workspaceID uuid.UUID) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodPost, projectPath(id, "/transfer"), models.TransferProjectRequest{WorkspaceID: workspaceID})
+}
+
+func (c *Client) projectRequest(ctx context.Context, method, path string, body interface{}) (*Project, error) {
+	var project Project
+	if _, err := c.do(ctx, request{method: method, path: path, body: body}, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+// CreateShareLink creates a read-only link to a project's document.
+func (c *Client) CreateShareLink(ctx context.Context, projectID uuid.UUID, req CreateShareLinkRequest) (*ShareLink, error) {
+	var link ShareLink
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: projectPath(projectID, "/share-links"), body: req}, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (c *Client) ListShareLinks(ctx context.Context, projectID uuid.UUID) ([]ShareLink, error) {
+	var response models.ShareLinkListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: projectPath(projectID, "/share-links")}, &response); err != nil {
+		return nil, err
+	}
+	return response.ShareLinks, nil
+}
+
+func (c *Client) RevokeShareLink(ctx context.Context, projectID, linkID uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: projectPath(projectID, "/share-links/"+linkID.String())}, nil)
+	return err
+}
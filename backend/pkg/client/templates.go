@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func templatePath(id uuid.UUID) string {
+	return "/api/templates/" + id.String()
+}
+
+func (c *Client) CreateTemplate(ctx context.Context, req CreateTemplateRequest) (*Template, error) {
+	return c.templateRequest(ctx, http.MethodPost, "/api/templates", req)
+}
+
+// SaveProjectAsTemplate turns a project's document into a template.
+func (c *Client) SaveProjectAsTemplate(ctx context.Context, projectID uuid.UUID, req SaveAsTemplateRequest) (*Template, error) {
+	return c.templateRequest(ctx, http.MethodPost, projectPath(projectID, "/template"), req)
+}
+
+func (c *Client) ListTemplates(ctx context.Context) ([]Template, error) {
+	var response models.TemplateListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/templates"}, &response); err != nil {
+		return nil, err
+	}
+	return response.Templates, nil
+}
+
+func (c *Client) GetTemplate(ctx context.Context, id uuid.UUID) (*Template, error) {
+	return c.templateRequest(ctx, http.MethodGet, templatePath(id), nil)
+}
+
+func (c *Client) UpdateTemplate(ctx context.Context, id uuid.UUID, req UpdateTemplateRequest) (*Template, error) {
+	return c.templateRequest(ctx, http.MethodPatch, templatePath(id), req)
+}
+
+func (c *Client) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: templatePath(id)}, nil)
+	return err
+}
+
+func (c *Client) templateRequest(ctx context.Context, method, path string, body interface{}) (*Template, error) {
+	var template Template
+	if _, err := c.do(ctx, request{method: method, path: path, body: body}, &template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ListTrash lists deleted projects, most recently deleted first. Zero page
+// and pageSize use the server defaults.
+func (c *Client) ListTrash(ctx context.Context, page, pageSize int) (*TrashListResponse, error) {
+	q := url.Values{}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		q.Set("pageSize", strconv.Itoa(pageSize))
+	}
+
+	var response TrashListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/trash", query: q}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// RestoreProject brings a project back from the trash.
+func (c *Client) RestoreProject(ctx context.Context, id uuid.UUID) (*Project, error) {
+	return c.projectRequest(ctx, http.MethodPost, projectPath(id, "/restore"), nil)
+}
+
+// PurgeProject permanently deletes a project in the trash.
+func (c *Client) PurgeProject(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/api/trash/" + id.String()}, nil)
+	return err
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func webhookPath(id uuid.UUID, suffix string) string {
+	return "/api/webhooks/" + id.String() + suffix
+}
+
+// CreateWebhook subscribes a URL to events. The returned webhook carries
+// its secret, which is not returned again.
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*Webhook, error) {
+	return c.webhookRequest(ctx, http.MethodPost, "/api/webhooks", req)
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var response models.WebhookListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/webhooks"}, &response); err != nil {
+		return nil, err
+	}
+	return response.Webhooks, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id uuid.UUID) (*Webhook, error) {
+	return c.webhookRequest(ctx, http.MethodGet, webhookPath(id, ""), nil)
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id uuid.UUID, req UpdateWebhookRequest) (*Webhook, error) {
+	return c.webhookRequest(ctx, http.MethodPatch, webhookPath(id, ""), req)
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: webhookPath(id, "")}, nil)
+	return err
+}
+
+// ListWebhookDeliveries lists the recent deliveries of a webhook.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id uuid.UUID) ([]WebhookDelivery, error) {
+	var response models.WebhookDeliveryListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: webhookPath(id, "/deliveries")}, &response); err != nil {
+		return nil, err
+	}
+	return response.Deliveries, nil
+}
+
+func (c *Client) webhookRequest(ctx context.Context, method, path string, body interface{}) (*Webhook, error) {
+	var webhook Webhook
+	if _, err := c.do(ctx, request{method: method, path: path, body: body}, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/warriorguo/md-editor/backend/internal/models"
+)
+
+func workspacePath(id uuid.UUID, suffix string) string {
+	return "/api/workspaces/" + id.String() + suffix
+}
+
+// ListWorkspaces lists the workspaces of the authenticated user.
+func (c *Client) ListWorkspaces(ctx context.Context) ([]Workspace, error) {
+	var response models.WorkspaceListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/workspaces"}, &response); err != nil {
+		return nil, err
+	}
+	return response.Workspaces, nil
+}
+
+// CreateWorkspace creates a workspace owned by the authenticated user.
+func (c *Client) CreateWorkspace(ctx context.Context, name string) (*Workspace, error) {
+	var workspace Workspace
+	req := request{method: http.MethodPost, path: "/api/workspaces", body: models.CreateWorkspaceRequest{Name: name}}
+	if _, err := c.do(ctx, req, &workspace); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (c *Client) UpdateWorkspace(ctx context.Context, id uuid.UUID, req UpdateWorkspaceRequest) (*Workspace, error) {
+	var workspace Workspace
+	if _, err := c.do(ctx, request{method: http.MethodPatch, path: workspacePath(id, ""), body: req}, &workspace); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (c *Client) ListWorkspaceMembers(ctx context.Context, id uuid.UUID) ([]WorkspaceMember, error) {
+	var response models.WorkspaceMemberListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: workspacePath(id, "/members")}, &response); err != nil {
+		return nil, err
+	}
+	return response.Members, nil
+}
+
+// AddWorkspaceMember adds a user to a workspace, or changes their role.
+func (c *Client) AddWorkspaceMember(ctx context.Context, id uuid.UUID, req AddWorkspaceMemberRequest) (*WorkspaceMember, error) {
+	var member WorkspaceMember
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: workspacePath(id, "/members"), body: req}, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (c *Client) RemoveWorkspaceMember(ctx context.Context, id, userID uuid.UUID) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: workspacePath(id, "/members/"+userID.String())}, nil)
+	return err
+}
@@ -12,7 +12,7 @@ describe('isApiError', () => {
       {
         status: 400,
         statusText: 'Bad Request',
-        data: { error: 'Validation failed' },
+        data: { title: 'Bad Request', status: 400, code: 'validation_failed' },
         headers: {},
         config: { headers: new AxiosHeaders() },
       }
@@ -40,7 +40,7 @@ describe('isApiError', () => {
 });
 
 describe('getErrorMessage', () => {
-  it('should return message from API error response', () => {
+  it('should return the first field error of a validation problem', () => {
     const error = new AxiosError(
       'Request failed',
       'ERR_BAD_REQUEST',
@@ -49,31 +49,37 @@ describe('getErrorMessage', () => {
       {
         status: 400,
         statusText: 'Bad Request',
-        data: { error: 'Validation failed', message: 'Name is required' },
+        data: {
+          title: 'Bad Request',
+          status: 400,
+          code: 'validation_failed',
+          detail: 'request validation failed',
+          errors: [{ field: 'name', code: 'required', message: 'is required' }],
+        },
         headers: {},
         config: { headers: new AxiosHeaders() },
       }
     );
 
-    expect(getErrorMessage(error)).toBe('Name is required');
+    expect(getErrorMessage(error)).toBe('name is required');
   });
 
-  it('should return error field if no message', () => {
+  it('should return the problem detail', () => {
     const error = new AxiosError(
       'Request failed',
       'ERR_BAD_REQUEST',
       undefined,
       undefined,
       {
-        status: 400,
-        statusText: 'Bad Request',
-        data: { error: 'Validation failed' },
+        status: 404,
+        statusText: 'Not Found',
+        data: { title: 'Not Found', status: 404, code: 'project_not_found', detail: 'project not found' },
         headers: {},
         config: { headers: new AxiosHeaders() },
       }
     );
 
-    expect(getErrorMessage(error)).toBe('Validation failed');
+    expect(getErrorMessage(error)).toBe('project not found');
   });
 
   it('should return default message for empty response', () => {
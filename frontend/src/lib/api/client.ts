@@ -7,9 +7,19 @@ export const apiClient = axios.create({
   },
 });
 
+// Errors are RFC 9457 problem details; clients branch on `code`.
+export interface ApiFieldError {
+  field: string;
+  code: string;
+  message: string;
+}
+
 export interface ApiError {
-  error: string;
-  message?: string;
+  title: string;
+  status: number;
+  code: string;
+  detail?: string;
+  errors?: ApiFieldError[];
 }
 
 export function isApiError(error: unknown): error is AxiosError<ApiError> {
@@ -18,7 +28,12 @@ export function isApiError(error: unknown): error is AxiosError<ApiError> {
 
 export function getErrorMessage(error: unknown): string {
   if (isApiError(error)) {
-    return error.response?.data?.message || error.response?.data?.error || 'An error occurred';
+    const data = error.response?.data;
+    const field = data?.errors?.[0];
+    if (field) {
+      return `${field.field} ${field.message}`;
+    }
+    return data?.detail || data?.title || 'An error occurred';
   }
   if (error instanceof Error) {
     return error.message;